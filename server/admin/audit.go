@@ -0,0 +1,70 @@
+package admin
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Versifine/Cumt-cumpus-hub/server/internal/transport"
+	"github.com/Versifine/Cumt-cumpus-hub/server/store"
+)
+
+// AuditLog handles GET /api/v1/admin/audit-log: every admin and moderator action, newest first.
+func (h *Handler) AuditLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+		return
+	}
+	if _, ok := h.Auth.RequireAdmin(w, r); !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	since, ok := parseAuditTime(query.Get("since"))
+	if !ok {
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid since")
+		return
+	}
+	until, ok := parseAuditTime(query.Get("until"))
+	if !ok {
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid until")
+		return
+	}
+
+	filter := store.AuditFilter{
+		ActorID:    strings.TrimSpace(query.Get("actor_id")),
+		Action:     strings.TrimSpace(query.Get("action")),
+		TargetType: strings.TrimSpace(query.Get("target_type")),
+		TargetID:   strings.TrimSpace(query.Get("target_id")),
+		BoardID:    strings.TrimSpace(query.Get("board_id")),
+		Since:      since,
+		Until:      until,
+	}
+	page := parsePositiveInt(query.Get("page"), 1)
+	pageSize := parsePositiveInt(query.Get("page_size"), 20)
+
+	items, total, err := h.Store.AuditEntries(filter, page, pageSize)
+	if err != nil {
+		transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+		return
+	}
+	transport.WriteJSON(w, http.StatusOK, map[string]any{
+		"items": items,
+		"total": total,
+	})
+}
+
+// parseAuditTime accepts an RFC3339 timestamp or a YYYY-MM-DD date (UTC midnight) and
+// returns it in the UTC RFC3339 form the audit log stores.
+func parseAuditTime(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", true
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC().Format(time.RFC3339), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,257 @@
+package admin
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Versifine/Cumt-cumpus-hub/server/internal/transport"
+	"github.com/Versifine/Cumt-cumpus-hub/server/store"
+)
+
+// Boards handles POST /api/v1/admin/boards (create a board directly).
+func (h *Handler) Boards(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+		return
+	}
+	admin, ok := h.Auth.RequireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	var req boardRequest
+	if err := transport.ReadJSON(r, &req); err != nil {
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid json")
+		return
+	}
+
+	board, err := h.Store.CreateBoard(req.apply(store.Board{}))
+	if err != nil {
+		writeBoardError(w, err)
+		return
+	}
+	h.audit(r, store.AuditEntry{
+		ActorID:    admin.ID,
+		Action:     "create_board",
+		TargetType: "board",
+		TargetID:   board.ID,
+		BoardID:    board.ID,
+		After:      store.AuditState(board),
+	})
+	transport.WriteJSON(w, http.StatusOK, board)
+}
+
+// Board handles PATCH/DELETE /api/v1/admin/boards/{board_id}.
+func (h *Handler) Board(boardID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPatch:
+			h.updateBoard(w, r, boardID)
+		case http.MethodDelete:
+			h.deleteBoard(w, r, boardID)
+		default:
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+		}
+	}
+}
+
+func (h *Handler) updateBoard(w http.ResponseWriter, r *http.Request, boardID string) {
+	admin, ok := h.Auth.RequireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	board, ok := h.Store.GetBoard(boardID)
+	if !ok {
+		transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+		return
+	}
+
+	var req boardRequest
+	if err := transport.ReadJSON(r, &req); err != nil {
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid json")
+		return
+	}
+
+	updated, err := h.Store.UpdateBoard(req.apply(board))
+	if err != nil {
+		writeBoardError(w, err)
+		return
+	}
+	if updated != board {
+		h.audit(r, store.AuditEntry{
+			ActorID:    admin.ID,
+			Action:     "update_board",
+			TargetType: "board",
+			TargetID:   board.ID,
+			BoardID:    board.ID,
+			Before:     store.AuditState(board),
+			After:      store.AuditState(updated),
+		})
+	}
+	transport.WriteJSON(w, http.StatusOK, updated)
+}
+
+func (h *Handler) deleteBoard(w http.ResponseWriter, r *http.Request, boardID string) {
+	admin, ok := h.Auth.RequireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	board, _ := h.Store.GetBoard(boardID)
+	if err := h.Store.DeleteBoard(boardID); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+		case store.ErrConflict:
+			transport.WriteError(w, http.StatusConflict, 2001, "board has posts, archive it instead")
+		default:
+			transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+		}
+		return
+	}
+	h.audit(r, store.AuditEntry{
+		ActorID:    admin.ID,
+		Action:     "delete_board",
+		TargetType: "board",
+		TargetID:   board.ID,
+		BoardID:    board.ID,
+		Before:     store.AuditState(board),
+	})
+	transport.WriteJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// BoardApplications handles GET /api/v1/admin/board-applications (review queue).
+func (h *Handler) BoardApplications(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+		return
+	}
+	if _, ok := h.Auth.RequireAdmin(w, r); !ok {
+		return
+	}
+
+	status := strings.TrimSpace(r.URL.Query().Get("status"))
+	page := parsePositiveInt(r.URL.Query().Get("page"), 1)
+	pageSize := parsePositiveInt(r.URL.Query().Get("page_size"), 20)
+
+	items, total, err := h.Store.BoardApplications(status, "", page, pageSize)
+	if err != nil {
+		transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+		return
+	}
+
+	resp := map[string]any{
+		"items": items,
+		"total": total,
+	}
+	transport.WriteJSON(w, http.StatusOK, resp)
+}
+
+// BoardApplication handles PATCH /api/v1/admin/board-applications/{application_id}.
+func (h *Handler) BoardApplication(appID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+			return
+		}
+
+		user, ok := h.Auth.RequireAdmin(w, r)
+		if !ok {
+			return
+		}
+
+		var req struct {
+			Status string `json:"status"`
+			Note   string `json:"note"`
+		}
+		if err := transport.ReadJSON(r, &req); err != nil {
+			transport.WriteError(w, http.StatusBadRequest, 2001, "invalid json")
+			return
+		}
+
+		var approve bool
+		switch strings.TrimSpace(req.Status) {
+		case "approved":
+			approve = true
+		case "rejected":
+			approve = false
+		default:
+			transport.WriteError(w, http.StatusBadRequest, 2001, "invalid status")
+			return
+		}
+
+		app, err := h.Store.ReviewBoardApplication(appID, approve, req.Note, user.ID)
+		if err != nil {
+			switch err {
+			case store.ErrNotFound:
+				transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			case store.ErrConflict:
+				transport.WriteError(w, http.StatusConflict, 2001, "application already reviewed or board name taken")
+			default:
+				transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+			}
+			return
+		}
+		h.audit(r, store.AuditEntry{
+			ActorID:    user.ID,
+			Action:     "review_board_application",
+			TargetType: "board_application",
+			TargetID:   app.ID,
+			BoardID:    app.BoardID,
+			Reason:     req.Note,
+			Before:     store.AuditState(map[string]string{"status": "pending"}),
+			After:      store.AuditState(map[string]string{"status": app.Status, "board_id": app.BoardID}),
+		})
+		transport.WriteJSON(w, http.StatusOK, app)
+	}
+}
+
+// boardRequest carries optional board fields; nil fields keep their current value.
+type boardRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Icon        *string `json:"icon"`
+	Rules       *string `json:"rules"`
+	QQGroup     *string `json:"qq_group"`
+	Archived    *bool   `json:"archived"`
+	ReadOnly    *bool   `json:"read_only"`
+}
+
+func (req boardRequest) apply(board store.Board) store.Board {
+	if req.Name != nil {
+		board.Name = *req.Name
+	}
+	if req.Description != nil {
+		board.Description = *req.Description
+	}
+	if req.Icon != nil {
+		board.Icon = *req.Icon
+	}
+	if req.Rules != nil {
+		board.Rules = *req.Rules
+	}
+	if req.QQGroup != nil {
+		board.QQGroup = *req.QQGroup
+	}
+	if req.Archived != nil {
+		board.Archived = *req.Archived
+	}
+	if req.ReadOnly != nil {
+		board.ReadOnly = *req.ReadOnly
+	}
+	return board
+}
+
+func writeBoardError(w http.ResponseWriter, err error) {
+	switch err {
+	case store.ErrInvalidInput:
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid fields")
+	case store.ErrNotFound:
+		transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+	case store.ErrConflict:
+		transport.WriteError(w, http.StatusConflict, 2001, "board name already taken")
+	default:
+		transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+	}
+}
@@ -0,0 +1,212 @@
+package admin
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Versifine/Cumt-cumpus-hub/server/internal/contentfilter"
+	"github.com/Versifine/Cumt-cumpus-hub/server/internal/transport"
+	"github.com/Versifine/Cumt-cumpus-hub/server/store"
+)
+
+// FilterWords handles GET/POST /api/v1/admin/filter/words.
+func (h *Handler) FilterWords(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		if _, ok := h.Auth.RequireAdmin(w, r); !ok {
+			return
+		}
+		items := h.Store.FilterWords()
+		if items == nil {
+			items = []store.FilterWord{}
+		}
+		transport.WriteJSON(w, http.StatusOK, map[string]any{"items": items})
+	case http.MethodPost:
+		h.addFilterWord(w, r)
+	default:
+		transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+	}
+}
+
+func (h *Handler) addFilterWord(w http.ResponseWriter, r *http.Request) {
+	admin, ok := h.Auth.RequireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Word     string `json:"word"`
+		Category string `json:"category"`
+	}
+	if err := transport.ReadJSON(r, &req); err != nil {
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid json")
+		return
+	}
+
+	word, err := h.Store.AddFilterWord(req.Word, req.Category, admin.ID)
+	if err != nil {
+		switch err {
+		case store.ErrInvalidInput:
+			transport.WriteError(w, http.StatusBadRequest, 2001, "invalid fields")
+		case store.ErrConflict:
+			transport.WriteError(w, http.StatusConflict, 2001, "word already listed")
+		default:
+			transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+		}
+		return
+	}
+	h.reloadFilter()
+	h.audit(r, store.AuditEntry{
+		ActorID:    admin.ID,
+		Action:     "add_filter_word",
+		TargetType: "filter_word",
+		TargetID:   word.ID,
+		After:      store.AuditState(word),
+	})
+	transport.WriteJSON(w, http.StatusOK, word)
+}
+
+// FilterWord handles DELETE /api/v1/admin/filter/words/{word_id}.
+func (h *Handler) FilterWord(wordID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+			return
+		}
+		admin, ok := h.Auth.RequireAdmin(w, r)
+		if !ok {
+			return
+		}
+
+		var before store.FilterWord
+		for _, word := range h.Store.FilterWords() {
+			if word.ID == wordID {
+				before = word
+			}
+		}
+		if err := h.Store.RemoveFilterWord(wordID); err != nil {
+			switch err {
+			case store.ErrNotFound:
+				transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			default:
+				transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+			}
+			return
+		}
+		h.reloadFilter()
+		h.audit(r, store.AuditEntry{
+			ActorID:    admin.ID,
+			Action:     "remove_filter_word",
+			TargetType: "filter_word",
+			TargetID:   wordID,
+			Before:     store.AuditState(before),
+		})
+		transport.WriteJSON(w, http.StatusOK, map[string]any{"status": "removed"})
+	}
+}
+
+// FilterCategories handles GET /api/v1/admin/filter/categories: every category that has words
+// or a configured action, with the action in effect.
+func (h *Handler) FilterCategories(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+		return
+	}
+	if _, ok := h.Auth.RequireAdmin(w, r); !ok {
+		return
+	}
+
+	type categoryItem struct {
+		Name      string `json:"name"`
+		Action    string `json:"action"`
+		WordCount int    `json:"word_count"`
+	}
+	byName := map[string]*categoryItem{}
+	for _, category := range h.Store.FilterCategories() {
+		byName[category.Name] = &categoryItem{Name: category.Name, Action: category.Action}
+	}
+	for _, word := range h.Store.FilterWords() {
+		item, ok := byName[word.Category]
+		if !ok {
+			item = &categoryItem{Name: word.Category, Action: contentfilter.DefaultAction}
+			byName[word.Category] = item
+		}
+		item.WordCount++
+	}
+
+	items := make([]categoryItem, 0, len(byName))
+	for _, item := range byName {
+		items = append(items, *item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	transport.WriteJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+// FilterCategory handles PUT /api/v1/admin/filter/categories/{name}.
+func (h *Handler) FilterCategory(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+			return
+		}
+		admin, ok := h.Auth.RequireAdmin(w, r)
+		if !ok {
+			return
+		}
+
+		var req struct {
+			Action string `json:"action"`
+		}
+		if err := transport.ReadJSON(r, &req); err != nil {
+			transport.WriteError(w, http.StatusBadRequest, 2001, "invalid json")
+			return
+		}
+		action := strings.TrimSpace(req.Action)
+		if !contentfilter.ValidAction(action) {
+			transport.WriteError(w, http.StatusBadRequest, 2001, "invalid action")
+			return
+		}
+
+		previous := contentfilter.DefaultAction
+		for _, category := range h.Store.FilterCategories() {
+			if category.Name == name {
+				previous = category.Action
+			}
+		}
+		if err := h.Store.SetFilterCategory(name, action); err != nil {
+			transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+			return
+		}
+		h.reloadFilter()
+		h.audit(r, store.AuditEntry{
+			ActorID:    admin.ID,
+			Action:     "set_filter_category",
+			TargetType: "filter_category",
+			TargetID:   name,
+			Before:     store.AuditState(map[string]string{"action": previous}),
+			After:      store.AuditState(map[string]string{"action": action}),
+		})
+		transport.WriteJSON(w, http.StatusOK, store.FilterCategory{Name: name, Action: action})
+	}
+}
+
+// ReloadFilter handles POST /api/v1/admin/filter/reload, for word lists edited outside the API
+// (e.g. directly in the database).
+func (h *Handler) ReloadFilter(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+		return
+	}
+	if _, ok := h.Auth.RequireAdmin(w, r); !ok {
+		return
+	}
+	h.reloadFilter()
+	transport.WriteJSON(w, http.StatusOK, map[string]any{"status": "reloaded"})
+}
+
+func (h *Handler) reloadFilter() {
+	if h.Filter != nil {
+		h.Filter.Reload(h.Store)
+	}
+}
@@ -0,0 +1,100 @@
+package admin
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Versifine/Cumt-cumpus-hub/server/auth"
+	"github.com/Versifine/Cumt-cumpus-hub/server/internal/contentfilter"
+	"github.com/Versifine/Cumt-cumpus-hub/server/internal/transport"
+	"github.com/Versifine/Cumt-cumpus-hub/server/store"
+)
+
+// Handler serves the /api/v1/admin/* management endpoints (everything except reports).
+type Handler struct {
+	Store  store.API
+	Auth   *auth.Service
+	Filter *contentfilter.Filter
+	// PostChanged, if set, is called after a post is purged so its chat room closes.
+	PostChanged func(postID string)
+
+	stats statsCache
+}
+
+// Post handles DELETE /api/v1/admin/posts/{post_id} (permanent purge).
+func (h *Handler) Post(postID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+			return
+		}
+		admin, ok := h.Auth.RequireAdmin(w, r)
+		if !ok {
+			return
+		}
+
+		postID = strings.TrimSpace(postID)
+		post, _ := h.Store.LookupPost(postID)
+		removed, err := h.Store.PurgePost(postID)
+		if err != nil {
+			switch err {
+			case store.ErrNotFound:
+				transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			default:
+				transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+			}
+			return
+		}
+
+		// Metadata is already gone; a leftover file on disk is only wasted space.
+		for _, file := range removed {
+			if err := os.Remove(file.StoragePath); err != nil && !os.IsNotExist(err) {
+				log.Printf("purge post %s: remove %s: %v", postID, file.StoragePath, err)
+			}
+		}
+
+		h.audit(r, store.AuditEntry{
+			ActorID:    admin.ID,
+			Action:     "purge_post",
+			TargetType: "post",
+			TargetID:   post.ID,
+			BoardID:    post.BoardID,
+			Before: store.AuditState(map[string]any{
+				"title":     post.Title,
+				"author_id": post.AuthorID,
+				"removed":   post.DeletedAt != "",
+			}),
+			After: store.AuditState(map[string]any{"removed_files": len(removed)}),
+		})
+		if h.PostChanged != nil {
+			h.PostChanged(postID)
+		}
+		transport.WriteJSON(w, http.StatusOK, map[string]any{
+			"status":        "purged",
+			"removed_files": len(removed),
+		})
+	}
+}
+
+// audit records an admin action; the action itself already succeeded, so failures are only logged.
+func (h *Handler) audit(r *http.Request, entry store.AuditEntry) {
+	entry.IP = transport.ClientIP(r)
+	if _, err := h.Store.AddAuditEntry(entry); err != nil {
+		log.Printf("audit %s %s: %v", entry.Action, entry.TargetID, err)
+	}
+}
+
+func parsePositiveInt(value string, fallback int) int {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		return fallback
+	}
+	return parsed
+}
@@ -0,0 +1,124 @@
+package admin
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Versifine/Cumt-cumpus-hub/server/internal/transport"
+	"github.com/Versifine/Cumt-cumpus-hub/server/store"
+)
+
+type moderatorItem struct {
+	UserID    string `json:"user_id"`
+	Nickname  string `json:"nickname"`
+	GrantedBy string `json:"granted_by"`
+	CreatedAt string `json:"created_at"`
+}
+
+// Moderators handles GET/POST /api/v1/admin/boards/{board_id}/moderators.
+func (h *Handler) Moderators(boardID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.listModerators(w, r, boardID)
+		case http.MethodPost:
+			h.addModerator(w, r, boardID)
+		default:
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+		}
+	}
+}
+
+// Moderator handles DELETE /api/v1/admin/boards/{board_id}/moderators/{user_id}.
+func (h *Handler) Moderator(boardID, userID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+			return
+		}
+		admin, ok := h.Auth.RequireAdmin(w, r)
+		if !ok {
+			return
+		}
+
+		if err := h.Store.RemoveBoardModerator(boardID, userID); err != nil {
+			writeBoardError(w, err)
+			return
+		}
+		h.audit(r, store.AuditEntry{
+			ActorID:    admin.ID,
+			Action:     "remove_moderator",
+			TargetType: "user",
+			TargetID:   userID,
+			BoardID:    boardID,
+			Before:     store.AuditState(map[string]bool{"moderator": true}),
+			After:      store.AuditState(map[string]bool{"moderator": false}),
+		})
+		transport.WriteJSON(w, http.StatusOK, map[string]any{"status": "removed"})
+	}
+}
+
+func (h *Handler) listModerators(w http.ResponseWriter, r *http.Request, boardID string) {
+	if _, ok := h.Auth.RequireAdmin(w, r); !ok {
+		return
+	}
+	if _, ok := h.Store.GetBoard(boardID); !ok {
+		transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+		return
+	}
+
+	items := make([]moderatorItem, 0)
+	for _, moderator := range h.Store.BoardModerators(boardID) {
+		user, _ := h.Store.GetUser(moderator.UserID)
+		items = append(items, moderatorItem{
+			UserID:    moderator.UserID,
+			Nickname:  user.Nickname,
+			GrantedBy: moderator.GrantedBy,
+			CreatedAt: moderator.CreatedAt,
+		})
+	}
+	transport.WriteJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+func (h *Handler) addModerator(w http.ResponseWriter, r *http.Request, boardID string) {
+	admin, ok := h.Auth.RequireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		UserID string `json:"user_id"`
+	}
+	if err := transport.ReadJSON(r, &req); err != nil {
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid json")
+		return
+	}
+	userID := strings.TrimSpace(req.UserID)
+	if userID == "" {
+		transport.WriteError(w, http.StatusBadRequest, 2001, "missing user_id")
+		return
+	}
+
+	if err := h.Store.AddBoardModerator(boardID, userID, admin.ID); err != nil {
+		switch err {
+		case store.ErrInvalidInput:
+			transport.WriteError(w, http.StatusBadRequest, 2001, "user not found")
+		default:
+			writeBoardError(w, err)
+		}
+		return
+	}
+	h.audit(r, store.AuditEntry{
+		ActorID:    admin.ID,
+		Action:     "add_moderator",
+		TargetType: "user",
+		TargetID:   userID,
+		BoardID:    boardID,
+		Before:     store.AuditState(map[string]bool{"moderator": false}),
+		After:      store.AuditState(map[string]bool{"moderator": true}),
+	})
+	transport.WriteJSON(w, http.StatusOK, map[string]any{
+		"board_id": boardID,
+		"user_id":  userID,
+	})
+}
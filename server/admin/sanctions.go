@@ -0,0 +1,155 @@
+package admin
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Versifine/Cumt-cumpus-hub/server/internal/transport"
+	"github.com/Versifine/Cumt-cumpus-hub/server/store"
+)
+
+// UserSanctions handles GET/POST /api/v1/admin/users/{user_id}/sanctions.
+func (h *Handler) UserSanctions(userID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.listSanctions(w, r, userID)
+		case http.MethodPost:
+			h.addSanction(w, r, userID)
+		default:
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+		}
+	}
+}
+
+func (h *Handler) listSanctions(w http.ResponseWriter, r *http.Request, userID string) {
+	if _, ok := h.Auth.RequireAdmin(w, r); !ok {
+		return
+	}
+	if _, ok := h.Store.GetUser(userID); !ok {
+		transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+		return
+	}
+
+	items := h.Store.Sanctions(userID)
+	if items == nil {
+		items = []store.Sanction{}
+	}
+	var active *store.Sanction
+	if sanction, ok := h.Store.ActiveSanction(userID); ok {
+		active = &sanction
+	}
+	transport.WriteJSON(w, http.StatusOK, map[string]any{
+		"items":  items,
+		"active": active,
+	})
+}
+
+func (h *Handler) addSanction(w http.ResponseWriter, r *http.Request, userID string) {
+	admin, ok := h.Auth.RequireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+		Days   int    `json:"days"`
+	}
+	if err := transport.ReadJSON(r, &req); err != nil {
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid json")
+		return
+	}
+
+	target, ok := h.Store.GetUser(userID)
+	if !ok {
+		transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+		return
+	}
+	if h.Auth.IsAdmin(target) {
+		transport.WriteError(w, http.StatusBadRequest, 2001, "cannot sanction an admin")
+		return
+	}
+
+	sanction := store.Sanction{
+		UserID:    userID,
+		Type:      strings.TrimSpace(req.Type),
+		Reason:    req.Reason,
+		CreatedBy: admin.ID,
+	}
+	switch sanction.Type {
+	case store.SanctionWarning, store.SanctionBan:
+	case store.SanctionSuspension:
+		if req.Days == 0 {
+			req.Days = store.DefaultSuspendDays
+		}
+		if req.Days < 0 || req.Days > store.MaxSuspendDays {
+			transport.WriteError(w, http.StatusBadRequest, 2001, "invalid days")
+			return
+		}
+		sanction.ExpiresAt = time.Now().UTC().AddDate(0, 0, req.Days).Format(time.RFC3339)
+	default:
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid type")
+		return
+	}
+
+	created, err := h.Store.AddSanction(sanction)
+	if err != nil {
+		writeSanctionError(w, err)
+		return
+	}
+	h.audit(r, store.AuditEntry{
+		ActorID:    admin.ID,
+		Action:     "add_sanction",
+		TargetType: "user",
+		TargetID:   created.UserID,
+		Reason:     created.Reason,
+		After:      store.AuditState(created),
+	})
+	transport.WriteJSON(w, http.StatusOK, created)
+}
+
+// Sanction handles DELETE /api/v1/admin/sanctions/{sanction_id} (revoke).
+func (h *Handler) Sanction(sanctionID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+			return
+		}
+		admin, ok := h.Auth.RequireAdmin(w, r)
+		if !ok {
+			return
+		}
+
+		revoked, err := h.Store.RevokeSanction(sanctionID)
+		if err != nil {
+			writeSanctionError(w, err)
+			return
+		}
+		before := revoked
+		before.RevokedAt = ""
+		h.audit(r, store.AuditEntry{
+			ActorID:    admin.ID,
+			Action:     "revoke_sanction",
+			TargetType: "user",
+			TargetID:   revoked.UserID,
+			Before:     store.AuditState(before),
+			After:      store.AuditState(revoked),
+		})
+		transport.WriteJSON(w, http.StatusOK, revoked)
+	}
+}
+
+func writeSanctionError(w http.ResponseWriter, err error) {
+	switch err {
+	case store.ErrInvalidInput:
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid fields")
+	case store.ErrNotFound:
+		transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+	case store.ErrConflict:
+		transport.WriteError(w, http.StatusConflict, 2001, "sanction already revoked")
+	default:
+		transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+	}
+}
@@ -0,0 +1,129 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Versifine/Cumt-cumpus-hub/server/internal/transport"
+	"github.com/Versifine/Cumt-cumpus-hub/server/store"
+)
+
+const (
+	defaultStatsDays = 7
+	maxStatsDays     = 90
+	defaultTopBoards = 5
+	maxTopBoards     = 20
+
+	// statsTTL is how long a computed result is served before the store is queried again.
+	statsTTL = time.Minute
+)
+
+// Stats handles GET /api/v1/admin/stats?from=YYYY-MM-DD&to=YYYY-MM-DD&top=N.
+// The range defaults to the last seven days (UTC) ending today.
+func (h *Handler) Stats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+		return
+	}
+	if _, ok := h.Auth.RequireAdmin(w, r); !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	to, ok := parseStatsDay(query.Get("to"), today)
+	if !ok {
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid to")
+		return
+	}
+	from, ok := parseStatsDay(query.Get("from"), to.AddDate(0, 0, -(defaultStatsDays-1)))
+	if !ok {
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid from")
+		return
+	}
+	if from.After(to) || to.Sub(from) >= maxStatsDays*24*time.Hour {
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid range")
+		return
+	}
+	top := parsePositiveInt(query.Get("top"), defaultTopBoards)
+	if top > maxTopBoards {
+		top = maxTopBoards
+	}
+
+	fromDay, toDay := from.Format(time.DateOnly), to.Format(time.DateOnly)
+	key := fromDay + "|" + toDay + "|" + strconv.Itoa(top)
+	stats, generatedAt, ok := h.stats.get(key)
+	if !ok {
+		var err error
+		stats, err = h.Store.SiteStats(fromDay, toDay, top)
+		if err != nil {
+			transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+			return
+		}
+		generatedAt = h.stats.put(key, stats)
+	}
+
+	transport.WriteJSON(w, http.StatusOK, map[string]any{
+		"from":         fromDay,
+		"to":           toDay,
+		"generated_at": generatedAt.Format(time.RFC3339),
+		"stats":        stats,
+	})
+}
+
+// parseStatsDay parses a YYYY-MM-DD date, returning fallback for an empty value.
+func parseStatsDay(value string, fallback time.Time) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fallback, true
+	}
+	day, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return day, true
+}
+
+// statsCache keeps recent SiteStats results keyed by query so dashboards polling the endpoint
+// do not re-run the aggregate queries. The zero value is ready to use.
+type statsCache struct {
+	mu      sync.Mutex
+	entries map[string]statsEntry
+}
+
+type statsEntry struct {
+	stats       store.SiteStats
+	generatedAt time.Time
+}
+
+func (c *statsCache) get(key string) (store.SiteStats, time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Since(entry.generatedAt) >= statsTTL {
+		return store.SiteStats{}, time.Time{}, false
+	}
+	return entry.stats, entry.generatedAt, true
+}
+
+func (c *statsCache) put(key string, stats store.SiteStats) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now().UTC()
+	if c.entries == nil {
+		c.entries = make(map[string]statsEntry)
+	}
+	// Expired entries are dropped here so the map only holds the handful of live ranges.
+	for k, entry := range c.entries {
+		if now.Sub(entry.generatedAt) >= statsTTL {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = statsEntry{stats: stats, generatedAt: now}
+	return now
+}
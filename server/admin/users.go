@@ -0,0 +1,401 @@
+package admin
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/Versifine/Cumt-cumpus-hub/server/internal/transport"
+	"github.com/Versifine/Cumt-cumpus-hub/server/store"
+)
+
+// recentIPLimit caps how many addresses the user detail view returns.
+const recentIPLimit = 20
+
+type userItem struct {
+	store.UserRecord
+	IsAdmin bool `json:"is_admin"`
+}
+
+type userPostItem struct {
+	ID        string `json:"id"`
+	BoardID   string `json:"board_id"`
+	Title     string `json:"title"`
+	Content   string `json:"content"`
+	CreatedAt string `json:"created_at"`
+	DeletedAt string `json:"deleted_at"`
+	HiddenAt  string `json:"hidden_at"`
+}
+
+type userCommentItem struct {
+	ID        string `json:"id"`
+	PostID    string `json:"post_id"`
+	ParentID  string `json:"parent_id"`
+	Content   string `json:"content"`
+	CreatedAt string `json:"created_at"`
+	DeletedAt string `json:"deleted_at"`
+	HiddenAt  string `json:"hidden_at"`
+}
+
+// isAdminRecord applies auth.IsAdmin to a UserRecord, so ADMIN_ACCOUNTS admins show up as admins.
+func (h *Handler) isAdminRecord(record store.UserRecord) bool {
+	return h.Auth.IsAdmin(store.User{ID: record.ID, Nickname: record.Nickname, Role: record.Role})
+}
+
+// Users handles GET /api/v1/admin/users?q=&page=&page_size=.
+func (h *Handler) Users(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+		return
+	}
+	if _, ok := h.Auth.RequireAdmin(w, r); !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	page := parsePositiveInt(query.Get("page"), 1)
+	pageSize := parsePositiveInt(query.Get("page_size"), 20)
+	records, total, err := h.Store.SearchUsers(query.Get("q"), page, pageSize)
+	if err != nil {
+		transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+		return
+	}
+
+	items := make([]userItem, 0, len(records))
+	for _, record := range records {
+		items = append(items, userItem{UserRecord: record, IsAdmin: h.isAdminRecord(record)})
+	}
+	transport.WriteJSON(w, http.StatusOK, map[string]any{
+		"items": items,
+		"total": total,
+	})
+}
+
+// User handles GET /api/v1/admin/users/{user_id}: profile, sessions, recent IPs and counters.
+func (h *Handler) User(userID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+			return
+		}
+		if _, ok := h.Auth.RequireAdmin(w, r); !ok {
+			return
+		}
+		record, ok := h.Store.GetUserRecord(userID)
+		if !ok {
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			return
+		}
+
+		_, postCount, err := h.Store.UserPosts(userID, 1, 1)
+		if err != nil {
+			transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+			return
+		}
+		_, commentCount, err := h.Store.UserComments(userID, 1, 1)
+		if err != nil {
+			transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+			return
+		}
+		_, reportCount, err := h.Store.ReportsAgainst(userID, 1, 1)
+		if err != nil {
+			transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+			return
+		}
+
+		sessions := h.Store.UserSessions(userID)
+		if sessions == nil {
+			sessions = []store.Session{}
+		}
+		ips := h.Store.UserIPs(userID, recentIPLimit)
+		if ips == nil {
+			ips = []store.UserIP{}
+		}
+		var active *store.Sanction
+		if sanction, ok := h.Store.ActiveSanction(userID); ok {
+			active = &sanction
+		}
+
+		transport.WriteJSON(w, http.StatusOK, map[string]any{
+			"user":            userItem{UserRecord: record, IsAdmin: h.isAdminRecord(record)},
+			"karma":           h.Store.UserKarma(userID),
+			"post_count":      postCount,
+			"comment_count":   commentCount,
+			"report_count":    reportCount,
+			"sessions":        sessions,
+			"recent_ips":      ips,
+			"active_sanction": active,
+		})
+	}
+}
+
+// UserPosts handles GET /api/v1/admin/users/{user_id}/posts, including deleted and hidden posts.
+func (h *Handler) UserPosts(userID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page, pageSize, ok := h.userListRequest(w, r, userID)
+		if !ok {
+			return
+		}
+		posts, total, err := h.Store.UserPosts(userID, page, pageSize)
+		if err != nil {
+			transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+			return
+		}
+
+		items := make([]userPostItem, 0, len(posts))
+		for _, post := range posts {
+			items = append(items, userPostItem{
+				ID:        post.ID,
+				BoardID:   post.BoardID,
+				Title:     post.Title,
+				Content:   post.Content,
+				CreatedAt: post.CreatedAt,
+				DeletedAt: post.DeletedAt,
+				HiddenAt:  post.HiddenAt,
+			})
+		}
+		transport.WriteJSON(w, http.StatusOK, map[string]any{
+			"items": items,
+			"total": total,
+		})
+	}
+}
+
+// UserComments handles GET /api/v1/admin/users/{user_id}/comments, including deleted and hidden comments.
+func (h *Handler) UserComments(userID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page, pageSize, ok := h.userListRequest(w, r, userID)
+		if !ok {
+			return
+		}
+		comments, total, err := h.Store.UserComments(userID, page, pageSize)
+		if err != nil {
+			transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+			return
+		}
+
+		items := make([]userCommentItem, 0, len(comments))
+		for _, comment := range comments {
+			items = append(items, userCommentItem{
+				ID:        comment.ID,
+				PostID:    comment.PostID,
+				ParentID:  comment.ParentID,
+				Content:   comment.Content,
+				CreatedAt: comment.CreatedAt,
+				DeletedAt: comment.DeletedAt,
+				HiddenAt:  comment.HiddenAt,
+			})
+		}
+		transport.WriteJSON(w, http.StatusOK, map[string]any{
+			"items": items,
+			"total": total,
+		})
+	}
+}
+
+// UserReports handles GET /api/v1/admin/users/{user_id}/reports: reports against the user or their content.
+func (h *Handler) UserReports(userID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page, pageSize, ok := h.userListRequest(w, r, userID)
+		if !ok {
+			return
+		}
+		items, total, err := h.Store.ReportsAgainst(userID, page, pageSize)
+		if err != nil {
+			transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+			return
+		}
+		transport.WriteJSON(w, http.StatusOK, map[string]any{
+			"items": items,
+			"total": total,
+		})
+	}
+}
+
+// userListRequest checks method, admin auth and that the user exists, then returns paging params.
+func (h *Handler) userListRequest(w http.ResponseWriter, r *http.Request, userID string) (page, pageSize int, ok bool) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+		return 0, 0, false
+	}
+	if _, ok := h.Auth.RequireAdmin(w, r); !ok {
+		return 0, 0, false
+	}
+	if _, ok := h.Store.GetUser(userID); !ok {
+		transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+		return 0, 0, false
+	}
+	query := r.URL.Query()
+	return parsePositiveInt(query.Get("page"), 1), parsePositiveInt(query.Get("page_size"), 20), true
+}
+
+// UserSessions handles GET/DELETE /api/v1/admin/users/{user_id}/sessions. DELETE logs the
+// user out everywhere.
+func (h *Handler) UserSessions(userID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			if _, ok := h.Auth.RequireAdmin(w, r); !ok {
+				return
+			}
+			if _, ok := h.Store.GetUser(userID); !ok {
+				transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+				return
+			}
+			items := h.Store.UserSessions(userID)
+			if items == nil {
+				items = []store.Session{}
+			}
+			transport.WriteJSON(w, http.StatusOK, map[string]any{"items": items})
+		case http.MethodDelete:
+			admin, ok := h.Auth.RequireAdmin(w, r)
+			if !ok {
+				return
+			}
+			revoked, err := h.Store.RevokeSessions(userID)
+			if err != nil {
+				writeUserError(w, err)
+				return
+			}
+			h.audit(r, store.AuditEntry{
+				ActorID:    admin.ID,
+				Action:     "force_logout",
+				TargetType: "user",
+				TargetID:   userID,
+				After:      store.AuditState(map[string]int{"revoked_sessions": revoked}),
+			})
+			transport.WriteJSON(w, http.StatusOK, map[string]int{"revoked_sessions": revoked})
+		default:
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+		}
+	}
+}
+
+// UserPassword handles POST /api/v1/admin/users/{user_id}/password. Without a password in the
+// body a random one is generated; either way it is returned once and the user is logged out.
+func (h *Handler) UserPassword(userID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+			return
+		}
+		admin, ok := h.Auth.RequireAdmin(w, r)
+		if !ok {
+			return
+		}
+
+		var req struct {
+			Password string `json:"password"`
+		}
+		if r.ContentLength != 0 {
+			if err := transport.ReadJSON(r, &req); err != nil {
+				transport.WriteError(w, http.StatusBadRequest, 2001, "invalid json")
+				return
+			}
+		}
+		password := strings.TrimSpace(req.Password)
+		if password == "" {
+			generated, err := randomPassword()
+			if err != nil {
+				transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+				return
+			}
+			password = generated
+		}
+
+		if err := h.Store.SetPassword(userID, password); err != nil {
+			writeUserError(w, err)
+			return
+		}
+		revoked, err := h.Store.RevokeSessions(userID)
+		if err != nil {
+			writeUserError(w, err)
+			return
+		}
+		h.audit(r, store.AuditEntry{
+			ActorID:    admin.ID,
+			Action:     "reset_password",
+			TargetType: "user",
+			TargetID:   userID,
+			After:      store.AuditState(map[string]int{"revoked_sessions": revoked}),
+		})
+		transport.WriteJSON(w, http.StatusOK, map[string]any{
+			"password":         password,
+			"revoked_sessions": revoked,
+		})
+	}
+}
+
+// UserRole handles PUT /api/v1/admin/users/{user_id}/role with {"role": "user"|"admin"}.
+func (h *Handler) UserRole(userID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+			return
+		}
+		admin, ok := h.Auth.RequireAdmin(w, r)
+		if !ok {
+			return
+		}
+
+		var req struct {
+			Role string `json:"role"`
+		}
+		if err := transport.ReadJSON(r, &req); err != nil {
+			transport.WriteError(w, http.StatusBadRequest, 2001, "invalid json")
+			return
+		}
+		role := strings.TrimSpace(req.Role)
+		if !store.ValidRole(role) {
+			transport.WriteError(w, http.StatusBadRequest, 2001, "invalid role")
+			return
+		}
+		if userID == admin.ID {
+			transport.WriteError(w, http.StatusBadRequest, 2001, "cannot change own role")
+			return
+		}
+
+		before, ok := h.Store.GetUserRecord(userID)
+		if !ok {
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			return
+		}
+		if err := h.Store.SetUserRole(userID, role); err != nil {
+			writeUserError(w, err)
+			return
+		}
+		after := before
+		after.Role = role
+		if before.Role != role {
+			h.audit(r, store.AuditEntry{
+				ActorID:    admin.ID,
+				Action:     "change_role",
+				TargetType: "user",
+				TargetID:   userID,
+				Before:     store.AuditState(map[string]string{"role": before.Role}),
+				After:      store.AuditState(map[string]string{"role": after.Role}),
+			})
+		}
+		transport.WriteJSON(w, http.StatusOK, userItem{UserRecord: after, IsAdmin: h.isAdminRecord(after)})
+	}
+}
+
+func randomPassword() (string, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+func writeUserError(w http.ResponseWriter, err error) {
+	switch err {
+	case store.ErrInvalidInput:
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid fields")
+	case store.ErrNotFound:
+		transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+	default:
+		transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+	}
+}
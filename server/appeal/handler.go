@@ -0,0 +1,316 @@
+package appeal
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Versifine/Cumt-cumpus-hub/server/auth"
+	"github.com/Versifine/Cumt-cumpus-hub/server/internal/transport"
+	"github.com/Versifine/Cumt-cumpus-hub/server/store"
+)
+
+// Handler serves /api/v1/appeals for users and /api/v1/admin/appeals for the admin queue.
+// User endpoints authenticate with RequireSession so suspended and banned users can appeal.
+type Handler struct {
+	Store store.API
+	Auth  *auth.Service
+}
+
+// eligibleLimit caps how many resolved reports/sanctions Eligible looks at.
+const eligibleLimit = 100
+
+// appealItem is an appeal plus the report or sanction it is about, for the admin queue.
+type appealItem struct {
+	store.Appeal
+	Report   *store.Report   `json:"report"`
+	Sanction *store.Sanction `json:"sanction"`
+}
+
+// eligibleReport is a resolved report against the user. The reporter is deliberately left out.
+type eligibleReport struct {
+	ReportID   string `json:"report_id"`
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
+	Reason     string `json:"reason"`
+	Action     string `json:"action"`
+	Outcome    string `json:"outcome"`
+	ResolvedAt string `json:"resolved_at"`
+	AppealID   string `json:"appeal_id"`
+}
+
+type eligibleSanction struct {
+	store.Sanction
+	AppealID string `json:"appeal_id"`
+}
+
+// Appeals handles GET (own appeals) and POST (file an appeal) on /api/v1/appeals.
+func (h *Handler) Appeals(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.listMine(w, r)
+	case http.MethodPost:
+		h.create(w, r)
+	default:
+		transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+	}
+}
+
+func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.Auth.RequireSession(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		TargetType string `json:"target_type"`
+		TargetID   string `json:"target_id"`
+		Reason     string `json:"reason"`
+	}
+	if err := transport.ReadJSON(r, &req); err != nil {
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid json")
+		return
+	}
+	switch strings.TrimSpace(req.TargetType) {
+	case store.AppealTargetReport, store.AppealTargetSanction:
+	default:
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid target_type")
+		return
+	}
+
+	appeal, err := h.Store.CreateAppeal(user.ID, req.TargetType, req.TargetID, req.Reason)
+	if err != nil {
+		switch err {
+		case store.ErrInvalidInput:
+			transport.WriteError(w, http.StatusBadRequest, 2001, "target not appealable")
+		case store.ErrNotFound, store.ErrForbidden:
+			// Someone else's report/sanction looks the same as a missing one.
+			transport.WriteError(w, http.StatusNotFound, 2001, "target not found")
+		case store.ErrConflict:
+			transport.WriteError(w, http.StatusConflict, 2001, "already appealed")
+		default:
+			transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+		}
+		return
+	}
+	transport.WriteJSON(w, http.StatusOK, appeal)
+}
+
+func (h *Handler) listMine(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.Auth.RequireSession(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	page := parsePositiveInt(query.Get("page"), 1)
+	pageSize := parsePositiveInt(query.Get("page_size"), 20)
+
+	items, total, err := h.Store.Appeals(strings.TrimSpace(query.Get("status")), user.ID, page, pageSize)
+	if err != nil {
+		transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+		return
+	}
+	transport.WriteJSON(w, http.StatusOK, map[string]any{
+		"items": items,
+		"total": total,
+	})
+}
+
+// Eligible handles GET /api/v1/appeals/eligible: the resolved reports against the user and the
+// sanctions on their account, each with the appeal already filed for it (if any).
+func (h *Handler) Eligible(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+		return
+	}
+	user, ok := h.Auth.RequireSession(w, r)
+	if !ok {
+		return
+	}
+
+	appeals, _, err := h.Store.Appeals("", user.ID, 1, eligibleLimit)
+	if err != nil {
+		transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+		return
+	}
+	appealed := make(map[string]string, len(appeals))
+	for _, appeal := range appeals {
+		appealed[appeal.TargetType+":"+appeal.TargetID] = appeal.ID
+	}
+
+	against, _, err := h.Store.ReportsAgainst(user.ID, 1, eligibleLimit)
+	if err != nil {
+		transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+		return
+	}
+	reports := make([]eligibleReport, 0, len(against))
+	for _, report := range against {
+		if report.Status != "resolved" {
+			continue
+		}
+		reports = append(reports, eligibleReport{
+			ReportID:   report.ID,
+			TargetType: report.TargetType,
+			TargetID:   report.TargetID,
+			Reason:     report.Reason,
+			Action:     report.Action,
+			Outcome:    report.Outcome,
+			ResolvedAt: report.UpdatedAt,
+			AppealID:   appealed[store.AppealTargetReport+":"+report.ID],
+		})
+	}
+
+	sanctions := make([]eligibleSanction, 0)
+	for _, sanction := range h.Store.Sanctions(user.ID) {
+		if sanction.RevokedAt != "" {
+			continue
+		}
+		sanctions = append(sanctions, eligibleSanction{
+			Sanction: sanction,
+			AppealID: appealed[store.AppealTargetSanction+":"+sanction.ID],
+		})
+	}
+
+	transport.WriteJSON(w, http.StatusOK, map[string]any{
+		"reports":   reports,
+		"sanctions": sanctions,
+	})
+}
+
+// AdminList handles GET /api/v1/admin/appeals?status=&user_id=&page=&page_size=.
+func (h *Handler) AdminList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+		return
+	}
+	if _, ok := h.Auth.RequireAdmin(w, r); !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	page := parsePositiveInt(query.Get("page"), 1)
+	pageSize := parsePositiveInt(query.Get("page_size"), 20)
+	appeals, total, err := h.Store.Appeals(query.Get("status"), query.Get("user_id"), page, pageSize)
+	if err != nil {
+		transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+		return
+	}
+
+	items := make([]appealItem, 0, len(appeals))
+	for _, appeal := range appeals {
+		items = append(items, h.item(appeal))
+	}
+	transport.WriteJSON(w, http.StatusOK, map[string]any{
+		"items": items,
+		"total": total,
+	})
+}
+
+// AdminHandle handles GET/PATCH /api/v1/admin/appeals/{appeal_id}. PATCH takes
+// {"action": "accept"|"reject", "note": "..."}.
+func (h *Handler) AdminHandle(appealID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			if _, ok := h.Auth.RequireAdmin(w, r); !ok {
+				return
+			}
+			appeal, ok := h.Store.GetAppeal(appealID)
+			if !ok {
+				transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+				return
+			}
+			transport.WriteJSON(w, http.StatusOK, h.item(appeal))
+		case http.MethodPatch:
+			admin, ok := h.Auth.RequireAdmin(w, r)
+			if !ok {
+				return
+			}
+			h.resolve(w, r, admin, appealID)
+		default:
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+		}
+	}
+}
+
+func (h *Handler) resolve(w http.ResponseWriter, r *http.Request, admin store.User, appealID string) {
+	var req struct {
+		Action string `json:"action"`
+		Note   string `json:"note"`
+	}
+	if err := transport.ReadJSON(r, &req); err != nil {
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid json")
+		return
+	}
+	var accept bool
+	switch strings.TrimSpace(req.Action) {
+	case "accept":
+		accept = true
+	case "reject":
+	default:
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid action")
+		return
+	}
+
+	updated, err := h.Store.ResolveAppeal(appealID, accept, req.Note, admin.ID)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+		case store.ErrConflict:
+			transport.WriteError(w, http.StatusConflict, 2001, "appeal already handled")
+		default:
+			transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+		}
+		return
+	}
+
+	if _, err := h.Store.AddAuditEntry(store.AuditEntry{
+		ActorID:    admin.ID,
+		Action:     "handle_appeal",
+		TargetType: "appeal",
+		TargetID:   updated.ID,
+		Reason:     updated.Note,
+		Before:     store.AuditState(map[string]string{"status": store.AppealPending}),
+		After: store.AuditState(map[string]string{
+			"status":  updated.Status,
+			"outcome": updated.Outcome,
+		}),
+		IP: transport.ClientIP(r),
+	}); err != nil {
+		log.Printf("audit handle_appeal %s: %v", updated.ID, err)
+	}
+	transport.WriteJSON(w, http.StatusOK, h.item(updated))
+}
+
+// item attaches the appealed report or sanction to an appeal.
+func (h *Handler) item(appeal store.Appeal) appealItem {
+	item := appealItem{Appeal: appeal}
+	switch appeal.TargetType {
+	case store.AppealTargetReport:
+		if report, ok := h.Store.GetReport(appeal.TargetID); ok {
+			item.Report = &report
+		}
+	case store.AppealTargetSanction:
+		for _, sanction := range h.Store.Sanctions(appeal.UserID) {
+			if sanction.ID == appeal.TargetID {
+				item.Sanction = &sanction
+				break
+			}
+		}
+	}
+	return item
+}
+
+func parsePositiveInt(value string, fallback int) int {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		return fallback
+	}
+	return parsed
+}
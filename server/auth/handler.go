@@ -2,6 +2,7 @@ package auth
 
 import (
 	"net/http"
+	"os"
 	"strings"
 
 	"github.com/Versifine/Cumt-cumpus-hub/server/internal/transport"
@@ -10,6 +11,8 @@ import (
 
 type Service struct {
 	Store store.API
+	// OnSession, when set, is called for every request whose token resolved to a user.
+	OnSession func(r *http.Request, token string, user store.User)
 }
 
 type loginRequest struct {
@@ -115,10 +118,12 @@ func (s *Service) MeHandler(w http.ResponseWriter, r *http.Request) {
 	resp := struct {
 		ID        string `json:"id"`
 		Nickname  string `json:"nickname"`
+		IsAdmin   bool   `json:"is_admin"`
 		CreatedAt string `json:"created_at"`
 	}{
 		ID:        user.ID,
 		Nickname:  user.Nickname,
+		IsAdmin:   s.IsAdmin(user),
 		CreatedAt: user.CreatedAt,
 	}
 
@@ -126,14 +131,28 @@ func (s *Service) MeHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // RequireUser extracts the Bearer token, loads the user, and writes a 401 error on failure.
+// Suspended and banned users are rejected with a 403 (see RejectSanctioned).
 func (s *Service) RequireUser(w http.ResponseWriter, r *http.Request) (store.User, bool) {
+	user, ok := s.RequireSession(w, r)
+	if !ok {
+		return store.User{}, false
+	}
+	if s.RejectSanctioned(w, user) {
+		return store.User{}, false
+	}
+	return user, true
+}
+
+// RequireSession is RequireUser without the sanction check, for the few endpoints suspended
+// and banned users still need (filing appeals).
+func (s *Service) RequireSession(w http.ResponseWriter, r *http.Request) (store.User, bool) {
 	token := bearerToken(r)
 	if token == "" {
 		transport.WriteError(w, http.StatusUnauthorized, 1001, "missing token")
 		return store.User{}, false
 	}
 
-	user, ok := s.Store.UserByToken(token)
+	user, ok := s.UserByToken(r, token)
 	if !ok {
 		transport.WriteError(w, http.StatusUnauthorized, 1001, "invalid token")
 		return store.User{}, false
@@ -141,6 +160,87 @@ func (s *Service) RequireUser(w http.ResponseWriter, r *http.Request) (store.Use
 	return user, true
 }
 
+// UserByToken loads the user behind a session token sent with r and reports the session to
+// OnSession. Handlers that take the token from somewhere other than the Authorization header
+// (the WebSocket query string) or treat it as optional use this instead of RequireSession.
+func (s *Service) UserByToken(r *http.Request, token string) (store.User, bool) {
+	user, ok := s.Store.UserByToken(token)
+	if ok && s.OnSession != nil {
+		s.OnSession(r, token, user)
+	}
+	return user, ok
+}
+
+// sanctionErrorResponse is the 403 body for suspended/banned users; ExpiresAt is null for bans.
+type sanctionErrorResponse struct {
+	Code       int     `json:"code"`
+	Message    string  `json:"message"`
+	SanctionID string  `json:"sanction_id"`
+	Reason     string  `json:"reason"`
+	ExpiresAt  *string `json:"expires_at"`
+}
+
+// RejectSanctioned writes a 403 (code 1006) and returns true when the user is currently
+// suspended or banned.
+func (s *Service) RejectSanctioned(w http.ResponseWriter, user store.User) bool {
+	sanction, ok := s.Store.ActiveSanction(user.ID)
+	if !ok {
+		return false
+	}
+	resp := sanctionErrorResponse{
+		Code:       1006,
+		Message:    "account banned",
+		SanctionID: sanction.ID,
+		Reason:     sanction.Reason,
+	}
+	if sanction.Type == store.SanctionSuspension {
+		resp.Message = "account suspended"
+		resp.ExpiresAt = &sanction.ExpiresAt
+	}
+	transport.WriteJSON(w, http.StatusForbidden, resp)
+	return true
+}
+
+// RequireAdmin is RequireUser plus an admin check; it writes a 403 error for non-admins.
+func (s *Service) RequireAdmin(w http.ResponseWriter, r *http.Request) (store.User, bool) {
+	user, ok := s.RequireUser(w, r)
+	if !ok {
+		return store.User{}, false
+	}
+	if !s.IsAdmin(user) {
+		transport.WriteError(w, http.StatusForbidden, 1002, "forbidden")
+		return store.User{}, false
+	}
+	return user, true
+}
+
+// IsAdmin reports whether the user has the admin role or is listed in ADMIN_ACCOUNTS
+// (matched by nickname).
+func (s *Service) IsAdmin(user store.User) bool {
+	if user.Role == store.RoleAdmin {
+		return true
+	}
+	raw := strings.TrimSpace(os.Getenv("ADMIN_ACCOUNTS"))
+	if raw == "" {
+		return false
+	}
+	parts := strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == ';' || r == ' ' || r == '\t' || r == '\n' })
+	for _, part := range parts {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(part), user.Nickname) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanModerate reports whether the user may moderate the board: admins everywhere, moderators on their boards.
+func (s *Service) CanModerate(user store.User, boardID string) bool {
+	return s.IsAdmin(user) || s.Store.IsBoardModerator(boardID, user.ID)
+}
+
 // bearerToken parses Authorization: Bearer <token>.
 func bearerToken(r *http.Request) string {
 	authHeader := strings.TrimSpace(r.Header.Get("Authorization"))
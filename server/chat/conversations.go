@@ -0,0 +1,295 @@
+package chat
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Versifine/Cumt-cumpus-hub/server/internal/transport"
+	"github.com/Versifine/Cumt-cumpus-hub/server/store"
+)
+
+// conversationItem is a conversation as seen by one member: members with nicknames, how many
+// messages they have not read, and the latest message.
+type conversationItem struct {
+	store.Conversation
+	Members     []conversationMember `json:"members"`
+	Unread      int                  `json:"unread"`
+	LastMessage map[string]any       `json:"last_message"`
+
+	lastSeq int
+}
+
+type conversationMember struct {
+	ID          string `json:"id"`
+	Nickname    string `json:"nickname"`
+	LastReadSeq int    `json:"last_read_seq"`
+}
+
+type blockItem struct {
+	UserID    string `json:"user_id"`
+	Nickname  string `json:"nickname"`
+	CreatedAt string `json:"created_at"`
+}
+
+// directBlocked reports whether either side of a direct conversation has blocked the other.
+// Groups are not affected by blocks once they exist.
+func (h *Handler) directBlocked(convo store.Conversation, senderID string) bool {
+	if convo.Kind != store.ConversationDirect {
+		return false
+	}
+	for _, memberID := range convo.MemberIDs() {
+		if memberID != senderID && (h.Store.Blocked(memberID, senderID) || h.Store.Blocked(senderID, memberID)) {
+			return true
+		}
+	}
+	return false
+}
+
+// canReadHistory reports whether the user may read a room's or conversation's history.
+func (h *Handler) canReadHistory(user store.User, roomID string) bool {
+	if store.IsConversationID(roomID) {
+		convo, ok := h.Store.GetConversation(roomID)
+		return ok && convo.HasMember(user.ID)
+	}
+	room, ok := h.Store.GetChatRoom(roomID)
+	return ok && h.canAccessRoom(user, room)
+}
+
+// Conversations handles GET (list) and POST (start) on /api/v1/conversations.
+func (h *Handler) Conversations(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.listConversations(w, r)
+	case http.MethodPost:
+		h.createConversation(w, r)
+	default:
+		transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+	}
+}
+
+func (h *Handler) listConversations(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.Auth.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	convos := h.Store.Conversations(user.ID)
+	items := make([]conversationItem, 0, len(convos))
+	for _, convo := range convos {
+		items = append(items, h.conversationItem(convo, user.ID))
+	}
+	// Most recent activity first; conversations without messages keep the store's newest-first order.
+	sort.SliceStable(items, func(i, j int) bool { return items[i].lastSeq > items[j].lastSeq })
+	transport.WriteJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+// createConversation starts a conversation with member_ids. One other member makes a direct
+// conversation (returning the existing one if the pair already has one); more make a group.
+func (h *Handler) createConversation(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.Auth.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		MemberIDs []string `json:"member_ids"`
+		Title     string   `json:"title"`
+	}
+	if err := transport.ReadJSON(r, &req); err != nil {
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid json")
+		return
+	}
+
+	convo, err := h.Store.CreateConversation(user.ID, req.MemberIDs, req.Title)
+	if err != nil {
+		switch err {
+		case store.ErrInvalidInput:
+			transport.WriteError(w, http.StatusBadRequest, 2001, "invalid fields")
+		case store.ErrNotFound:
+			transport.WriteError(w, http.StatusNotFound, 2001, "user not found")
+		case store.ErrForbidden:
+			transport.WriteError(w, http.StatusForbidden, 1002, "blocked")
+		default:
+			transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+		}
+		return
+	}
+	transport.WriteJSON(w, http.StatusOK, h.conversationItem(convo, user.ID))
+}
+
+// Conversation handles GET (details) and DELETE (leave a group) on
+// /api/v1/conversations/{conversation_id}. Non-members get 404.
+func (h *Handler) Conversation(conversationID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodDelete:
+		default:
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+			return
+		}
+		user, ok := h.Auth.RequireUser(w, r)
+		if !ok {
+			return
+		}
+		convo, ok := h.Store.GetConversation(conversationID)
+		if !ok || !convo.HasMember(user.ID) {
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			transport.WriteJSON(w, http.StatusOK, h.conversationItem(convo, user.ID))
+			return
+		}
+
+		if err := h.Store.LeaveConversation(convo.ID, user.ID); err != nil {
+			switch err {
+			case store.ErrNotFound:
+				transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			case store.ErrInvalidInput:
+				transport.WriteError(w, http.StatusBadRequest, 2001, "cannot leave direct conversation")
+			default:
+				transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+			}
+			return
+		}
+		transport.WriteJSON(w, http.StatusOK, map[string]string{"status": "left"})
+	}
+}
+
+// ConversationRead handles POST /api/v1/conversations/{conversation_id}/read with an optional
+// {"message_id": "m_42"}; without it everything up to the latest message is marked read.
+func (h *Handler) ConversationRead(conversationID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+			return
+		}
+		user, ok := h.Auth.RequireUser(w, r)
+		if !ok {
+			return
+		}
+
+		var req struct {
+			MessageID string `json:"message_id"`
+		}
+		if r.ContentLength != 0 {
+			if err := transport.ReadJSON(r, &req); err != nil {
+				transport.WriteError(w, http.StatusBadRequest, 2001, "invalid json")
+				return
+			}
+		}
+
+		convo, ok := h.Store.GetConversation(conversationID)
+		if !ok || !convo.HasMember(user.ID) {
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			return
+		}
+		seq := 0
+		if strings.TrimSpace(req.MessageID) != "" {
+			if seq, ok = messageSeq(req.MessageID); !ok {
+				transport.WriteError(w, http.StatusBadRequest, 2001, "invalid message_id")
+				return
+			}
+		} else if latest := h.Store.Messages(convo.ID, 0, 1); len(latest) > 0 {
+			seq = latest[0].Seq
+		}
+
+		if err := h.Store.MarkConversationRead(convo.ID, user.ID, seq); err != nil {
+			switch err {
+			case store.ErrNotFound:
+				transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			default:
+				transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+			}
+			return
+		}
+		transport.WriteJSON(w, http.StatusOK, map[string]any{"unread": h.Store.UnreadCount(convo.ID, user.ID)})
+	}
+}
+
+// Blocks handles GET /api/v1/users/me/blocks: the users the caller has blocked.
+func (h *Handler) Blocks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+		return
+	}
+	user, ok := h.Auth.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	blocks := h.Store.BlockedUsers(user.ID)
+	items := make([]blockItem, 0, len(blocks))
+	for _, block := range blocks {
+		blocked, _ := h.Store.GetUser(block.BlockedID)
+		items = append(items, blockItem{UserID: block.BlockedID, Nickname: blocked.Nickname, CreatedAt: block.CreatedAt})
+	}
+	transport.WriteJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+// Block handles PUT (block) and DELETE (unblock) on /api/v1/users/me/blocks/{user_id}. A blocked
+// user cannot start a conversation with the caller or send in their direct conversation.
+func (h *Handler) Block(blockedID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			user, ok := h.Auth.RequireUser(w, r)
+			if !ok {
+				return
+			}
+			if err := h.Store.BlockUser(user.ID, blockedID); err != nil {
+				switch err {
+				case store.ErrInvalidInput:
+					transport.WriteError(w, http.StatusBadRequest, 2001, "cannot block yourself")
+				case store.ErrNotFound:
+					transport.WriteError(w, http.StatusNotFound, 2001, "user not found")
+				default:
+					transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+				}
+				return
+			}
+			transport.WriteJSON(w, http.StatusOK, map[string]string{"status": "blocked"})
+		case http.MethodDelete:
+			user, ok := h.Auth.RequireUser(w, r)
+			if !ok {
+				return
+			}
+			if err := h.Store.UnblockUser(user.ID, blockedID); err != nil {
+				switch err {
+				case store.ErrNotFound:
+					transport.WriteError(w, http.StatusNotFound, 2001, "not blocked")
+				default:
+					transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+				}
+				return
+			}
+			transport.WriteJSON(w, http.StatusOK, map[string]string{"status": "unblocked"})
+		default:
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+		}
+	}
+}
+
+// conversationItem fills in member nicknames, the viewer's unread count and the latest message.
+func (h *Handler) conversationItem(convo store.Conversation, viewerID string) conversationItem {
+	item := conversationItem{
+		Conversation: convo,
+		Members:      make([]conversationMember, 0, len(convo.Members)),
+		Unread:       h.Store.UnreadCount(convo.ID, viewerID),
+	}
+	for _, member := range convo.Members {
+		user, _ := h.Store.GetUser(member.UserID)
+		item.Members = append(item.Members, conversationMember{
+			ID:          member.UserID,
+			Nickname:    user.Nickname,
+			LastReadSeq: member.LastReadSeq,
+		})
+	}
+	if latest := h.Store.Messages(convo.ID, 0, 1); len(latest) > 0 {
+		item.LastMessage = h.messageItems(latest)[0]
+		item.lastSeq = latest[0].Seq
+	}
+	return item
+}
@@ -0,0 +1,87 @@
+package chat
+
+import (
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Versifine/Cumt-cumpus-hub/server/internal/ratelimit"
+)
+
+// Flood control for chat.send. Limits are per user (across all of their connections) and per
+// room, so one user cannot drown a room and a busy room cannot drown the server.
+const (
+	maxMessageRunes = 2000
+	// repeatWindow/maxRepeats: the same text may be sent to a room at most maxRepeats times in a
+	// row within repeatWindow.
+	repeatWindow = 30 * time.Second
+	maxRepeats   = 2
+)
+
+var (
+	userSendLimiter = ratelimit.NewFixedWindow(10*time.Second, 10)
+	roomSendLimiter = ratelimit.NewFixedWindow(10*time.Second, 60)
+	sendRepeats     = newRepeatGuard(repeatWindow, maxRepeats)
+)
+
+// messageTooLong reports whether content exceeds maxMessageRunes.
+func messageTooLong(content string) bool {
+	return utf8.RuneCountInString(content) > maxMessageRunes
+}
+
+// repeatGuard remembers the last message each user sent to each room.
+type repeatGuard struct {
+	mu     sync.Mutex
+	window time.Duration
+	limit  int
+	last   map[string]repeatEntry
+	pruned time.Time
+}
+
+type repeatEntry struct {
+	text  string
+	count int
+	at    time.Time
+}
+
+func newRepeatGuard(window time.Duration, limit int) *repeatGuard {
+	return &repeatGuard{window: window, limit: limit, last: map[string]repeatEntry{}}
+}
+
+// Allow records content for the user/room key and reports whether it may be sent. When it
+// may not, the duration is how long until the same text is accepted again.
+func (g *repeatGuard) Allow(key, content string) (bool, time.Duration) {
+	text := strings.Join(strings.Fields(strings.ToLower(content)), " ")
+	now := time.Now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.prune(now)
+	item := g.last[key]
+	if item.text != text || now.Sub(item.at) > g.window {
+		g.last[key] = repeatEntry{text: text, count: 1, at: now}
+		return true, 0
+	}
+	if item.count >= g.limit {
+		return false, g.window - now.Sub(item.at)
+	}
+	item.count++
+	item.at = now
+	g.last[key] = item
+	return true, 0
+}
+
+// prune drops expired entries, at most once per window. Callers must hold g.mu.
+func (g *repeatGuard) prune(now time.Time) {
+	if now.Sub(g.pruned) < g.window {
+		return
+	}
+	g.pruned = now
+	for key, item := range g.last {
+		if now.Sub(item.at) > g.window {
+			delete(g.last, key)
+		}
+	}
+}
@@ -2,25 +2,83 @@ package chat
 
 import (
 	"encoding/json"
+	"errors"
+	"log"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gorilla/websocket"
 
+	"github.com/Versifine/Cumt-cumpus-hub/server/auth"
+	"github.com/Versifine/Cumt-cumpus-hub/server/internal/contentfilter"
 	"github.com/Versifine/Cumt-cumpus-hub/server/internal/transport"
 	"github.com/Versifine/Cumt-cumpus-hub/server/store"
 )
 
 type Handler struct {
-	Store store.API
-	Hub   *Hub
+	Store  store.API
+	Auth   *auth.Service
+	Hub    *Hub
+	Filter *contentfilter.Filter
 }
 
-// Client represents a single WebSocket connection to a specific user.
+// maxRoomsPerClient caps how many rooms one connection can be in at once.
+const maxRoomsPerClient = 20
+
+// Connection upkeep: the server pings every pingPeriod and drops connections that have not
+// answered (or sent anything) within pongWait, or that cannot take a write within writeWait.
+const (
+	writeWait      = 10 * time.Second
+	pongWait       = 60 * time.Second
+	pingPeriod     = pongWait * 9 / 10
+	maxFrameBytes  = 64 << 10
+	sendBufferSize = 256 // room for a full join replay (maxReplay) plus live traffic
+	// History page sizes for chat.history.
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 100
+	// maxReplay bounds how many missed messages chat.join replays after a reconnect.
+	maxReplay = 100
+	// maxSendDrops is how many messages in a row may be dropped on a full send buffer before
+	// the client is treated as a slow consumer and disconnected.
+	maxSendDrops = 16
+)
+
+// Close codes sent when the server ends a connection.
+const (
+	closeSlowConsumer = 4001
+)
+
+// Client represents a single WebSocket connection to a specific user. One connection can be in
+// several rooms; membership is tracked by Hub.
 type Client struct {
 	Conn *websocket.Conn
 	User store.User
-	Room string
 	Send chan []byte
+
+	rooms     map[string]bool // guarded by Hub.mu
+	replayed  map[string]int  // guarded by Hub.mu; last message seq replayed on join, per room
+	hub       *Hub
+	drops     atomic.Int32  // consecutive messages dropped on a full Send buffer
+	slow      atomic.Bool   // set once the client is marked as a slow consumer
+	kick      chan struct{} // closed when slow is set; writeLoop then evicts the client
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newClient(hub *Hub, conn *websocket.Conn, user store.User) *Client {
+	return &Client{
+		Conn: conn,
+		User: user,
+		Send: make(chan []byte, sendBufferSize),
+		hub:  hub,
+		kick: make(chan struct{}),
+		done: make(chan struct{}),
+	}
 }
 
 type envelope struct {
@@ -34,6 +92,8 @@ type envelope struct {
 type wsError struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
+	// RetryAfterMs is set on rate-limit errors: how long to wait before sending again.
+	RetryAfterMs int64 `json:"retryAfterMs,omitempty"`
 }
 
 var upgrader = websocket.Upgrader{
@@ -49,22 +109,31 @@ func (h *Handler) ServeWS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, ok := h.Store.UserByToken(token)
+	user, ok := h.Auth.UserByToken(r, token)
 	if !ok {
 		transport.WriteError(w, http.StatusUnauthorized, 1001, "invalid token")
 		return
 	}
+	if h.Auth.RejectSanctioned(w, user) {
+		return
+	}
 
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		return
 	}
 
-	client := &Client{
-		Conn: conn,
-		User: user,
-		Send: make(chan []byte, 16),
-	}
+	client := newClient(h.Hub, conn, user)
+	h.Hub.Register(client)
+	defer h.Hub.Unregister(client)
+	h.Hub.connections.Add(1)
+	defer h.Hub.connections.Add(-1)
+
+	conn.SetReadLimit(maxFrameBytes)
+	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
 
 	go client.writeLoop()
 
@@ -75,16 +144,25 @@ func (h *Handler) ServeWS(w http.ResponseWriter, r *http.Request) {
 	for {
 		var msg envelope
 		if err := conn.ReadJSON(&msg); err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				h.Hub.deadConnections.Add(1)
+			}
 			break
 		}
+		_ = conn.SetReadDeadline(time.Now().Add(pongWait))
 
 		switch msg.Type {
 		case "chat.join":
 			h.handleJoin(client, msg)
+		case "chat.leave":
+			h.handleLeave(client, msg)
 		case "chat.send":
 			h.handleSend(client, msg)
 		case "chat.history":
 			h.handleHistory(client, msg)
+		case "chat.typing":
+			h.handleTyping(client, msg)
 		case "system.ping":
 			client.sendEnvelope("system.pong", msg.RequestID, nil)
 		default:
@@ -92,25 +170,111 @@ func (h *Handler) ServeWS(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	h.Hub.Leave(client)
-	close(client.Send)
-	_ = conn.Close()
+	for _, room := range h.Hub.LeaveAll(client) {
+		h.announcePresence(room, client.User, "left")
+	}
+	client.close()
 }
 
+// handleJoin adds the client to a room. After a reconnect the client can pass the last message
+// it saw (lastSeenMessageId); the messages it missed are replayed, up to maxReplay, right after
+// chat.joined and before any live message. gapTooLarge says older missed messages were left out.
 func (h *Handler) handleJoin(client *Client, msg envelope) {
 	var req struct {
-		RoomID string `json:"roomId"`
+		RoomID            string `json:"roomId"`
+		LastSeenMessageID string `json:"lastSeenMessageId"`
 	}
 	if err := json.Unmarshal(msg.Data, &req); err != nil || req.RoomID == "" {
 		client.sendError(msg.RequestID, 3002, "invalid join payload")
 		return
 	}
+	lastSeen := 0
+	if req.LastSeenMessageID != "" {
+		seq, ok := messageSeq(req.LastSeenMessageID)
+		if !ok {
+			client.sendError(msg.RequestID, 3002, "invalid join payload")
+			return
+		}
+		lastSeen = seq
+	}
+	room, ok := h.Store.GetChatRoom(req.RoomID)
+	if !ok || !h.canAccessRoom(client.User, room) {
+		client.sendError(msg.RequestID, 3006, "room not found")
+		return
+	}
+	if !room.Open() {
+		client.sendError(msg.RequestID, 3006, "room archived")
+		return
+	}
+
+	if h.Hub.Member(req.RoomID, client) {
+		// Already receiving this room live, so there is no gap to replay.
+		lastSeen = 0
+	} else if len(h.Hub.Rooms(client)) >= maxRoomsPerClient {
+		client.sendError(msg.RequestID, 3010, "too many rooms")
+		return
+	}
+
+	// The replay is read from the store and encoded before Join takes the hub lock.
+	var missed []store.ChatMessage
+	if lastSeen > 0 {
+		// One extra row tells us whether the gap is larger than what we replay.
+		missed = h.Store.MessagesSince(req.RoomID, lastSeen, maxReplay+1)
+	}
+	gapTooLarge := len(missed) > maxReplay
+	if gapTooLarge {
+		missed = missed[1:]
+	}
+	var replay joinReplay
+	for _, item := range h.messageItems(missed) {
+		item["replayed"] = true
+		if encoded, err := marshalEnvelope(1, "chat.message", "", item, nil); err == nil {
+			replay.Messages = append(replay.Messages, encoded)
+		}
+	}
+	if len(missed) > 0 {
+		replay.LastSeq = missed[len(missed)-1].Seq
+	}
 
-	h.Hub.Leave(client)
-	h.Hub.Join(req.RoomID, client)
+	first := h.Hub.Join(req.RoomID, client, func(info joinInfo) []byte {
+		encoded, err := marshalEnvelope(1, "chat.joined", msg.RequestID, map[string]any{
+			"roomId":      req.RoomID,
+			"rooms":       info.Rooms,
+			"members":     memberList(info.Members),
+			"online":      len(info.Members),
+			"replayed":    len(missed),
+			"gapTooLarge": gapTooLarge,
+		}, nil)
+		if err != nil {
+			return nil
+		}
+		return encoded
+	}, replay)
+	if first {
+		h.announcePresence(req.RoomID, client.User, "joined")
+	}
+}
 
-	client.sendEnvelope("chat.joined", msg.RequestID, map[string]any{
+func (h *Handler) handleLeave(client *Client, msg envelope) {
+	var req struct {
+		RoomID string `json:"roomId"`
+	}
+	if err := json.Unmarshal(msg.Data, &req); err != nil || req.RoomID == "" {
+		client.sendError(msg.RequestID, 3009, "invalid leave payload")
+		return
+	}
+	member, last := h.Hub.Leave(req.RoomID, client)
+	if !member {
+		client.sendError(msg.RequestID, 3004, "not joined")
+		return
+	}
+	if last {
+		h.announcePresence(req.RoomID, client.User, "left")
+	}
+
+	client.sendEnvelope("chat.left", msg.RequestID, map[string]any{
 		"roomId": req.RoomID,
+		"rooms":  h.Hub.Rooms(client),
 	})
 }
 
@@ -123,65 +287,282 @@ func (h *Handler) handleSend(client *Client, msg envelope) {
 		client.sendError(msg.RequestID, 3003, "invalid send payload")
 		return
 	}
-	if client.Room != req.RoomID {
-		client.sendError(msg.RequestID, 3004, "not joined")
+	if messageTooLong(req.Content) {
+		client.sendError(msg.RequestID, 3012, "message too long")
+		return
+	}
+	// Conversations are delivered to their members directly; rooms need a chat.join first.
+	var recipients []string
+	if store.IsConversationID(req.RoomID) {
+		convo, ok := h.Store.GetConversation(req.RoomID)
+		if !ok || !convo.HasMember(client.User.ID) {
+			client.sendError(msg.RequestID, 3006, "room not found")
+			return
+		}
+		if h.directBlocked(convo, client.User.ID) {
+			client.sendError(msg.RequestID, 3015, "blocked")
+			return
+		}
+		recipients = convo.MemberIDs()
+	} else if !h.checkRoomSend(client, msg.RequestID, req.RoomID) {
 		return
 	}
+	// The upgrade check only covers new connections; a sanction issued mid-session stops sends here.
+	if _, sanctioned := h.Store.ActiveSanction(client.User.ID); sanctioned {
+		client.sendError(msg.RequestID, 1006, "account suspended")
+		return
+	}
+	if ok, wait := userSendLimiter.Take(client.User.ID); !ok {
+		client.sendRateLimited(msg.RequestID, 3011, "rate limited", wait)
+		return
+	}
+	if ok, wait := roomSendLimiter.Take(req.RoomID); !ok {
+		client.sendRateLimited(msg.RequestID, 3011, "room rate limited", wait)
+		return
+	}
+	if ok, wait := sendRepeats.Allow(client.User.ID+"|"+req.RoomID, req.Content); !ok {
+		client.sendRateLimited(msg.RequestID, 3013, "repeated message", wait)
+		return
+	}
+
+	var verdict contentfilter.Result
+	if h.Filter != nil {
+		verdict = h.Filter.Check(req.Content)
+		if verdict.Action == contentfilter.ActionReject {
+			client.sendError(msg.RequestID, 3007, "content rejected")
+			return
+		}
+		req.Content = verdict.Text
+	}
 
 	chatMsg := h.Store.AddMessage(req.RoomID, client.User.ID, req.Content)
-	payload := map[string]any{
-		"id":         chatMsg.ID,
-		"roomId":     chatMsg.RoomID,
-		"sender":     map[string]any{"id": client.User.ID, "nickname": client.User.Nickname},
-		"content":    chatMsg.Content,
-		"created_at": chatMsg.CreatedAt,
+	if chatMsg.ID == "" {
+		client.sendError(msg.RequestID, 5000, "server error")
+		return
+	}
+	// The message itself ends the sender's typing indicator on clients.
+	typing.stop(typingKey(client.User.ID, req.RoomID))
+	if verdict.Action == contentfilter.ActionReview {
+		// Chat is live, so flagged messages are still delivered and reviewed afterwards.
+		detail := "matched categories: " + strings.Join(verdict.Categories, ", ")
+		if _, err := h.Store.CreateReport("system", "message", chatMsg.ID, "content_filter", detail); err != nil {
+			log.Printf("queue message %s for review: %v", chatMsg.ID, err)
+		}
 	}
+	payload := messagePayload(chatMsg, client.User)
 
 	encoded, err := marshalEnvelope(1, "chat.message", "", payload, nil)
 	if err != nil {
 		return
 	}
-	h.Hub.Broadcast(req.RoomID, encoded)
+	if recipients != nil {
+		h.Hub.SendToUsers(recipients, encoded)
+		return
+	}
+	h.Hub.BroadcastMessage(req.RoomID, chatMsg.Seq, encoded)
+}
+
+// checkRoomSend checks that the client joined the room and that it still takes messages,
+// sending the error event if not.
+func (h *Handler) checkRoomSend(client *Client, requestID, roomID string) bool {
+	if !h.Hub.Member(roomID, client) {
+		client.sendError(requestID, 3004, "not joined")
+		return false
+	}
+	// Post rooms close and lock with their post, which can change without the hub hearing about it.
+	room, ok := h.Store.GetChatRoom(roomID)
+	if !ok || !room.Open() {
+		h.closeRoom(roomID)
+		client.sendError(requestID, 3006, "room archived")
+		return false
+	}
+	if !room.Writable() {
+		client.sendError(requestID, 3008, "room locked")
+		return false
+	}
+	return true
 }
 
+// handleHistory returns a page of messages, oldest first. Without a cursor it is the latest
+// page; with before (message ID) or beforeSeq it is the page just older than that message.
 func (h *Handler) handleHistory(client *Client, msg envelope) {
 	var req struct {
-		RoomID string `json:"roomId"`
-		Limit  int    `json:"limit"`
+		RoomID    string `json:"roomId"`
+		Limit     int    `json:"limit"`
+		Before    string `json:"before"`
+		BeforeSeq int    `json:"beforeSeq"`
 	}
-	if err := json.Unmarshal(msg.Data, &req); err != nil || req.RoomID == "" {
+	if err := json.Unmarshal(msg.Data, &req); err != nil || req.RoomID == "" || req.BeforeSeq < 0 {
 		client.sendError(msg.RequestID, 3005, "invalid history payload")
 		return
 	}
+	beforeSeq := req.BeforeSeq
+	if req.Before != "" {
+		seq, ok := messageSeq(req.Before)
+		if !ok {
+			client.sendError(msg.RequestID, 3005, "invalid history payload")
+			return
+		}
+		beforeSeq = seq
+	}
+	if !h.canReadHistory(client.User, req.RoomID) {
+		client.sendError(msg.RequestID, 3006, "room not found")
+		return
+	}
 
-	history := h.Store.Messages(req.RoomID, req.Limit)
-	items := make([]map[string]any, 0, len(history))
-	for _, entry := range history {
-		items = append(items, map[string]any{
-			"id":         entry.ID,
-			"content":    entry.Content,
-			"created_at": entry.CreatedAt,
-		})
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultHistoryLimit
 	}
+	limit = min(limit, maxHistoryLimit)
 
-	client.sendEnvelope("chat.history.result", msg.RequestID, map[string]any{
-		"items": items,
-	})
+	// One extra row tells us whether there is an older page.
+	history := h.Store.Messages(req.RoomID, beforeSeq, limit+1)
+	hasMore := len(history) > limit
+	if hasMore {
+		history = history[1:]
+	}
+
+	items := h.messageItems(history)
+	result := map[string]any{
+		"roomId":  req.RoomID,
+		"items":   items,
+		"hasMore": hasMore,
+	}
+	if hasMore {
+		result["nextBefore"] = history[0].ID
+	}
+	client.sendEnvelope("chat.history.result", msg.RequestID, result)
 }
 
+// messageItems renders stored messages with their senders, looking each sender up once.
+func (h *Handler) messageItems(messages []store.ChatMessage) []map[string]any {
+	senders := map[string]store.User{}
+	items := make([]map[string]any, 0, len(messages))
+	for _, entry := range messages {
+		sender, ok := senders[entry.SenderID]
+		if !ok {
+			sender, _ = h.Store.GetUser(entry.SenderID)
+			sender.ID = entry.SenderID
+			senders[entry.SenderID] = sender
+		}
+		items = append(items, messagePayload(entry, sender))
+	}
+	return items
+}
+
+// messagePayload is the wire form of a message, shared by chat.message and chat.history.result.
+func messagePayload(message store.ChatMessage, sender store.User) map[string]any {
+	return map[string]any{
+		"id":         message.ID,
+		"seq":        message.Seq,
+		"roomId":     message.RoomID,
+		"sender":     userSummary(sender),
+		"content":    message.Content,
+		"created_at": message.CreatedAt,
+	}
+}
+
+// messageSeq parses a message ID ("m_42") into its sequence number.
+func messageSeq(id string) (int, bool) {
+	seq, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(id), "m_"))
+	if err != nil || seq <= 0 {
+		return 0, false
+	}
+	return seq, true
+}
+
+// writeLoop is the connection's only writer: it drains Send and pings on a timer. A failed
+// write means the peer is gone, so the connection is closed and the read loop ends too.
 func (c *Client) writeLoop() {
-	for message := range c.Send {
-		_ = c.Conn.WriteMessage(websocket.TextMessage, message)
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case message := <-c.Send:
+			_ = c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.Conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				c.lost()
+				return
+			}
+			c.hub.delivered.Add(1)
+		case <-ticker.C:
+			_ = c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.lost()
+				return
+			}
+		case <-c.kick:
+			c.evict(closeSlowConsumer, "slow consumer")
+			return
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// deliver queues a message without blocking; callers may hold the hub lock. When the buffer is
+// full the message is dropped, and a client that keeps falling behind is marked as a slow
+// consumer for writeLoop to disconnect.
+func (c *Client) deliver(message []byte) {
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+	if c.slow.Load() {
+		return
+	}
+	select {
+	case c.Send <- message:
+		c.drops.Store(0)
+	default:
+		c.hub.dropped.Add(1)
+		if c.drops.Add(1) >= maxSendDrops && c.slow.CompareAndSwap(false, true) {
+			close(c.kick)
+		}
 	}
 }
 
+// evict sends a close frame with the reason and closes the connection. It blocks for up to
+// writeWait, so only writeLoop calls it, never while the hub lock is held.
+func (c *Client) evict(code int, reason string) {
+	c.closeOnce.Do(func() {
+		c.hub.evicted.Add(1)
+		frame := websocket.FormatCloseMessage(code, reason)
+		_ = c.Conn.WriteControl(websocket.CloseMessage, frame, time.Now().Add(writeWait))
+		close(c.done)
+		_ = c.Conn.Close()
+	})
+}
+
+// lost closes a connection whose peer stopped accepting writes.
+func (c *Client) lost() {
+	select {
+	case <-c.done:
+		// Already closed on purpose (evicted or the read loop ended).
+	default:
+		c.hub.deadConnections.Add(1)
+	}
+	c.close()
+}
+
+// close stops the write loop and closes the connection; it is safe to call more than once.
+func (c *Client) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		_ = c.Conn.Close()
+	})
+}
+
 // sendEnvelope marshals and sends a success event to the client.
 func (c *Client) sendEnvelope(eventType string, requestID string, data any) {
 	encoded, err := marshalEnvelope(1, eventType, requestID, data, nil)
 	if err != nil {
 		return
 	}
-	c.Send <- encoded
+	c.deliver(encoded)
 }
 
 // sendError marshals and sends an error event to the client.
@@ -190,7 +571,22 @@ func (c *Client) sendError(requestID string, code int, message string) {
 	if err != nil {
 		return
 	}
-	c.Send <- encoded
+	c.deliver(encoded)
+}
+
+// sendRateLimited sends an error event that tells the client how long to wait, rounded up to
+// the millisecond.
+func (c *Client) sendRateLimited(requestID string, code int, message string, wait time.Duration) {
+	retryAfter := (wait + time.Millisecond - 1) / time.Millisecond
+	encoded, err := marshalEnvelope(1, "error", requestID, nil, &wsError{
+		Code:         code,
+		Message:      message,
+		RetryAfterMs: max(int64(retryAfter), 1),
+	})
+	if err != nil {
+		return
+	}
+	c.deliver(encoded)
 }
 
 // marshalEnvelope builds the protocol envelope used by docs/ws-protocol.md.
@@ -1,40 +1,187 @@
 package chat
 
-import "sync"
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Versifine/Cumt-cumpus-hub/server/store"
+)
 
 type Hub struct {
 	mu    sync.Mutex
 	rooms map[string]map[*Client]bool
+	users map[string]map[*Client]bool // every connection per user, for direct messages
+
+	connections     atomic.Int64
+	delivered       atomic.Int64
+	dropped         atomic.Int64
+	evicted         atomic.Int64
+	deadConnections atomic.Int64
+}
+
+// Metrics is a snapshot of the hub's connection and delivery counters since startup.
+type Metrics struct {
+	Connections     int64 `json:"connections"`
+	Rooms           int   `json:"rooms"`
+	Delivered       int64 `json:"messages_delivered"`
+	Dropped         int64 `json:"messages_dropped"`
+	SlowConsumers   int64 `json:"slow_consumers_evicted"`
+	DeadConnections int64 `json:"dead_connections"`
+}
+
+// joinInfo is what Join hands to its joined callback.
+type joinInfo struct {
+	Rooms   []string     // the client's rooms, sorted
+	Members []store.User // distinct users in the room, sorted by ID
+	// FirstConnection is true when none of the user's other connections was already in the room.
+	FirstConnection bool
 }
 
 // NewHub creates an in-memory chat hub that manages rooms and connected clients.
 func NewHub() *Hub {
 	return &Hub{
 		rooms: map[string]map[*Client]bool{},
+		users: map[string]map[*Client]bool{},
+	}
+}
+
+// Register records a new connection so SendToUsers can reach it.
+func (h *Hub) Register(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.users[client.User.ID] == nil {
+		h.users[client.User.ID] = map[*Client]bool{}
+	}
+	h.users[client.User.ID][client] = true
+}
+
+// Unregister forgets a closed connection.
+func (h *Hub) Unregister(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	clients := h.users[client.User.ID]
+	delete(clients, client)
+	if len(clients) == 0 {
+		delete(h.users, client.User.ID)
 	}
 }
 
-// Join adds a client to a room (and updates client.Room).
-func (h *Hub) Join(room string, client *Client) {
+// joinReplay is a catch-up prepared before Join takes the hub lock: encoded messages the client
+// missed, oldest first, and the seq of the last one.
+type joinReplay struct {
+	Messages [][]byte
+	LastSeq  int
+}
+
+// Join adds a client to a room (and records the room in client.rooms) and reports whether it is
+// the user's first connection in the room. Under the hub lock, right after the client is added,
+// it queues the event joined builds (if joined is not nil) followed by the prepared replay, so
+// both reach the client ahead of any live message broadcast afterwards. joined only sees hub
+// state and must not touch the store or call back into the Hub. BroadcastMessage skips messages
+// up to replay.LastSeq for this client, which also drops those stored after the replay was
+// fetched and already queued, so nothing is delivered twice.
+func (h *Hub) Join(room string, client *Client, joined func(joinInfo) []byte, replay joinReplay) bool {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
+	first := !h.userInRoomLocked(room, client.User.ID)
 	if h.rooms[room] == nil {
 		h.rooms[room] = map[*Client]bool{}
 	}
 	h.rooms[room][client] = true
-	client.Room = room
+	if client.rooms == nil {
+		client.rooms = map[string]bool{}
+	}
+	client.rooms[room] = true
+
+	if joined != nil {
+		info := joinInfo{Rooms: roomsOf(client), Members: h.membersLocked(room), FirstConnection: first}
+		if message := joined(info); message != nil {
+			client.deliver(message)
+		}
+	}
+	for _, message := range replay.Messages {
+		client.deliver(message)
+	}
+	if replay.LastSeq > 0 {
+		if client.replayed == nil {
+			client.replayed = map[string]int{}
+		}
+		client.replayed[room] = replay.LastSeq
+	}
+	return first
 }
 
-// Leave removes a client from its current room (if any).
-func (h *Hub) Leave(client *Client) {
+// Leave removes a client from one room. It reports whether the client was in it and whether
+// that was the user's last connection in the room.
+func (h *Hub) Leave(room string, client *Client) (member, last bool) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	room := client.Room
-	if room == "" {
-		return
+	if !client.rooms[room] {
+		return false, false
 	}
+	h.removeLocked(room, client)
+	return true, !h.userInRoomLocked(room, client.User.ID)
+}
+
+// LeaveAll removes a client from every room it is in (used when the connection closes) and
+// returns the rooms the user has no connection left in.
+func (h *Hub) LeaveAll(client *Client) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var gone []string
+	for room := range client.rooms {
+		h.removeLocked(room, client)
+		if !h.userInRoomLocked(room, client.User.ID) {
+			gone = append(gone, room)
+		}
+	}
+	return gone
+}
+
+// Members returns the distinct users in the room, sorted by ID.
+func (h *Hub) Members(room string) []store.User {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.membersLocked(room)
+}
+
+// membersLocked lists the distinct users in a room. Callers must hold h.mu.
+func (h *Hub) membersLocked(room string) []store.User {
+	seen := map[string]bool{}
+	members := make([]store.User, 0, len(h.rooms[room]))
+	for client := range h.rooms[room] {
+		if seen[client.User.ID] {
+			continue
+		}
+		seen[client.User.ID] = true
+		members = append(members, client.User)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].ID < members[j].ID })
+	return members
+}
+
+// userInRoomLocked reports whether any of the user's connections is in the room. Callers must
+// hold h.mu.
+func (h *Hub) userInRoomLocked(room, userID string) bool {
+	for client := range h.rooms[room] {
+		if client.User.ID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// removeLocked drops one membership. Callers must hold h.mu.
+func (h *Hub) removeLocked(room string, client *Client) {
+	delete(client.rooms, room)
+	delete(client.replayed, room)
 	clients := h.rooms[room]
 	if clients == nil {
 		return
@@ -43,7 +190,56 @@ func (h *Hub) Leave(client *Client) {
 	if len(clients) == 0 {
 		delete(h.rooms, room)
 	}
-	client.Room = ""
+}
+
+// Member reports whether the client is currently in the room.
+func (h *Hub) Member(room string, client *Client) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.rooms[room][client]
+}
+
+// Rooms returns the rooms the client is in, sorted.
+func (h *Hub) Rooms(client *Client) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return roomsOf(client)
+}
+
+// roomsOf lists client.rooms, sorted. Callers must hold h.mu.
+func roomsOf(client *Client) []string {
+	rooms := make([]string, 0, len(client.rooms))
+	for room := range client.rooms {
+		rooms = append(rooms, room)
+	}
+	sort.Strings(rooms)
+	return rooms
+}
+
+// Online returns how many distinct users are in the room; several tabs of one user count once.
+func (h *Hub) Online(room string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.membersLocked(room))
+}
+
+// CloseRoom sends a final message to everyone in the room and removes them from it.
+func (h *Hub) CloseRoom(room string, message []byte) {
+	h.mu.Lock()
+	clients := h.rooms[room]
+	delete(h.rooms, room)
+	for client := range clients {
+		delete(client.rooms, room)
+		delete(client.replayed, room)
+	}
+	h.mu.Unlock()
+
+	for client := range clients {
+		client.deliver(message)
+	}
 }
 
 // Broadcast sends a message to all clients currently in the room.
@@ -57,9 +253,75 @@ func (h *Hub) Broadcast(room string, message []byte) {
 	h.mu.Unlock()
 
 	for _, client := range clients {
-		select {
-		case client.Send <- message:
-		default:
+		client.deliver(message)
+	}
+}
+
+// BroadcastFrom sends a message to everyone in the room except the sender's own connections.
+func (h *Hub) BroadcastFrom(room, senderID string, message []byte) {
+	h.mu.Lock()
+	roomClients := h.rooms[room]
+	clients := make([]*Client, 0, len(roomClients))
+	for client := range roomClients {
+		if client.User.ID == senderID {
+			continue
 		}
+		clients = append(clients, client)
+	}
+	h.mu.Unlock()
+
+	for _, client := range clients {
+		client.deliver(message)
+	}
+}
+
+// SendToUsers sends a message to every connection of the given users, whatever rooms they are in.
+func (h *Hub) SendToUsers(userIDs []string, message []byte) {
+	h.mu.Lock()
+	clients := make([]*Client, 0, len(userIDs))
+	for _, userID := range userIDs {
+		for client := range h.users[userID] {
+			clients = append(clients, client)
+		}
+	}
+	h.mu.Unlock()
+
+	for _, client := range clients {
+		client.deliver(message)
+	}
+}
+
+// BroadcastMessage sends a chat message with the given seq to the room, skipping clients that
+// already received it in a join replay.
+func (h *Hub) BroadcastMessage(room string, seq int, message []byte) {
+	h.mu.Lock()
+	roomClients := h.rooms[room]
+	clients := make([]*Client, 0, len(roomClients))
+	for client := range roomClients {
+		if client.replayed[room] >= seq {
+			continue
+		}
+		clients = append(clients, client)
+	}
+	h.mu.Unlock()
+
+	for _, client := range clients {
+		client.deliver(message)
+	}
+}
+
+// Metrics returns the current counters.
+func (h *Hub) Metrics() Metrics {
+	h.mu.Lock()
+	rooms := len(h.rooms)
+	h.mu.Unlock()
+
+	return Metrics{
+		Connections:     h.connections.Load(),
+		Rooms:           rooms,
+		Delivered:       h.delivered.Load(),
+		Dropped:         h.dropped.Load(),
+		SlowConsumers:   h.evicted.Load(),
+		DeadConnections: h.deadConnections.Load(),
 	}
 }
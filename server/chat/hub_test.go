@@ -0,0 +1,69 @@
+package chat
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Versifine/Cumt-cumpus-hub/server/store"
+)
+
+// drain returns everything queued on the client's Send channel.
+func drain(client *Client) []string {
+	var out []string
+	for {
+		select {
+		case message := <-client.Send:
+			out = append(out, string(message))
+		default:
+			return out
+		}
+	}
+}
+
+func TestJoinReplaySkipsReplayedBroadcasts(t *testing.T) {
+	hub := NewHub()
+	alice := newClient(hub, nil, store.User{ID: "u_1"})
+	bob := newClient(hub, nil, store.User{ID: "u_2"})
+	hub.Join("general", bob, nil, joinReplay{})
+
+	joined := func(info joinInfo) []byte { return []byte("joined") }
+	replay := joinReplay{Messages: [][]byte{[]byte("m_4"), []byte("m_5")}, LastSeq: 5}
+	if first := hub.Join("general", alice, joined, replay); !first {
+		t.Fatalf("Join = false, want first connection")
+	}
+	if got, want := drain(alice), []string{"joined", "m_4", "m_5"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("after join alice got %v, want %v", got, want)
+	}
+
+	// m_5 was stored after the replay was fetched but broadcast after the join: only bob needs it.
+	hub.BroadcastMessage("general", 5, []byte("m_5"))
+	hub.BroadcastMessage("general", 6, []byte("m_6"))
+	if got, want := drain(alice), []string{"m_6"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("alice got %v, want %v", got, want)
+	}
+	if got, want := drain(bob), []string{"m_5", "m_6"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("bob got %v, want %v", got, want)
+	}
+}
+
+func TestLeaveClearsReplayed(t *testing.T) {
+	hub := NewHub()
+	alice := newClient(hub, nil, store.User{ID: "u_1"})
+	hub.Join("general", alice, nil, joinReplay{Messages: [][]byte{[]byte("m_5")}, LastSeq: 5})
+	drain(alice)
+
+	if member, last := hub.Leave("general", alice); !member || !last {
+		t.Fatalf("Leave = (%v, %v), want (true, true)", member, last)
+	}
+	hub.BroadcastMessage("general", 6, []byte("m_6"))
+	if got := drain(alice); len(got) != 0 {
+		t.Fatalf("after leave alice got %v, want nothing", got)
+	}
+
+	// Rejoining without a replay must not keep skipping messages up to the old seq.
+	hub.Join("general", alice, nil, joinReplay{})
+	hub.BroadcastMessage("general", 3, []byte("m_3"))
+	if got, want := drain(alice), []string{"m_3"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("after rejoin alice got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,138 @@
+package chat
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/Versifine/Cumt-cumpus-hub/server/store"
+)
+
+const (
+	// typingThrottle is the minimum gap between two typing-start broadcasts for the same user
+	// and room; clients repeat chat.typing while the user keeps typing.
+	typingThrottle = 3 * time.Second
+	// typingTTL is how long a typing indicator lasts without a refresh. Clients apply the same
+	// timeout, so a lost stop event does not leave an indicator stuck.
+	typingTTL = 5 * time.Second
+	// maxPresenceMembers caps the member list sent in chat.joined.
+	maxPresenceMembers = 200
+)
+
+var typing = newTypingTracker()
+
+// typingTracker remembers when a typing start was last broadcast per user and room.
+type typingTracker struct {
+	mu     sync.Mutex
+	active map[string]time.Time
+	pruned time.Time
+}
+
+func newTypingTracker() *typingTracker {
+	return &typingTracker{active: map[string]time.Time{}}
+}
+
+func typingKey(userID, roomID string) string {
+	return userID + "|" + roomID
+}
+
+// start reports whether a typing-start should be broadcast now.
+func (t *typingTracker) start(key string) bool {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.prune(now)
+	if at, ok := t.active[key]; ok && now.Sub(at) < typingThrottle {
+		return false
+	}
+	t.active[key] = now
+	return true
+}
+
+// stop clears the indicator and reports whether a typing-stop should be broadcast (only when
+// a start went out recently enough that clients still show it).
+func (t *typingTracker) stop(key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	at, ok := t.active[key]
+	delete(t.active, key)
+	return ok && time.Since(at) < typingTTL
+}
+
+// prune drops expired indicators, at most once per TTL. Callers must hold t.mu.
+func (t *typingTracker) prune(now time.Time) {
+	if now.Sub(t.pruned) < typingTTL {
+		return
+	}
+	t.pruned = now
+	for key, at := range t.active {
+		if now.Sub(at) >= typingTTL {
+			delete(t.active, key)
+		}
+	}
+}
+
+// userSummary is how a user appears in chat events.
+func userSummary(user store.User) map[string]any {
+	return map[string]any{"id": user.ID, "nickname": user.Nickname}
+}
+
+// memberList renders at most maxPresenceMembers members for chat.joined.
+func memberList(members []store.User) []map[string]any {
+	out := make([]map[string]any, 0, min(len(members), maxPresenceMembers))
+	for _, member := range members[:min(len(members), maxPresenceMembers)] {
+		out = append(out, userSummary(member))
+	}
+	return out
+}
+
+// announcePresence tells the rest of the room that a user came online in it or left it. It is
+// only called for the user's first connection in and last connection out of a room.
+func (h *Handler) announcePresence(roomID string, user store.User, action string) {
+	if action == "left" {
+		typing.stop(typingKey(user.ID, roomID))
+	}
+	encoded, err := marshalEnvelope(1, "chat.presence", "", map[string]any{
+		"roomId": roomID,
+		"user":   userSummary(user),
+		"action": action,
+		"online": len(h.Hub.Members(roomID)),
+	}, nil)
+	if err != nil {
+		return
+	}
+	h.Hub.BroadcastFrom(roomID, user.ID, encoded)
+}
+
+// handleTyping relays typing start/stop to the rest of the room, throttled per user.
+func (h *Handler) handleTyping(client *Client, msg envelope) {
+	var req struct {
+		RoomID string `json:"roomId"`
+		Typing *bool  `json:"typing"`
+	}
+	if err := json.Unmarshal(msg.Data, &req); err != nil || req.RoomID == "" || req.Typing == nil {
+		client.sendError(msg.RequestID, 3014, "invalid typing payload")
+		return
+	}
+	if !h.Hub.Member(req.RoomID, client) {
+		client.sendError(msg.RequestID, 3004, "not joined")
+		return
+	}
+
+	key := typingKey(client.User.ID, req.RoomID)
+	if *req.Typing && !typing.start(key) || !*req.Typing && !typing.stop(key) {
+		return
+	}
+	encoded, err := marshalEnvelope(1, "chat.typing", "", map[string]any{
+		"roomId": req.RoomID,
+		"user":   userSummary(client.User),
+		"typing": *req.Typing,
+	}, nil)
+	if err != nil {
+		return
+	}
+	h.Hub.BroadcastFrom(req.RoomID, client.User.ID, encoded)
+}
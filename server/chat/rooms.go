@@ -0,0 +1,188 @@
+package chat
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/Versifine/Cumt-cumpus-hub/server/internal/transport"
+	"github.com/Versifine/Cumt-cumpus-hub/server/store"
+)
+
+// roomItem is a room plus how many distinct users are currently in it.
+type roomItem struct {
+	store.ChatRoom
+	Online int `json:"online"`
+}
+
+// canAccessRoom reports whether the user may see and join the room: public rooms are open to
+// everyone, private rooms only to their owner and admins.
+func (h *Handler) canAccessRoom(user store.User, room store.ChatRoom) bool {
+	return room.Visibility == store.RoomPublic || room.OwnerID == user.ID || h.Auth.IsAdmin(user)
+}
+
+// Rooms handles GET (list) and POST (create) on /api/v1/chat/rooms.
+func (h *Handler) Rooms(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.listRooms(w, r)
+	case http.MethodPost:
+		h.createRoom(w, r)
+	default:
+		transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+	}
+}
+
+func (h *Handler) listRooms(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.Auth.RequireUser(w, r)
+	if !ok {
+		return
+	}
+	includeArchived := r.URL.Query().Get("include_archived") == "1"
+
+	items := make([]roomItem, 0)
+	for _, room := range h.Store.ChatRooms() {
+		if !h.canAccessRoom(user, room) || (!room.Open() && !includeArchived) {
+			continue
+		}
+		items = append(items, roomItem{ChatRoom: room, Online: h.Hub.Online(room.ID)})
+	}
+	transport.WriteJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+func (h *Handler) createRoom(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.Auth.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Visibility  string `json:"visibility"`
+	}
+	if err := transport.ReadJSON(r, &req); err != nil {
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid json")
+		return
+	}
+
+	room, err := h.Store.CreateChatRoom(store.ChatRoom{
+		Name:        req.Name,
+		Description: req.Description,
+		Visibility:  req.Visibility,
+		OwnerID:     user.ID,
+	})
+	if err != nil {
+		switch err {
+		case store.ErrInvalidInput:
+			transport.WriteError(w, http.StatusBadRequest, 2001, "invalid fields")
+		default:
+			transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+		}
+		return
+	}
+	transport.WriteJSON(w, http.StatusOK, roomItem{ChatRoom: room})
+}
+
+// Room handles GET (details) and DELETE (archive) on /api/v1/chat/rooms/{room_id}. Only the
+// owner or an admin can archive; connected members are removed from the room.
+func (h *Handler) Room(roomID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodDelete:
+		default:
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+			return
+		}
+		user, ok := h.Auth.RequireUser(w, r)
+		if !ok {
+			return
+		}
+		room, ok := h.Store.GetChatRoom(roomID)
+		if !ok || !h.canAccessRoom(user, room) {
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			transport.WriteJSON(w, http.StatusOK, roomItem{ChatRoom: room, Online: h.Hub.Online(room.ID)})
+			return
+		}
+
+		if room.PostID != "" {
+			transport.WriteError(w, http.StatusBadRequest, 2001, "post rooms follow their post")
+			return
+		}
+		if room.OwnerID != user.ID && !h.Auth.IsAdmin(user) {
+			transport.WriteError(w, http.StatusForbidden, 1002, "forbidden")
+			return
+		}
+		archived, err := h.Store.ArchiveChatRoom(room.ID)
+		if err != nil {
+			switch err {
+			case store.ErrNotFound:
+				transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			case store.ErrConflict:
+				transport.WriteError(w, http.StatusConflict, 2001, "room already archived")
+			default:
+				transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+			}
+			return
+		}
+
+		h.closeRoom(archived.ID)
+		if _, err := h.Store.AddAuditEntry(store.AuditEntry{
+			ActorID:    user.ID,
+			Action:     "archive_chat_room",
+			TargetType: "chat_room",
+			TargetID:   archived.ID,
+			Before:     store.AuditState(room),
+			After:      store.AuditState(archived),
+			IP:         transport.ClientIP(r),
+		}); err != nil {
+			log.Printf("audit archive_chat_room %s: %v", archived.ID, err)
+		}
+		transport.WriteJSON(w, http.StatusOK, roomItem{ChatRoom: archived})
+	}
+}
+
+// PostChanged pushes a post's lock/delete state to its discussion room: members of a closed room
+// get chat.room.archived and are removed, otherwise they get chat.room.locked with the new state.
+func (h *Handler) PostChanged(postID string) {
+	room, ok := h.Store.GetChatRoom(store.PostRoomID(postID))
+	if !ok || !room.Open() {
+		h.closeRoom(store.PostRoomID(postID))
+		return
+	}
+	encoded, err := marshalEnvelope(1, "chat.room.locked", "", map[string]any{
+		"roomId": room.ID,
+		"locked": !room.Writable(),
+	}, nil)
+	if err != nil {
+		return
+	}
+	h.Hub.Broadcast(room.ID, encoded)
+}
+
+// PostRoomOnline returns how many users are in a post's discussion room.
+func (h *Handler) PostRoomOnline(postID string) int {
+	return h.Hub.Online(store.PostRoomID(postID))
+}
+
+// closeRoom tells everyone in the room it was archived and removes them from it.
+func (h *Handler) closeRoom(roomID string) {
+	if encoded, err := marshalEnvelope(1, "chat.room.archived", "", map[string]any{"roomId": roomID}, nil); err == nil {
+		h.Hub.CloseRoom(roomID, encoded)
+	}
+}
+
+// Metrics handles GET /api/v1/admin/chat/metrics: live connections and delivery counters.
+func (h *Handler) Metrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+		return
+	}
+	if _, ok := h.Auth.RequireAdmin(w, r); !ok {
+		return
+	}
+	transport.WriteJSON(w, http.StatusOK, h.Hub.Metrics())
+}
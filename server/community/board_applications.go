@@ -0,0 +1,86 @@
+package community
+
+import (
+	"net/http"
+
+	"github.com/Versifine/Cumt-cumpus-hub/server/internal/transport"
+	"github.com/Versifine/Cumt-cumpus-hub/server/store"
+)
+
+// BoardApplications handles POST /api/v1/board-applications (apply for a new board)
+// and GET /api/v1/board-applications (the current user's applications).
+func (h *Handler) BoardApplications(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.listMyBoardApplications(w, r)
+	case http.MethodPost:
+		h.createBoardApplication(w, r)
+	default:
+		transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+	}
+}
+
+func (h *Handler) createBoardApplication(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.Auth.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Icon        string `json:"icon"`
+		Rules       string `json:"rules"`
+		QQGroup     string `json:"qq_group"`
+		Reason      string `json:"reason"`
+	}
+	if err := transport.ReadJSON(r, &req); err != nil {
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid json")
+		return
+	}
+
+	app, err := h.Store.CreateBoardApplication(store.BoardApplication{
+		ApplicantID: user.ID,
+		Name:        req.Name,
+		Description: req.Description,
+		Icon:        req.Icon,
+		Rules:       req.Rules,
+		QQGroup:     req.QQGroup,
+		Reason:      req.Reason,
+	})
+	if err != nil {
+		switch err {
+		case store.ErrInvalidInput:
+			transport.WriteError(w, http.StatusBadRequest, 2001, "invalid fields")
+		case store.ErrConflict:
+			transport.WriteError(w, http.StatusConflict, 2001, "board name already taken")
+		default:
+			transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+		}
+		return
+	}
+
+	transport.WriteJSON(w, http.StatusOK, app)
+}
+
+func (h *Handler) listMyBoardApplications(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.Auth.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	page := parsePositiveInt(r.URL.Query().Get("page"), 1)
+	pageSize := parsePositiveInt(r.URL.Query().Get("page_size"), 20)
+
+	items, total, err := h.Store.BoardApplications("", user.ID, page, pageSize)
+	if err != nil {
+		transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+		return
+	}
+
+	resp := map[string]any{
+		"items": items,
+		"total": total,
+	}
+	transport.WriteJSON(w, http.StatusOK, resp)
+}
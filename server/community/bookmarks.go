@@ -0,0 +1,80 @@
+package community
+
+import (
+	"net/http"
+
+	"github.com/Versifine/Cumt-cumpus-hub/server/internal/transport"
+	"github.com/Versifine/Cumt-cumpus-hub/server/store"
+)
+
+// Bookmark handles POST/DELETE /api/v1/posts/{post_id}/bookmark.
+func (h *Handler) Bookmark(postID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+			return
+		}
+
+		user, ok := h.Auth.RequireUser(w, r)
+		if !ok {
+			return
+		}
+
+		var err error
+		if r.Method == http.MethodPost {
+			err = h.Store.BookmarkPost(postID, user.ID)
+		} else {
+			err = h.Store.UnbookmarkPost(postID, user.ID)
+		}
+		if err != nil {
+			switch err {
+			case store.ErrNotFound:
+				transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			case store.ErrInvalidInput:
+				transport.WriteError(w, http.StatusBadRequest, 2001, "invalid input")
+			default:
+				transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+			}
+			return
+		}
+
+		resp := map[string]any{
+			"post_id":       postID,
+			"my_bookmarked": r.Method == http.MethodPost,
+		}
+		transport.WriteJSON(w, http.StatusOK, resp)
+	}
+}
+
+// MyBookmarks handles GET /api/v1/users/me/bookmarks (newest bookmark first).
+func (h *Handler) MyBookmarks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+		return
+	}
+
+	user, ok := h.Auth.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	page := parsePositiveInt(r.URL.Query().Get("page"), 1)
+	pageSize := parsePositiveInt(r.URL.Query().Get("page_size"), 20)
+
+	posts, total, err := h.Store.Bookmarks(user.ID, page, pageSize)
+	if err != nil {
+		transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+		return
+	}
+
+	items := h.buildPostItems(posts, user.ID)
+
+	resp := struct {
+		Items []postItem `json:"items"`
+		Total int        `json:"total"`
+	}{
+		Items: items,
+		Total: total,
+	}
+	transport.WriteJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,179 @@
+package community
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Versifine/Cumt-cumpus-hub/server/internal/transport"
+	"github.com/Versifine/Cumt-cumpus-hub/server/store"
+)
+
+// Sort modes accepted by GET /api/v1/posts and GET /api/v1/feed.
+const (
+	sortNew = "new"
+	sortTop = "top"
+	sortHot = "hot"
+)
+
+// BoardSubscription handles POST/DELETE /api/v1/boards/{board_id}/subscription.
+func (h *Handler) BoardSubscription(boardID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+			return
+		}
+
+		user, ok := h.Auth.RequireUser(w, r)
+		if !ok {
+			return
+		}
+
+		var err error
+		if r.Method == http.MethodPost {
+			err = h.Store.SubscribeBoard(boardID, user.ID)
+		} else {
+			err = h.Store.UnsubscribeBoard(boardID, user.ID)
+		}
+		if err != nil {
+			switch err {
+			case store.ErrNotFound:
+				transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			case store.ErrInvalidInput:
+				transport.WriteError(w, http.StatusBadRequest, 2001, "invalid input")
+			default:
+				transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+			}
+			return
+		}
+
+		resp := map[string]any{
+			"board_id":         boardID,
+			"subscribed":       r.Method == http.MethodPost,
+			"subscriber_count": h.Store.BoardSubscriberCount(boardID),
+		}
+		transport.WriteJSON(w, http.StatusOK, resp)
+	}
+}
+
+// Feed handles GET /api/v1/feed: posts from the viewer's subscribed boards.
+func (h *Handler) Feed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+		return
+	}
+
+	user, ok := h.Auth.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	page := parsePositiveInt(r.URL.Query().Get("page"), 1)
+	pageSize := parsePositiveInt(r.URL.Query().Get("page_size"), 20)
+	sortMode := strings.TrimSpace(r.URL.Query().Get("sort"))
+	if sortMode == "" {
+		sortMode = sortNew
+	}
+	if !validSortMode(sortMode) {
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid sort")
+		return
+	}
+
+	var posts []store.Post
+	for _, boardID := range h.Store.SubscribedBoardIDs(user.ID) {
+		posts = append(posts, h.Store.Posts(boardID)...)
+	}
+	h.sortPosts(posts, sortMode)
+	total := len(posts)
+
+	start := (page - 1) * pageSize
+	if start > total {
+		start = total
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+
+	items := h.buildPostItems(posts[start:end], user.ID)
+
+	resp := struct {
+		Items []postItem `json:"items"`
+		Total int        `json:"total"`
+		Sort  string     `json:"sort"`
+	}{
+		Items: items,
+		Total: total,
+		Sort:  sortMode,
+	}
+	transport.WriteJSON(w, http.StatusOK, resp)
+}
+
+// validSortMode accepts an empty mode (store order) or one of the named modes.
+func validSortMode(mode string) bool {
+	switch mode {
+	case "", sortNew, sortTop, sortHot:
+		return true
+	default:
+		return false
+	}
+}
+
+// sortPosts orders posts in place. An empty mode keeps the store order.
+func (h *Handler) sortPosts(posts []store.Post, mode string) {
+	if mode == "" || len(posts) < 2 {
+		return
+	}
+
+	keys := make(map[string]float64, len(posts))
+	for _, post := range posts {
+		created := parseTime(post.CreatedAt)
+		switch mode {
+		case sortNew:
+			keys[post.ID] = float64(created.Unix())
+		case sortTop:
+			keys[post.ID] = float64(h.Store.PostScore(post.ID))
+		case sortHot:
+			keys[post.ID] = hotRank(h.Store.PostScore(post.ID), created)
+		}
+	}
+
+	// Ties fall back to newest first (higher ID sequence).
+	sort.SliceStable(posts, func(i, j int) bool {
+		ki, kj := keys[posts[i].ID], keys[posts[j].ID]
+		if ki != kj {
+			return ki > kj
+		}
+		return store.IDSeq(posts[i].ID) > store.IDSeq(posts[j].ID)
+	})
+}
+
+// pinnedFirst moves pinned posts to the front, keeping the existing order within each group.
+func pinnedFirst(posts []store.Post) {
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].PinnedAt != "" && posts[j].PinnedAt == ""
+	})
+}
+
+// hotRank is the classic Reddit "hot" formula: log-scaled score plus a time bonus
+// so that newer posts need fewer votes to rank higher.
+func hotRank(score int, created time.Time) float64 {
+	order := math.Log10(math.Max(math.Abs(float64(score)), 1))
+	sign := 0.0
+	if score > 0 {
+		sign = 1
+	} else if score < 0 {
+		sign = -1
+	}
+	return sign*order + float64(created.Unix())/45000
+}
+
+func parseTime(value string) time.Time {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
@@ -1,26 +1,34 @@
 package community
 
 import (
+	"log"
 	"net/http"
-	"net/netip"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Versifine/Cumt-cumpus-hub/server/auth"
+	"github.com/Versifine/Cumt-cumpus-hub/server/internal/contentfilter"
 	"github.com/Versifine/Cumt-cumpus-hub/server/internal/ratelimit"
+	"github.com/Versifine/Cumt-cumpus-hub/server/internal/spam"
 	"github.com/Versifine/Cumt-cumpus-hub/server/internal/transport"
 	"github.com/Versifine/Cumt-cumpus-hub/server/store"
 )
 
 type Handler struct {
-	Store store.API
-	Auth  *auth.Service
+	Store  store.API
+	Auth   *auth.Service
+	Filter *contentfilter.Filter
+	// PostChanged, if set, is called after a post is deleted so its chat room can close.
+	PostChanged func(postID string)
+	// ChatOnline, if set, returns how many users are in a post's chat room.
+	ChatOnline func(postID string) int
 }
 
 var (
 	postLimiter    = ratelimit.NewFixedWindow(30*time.Second, 5)
 	commentLimiter = ratelimit.NewFixedWindow(30*time.Second, 10)
+	spamDetector   = spam.NewDetector(spam.DefaultConfig)
 )
 
 // Boards handles GET /api/v1/boards.
@@ -30,7 +38,25 @@ func (h *Handler) Boards(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	transport.WriteJSON(w, http.StatusOK, h.Store.Boards())
+	viewerID := h.viewerID(r)
+	boards := h.Store.Boards()
+	items := make([]boardItem, 0, len(boards))
+	for _, board := range boards {
+		items = append(items, boardItem{
+			ID:              board.ID,
+			Name:            board.Name,
+			Description:     board.Description,
+			Icon:            board.Icon,
+			Rules:           board.Rules,
+			QQGroup:         board.QQGroup,
+			Archived:        board.Archived,
+			ReadOnly:        board.ReadOnly,
+			SubscriberCount: h.Store.BoardSubscriberCount(board.ID),
+			Subscribed:      viewerID != "" && h.Store.BoardSubscribed(board.ID, viewerID),
+		})
+	}
+
+	transport.WriteJSON(w, http.StatusOK, items)
 }
 
 // Posts handles GET /api/v1/posts and POST /api/v1/posts.
@@ -116,9 +142,18 @@ func (h *Handler) listPosts(w http.ResponseWriter, r *http.Request) {
 	boardID := r.URL.Query().Get("board_id")
 	page := parsePositiveInt(r.URL.Query().Get("page"), 1)
 	pageSize := parsePositiveInt(r.URL.Query().Get("page_size"), 20)
+	sortMode := strings.TrimSpace(r.URL.Query().Get("sort"))
+	if !validSortMode(sortMode) {
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid sort")
+		return
+	}
 
 	viewerID := h.viewerID(r)
 	posts := h.Store.Posts(boardID)
+	h.sortPosts(posts, sortMode)
+	if boardID != "" {
+		pinnedFirst(posts)
+	}
 	total := len(posts)
 
 	start := (page - 1) * pageSize
@@ -130,39 +165,7 @@ func (h *Handler) listPosts(w http.ResponseWriter, r *http.Request) {
 		end = total
 	}
 
-	items := make([]postItem, 0, end-start)
-	for _, post := range posts[start:end] {
-		author, _ := h.Store.GetUser(post.AuthorID)
-		board, _ := h.Store.GetBoard(post.BoardID)
-		var boardInfo *boardSummary
-		if strings.TrimSpace(board.ID) != "" {
-			boardInfo = &boardSummary{
-				ID:   board.ID,
-				Name: board.Name,
-			}
-		}
-		score := h.Store.PostScore(post.ID)
-		myVote := 0
-		if viewerID != "" {
-			myVote = h.Store.PostVote(post.ID, viewerID)
-		}
-		commentCount := h.Store.CommentCount(post.ID)
-
-		items = append(items, postItem{
-			ID:           post.ID,
-			Title:        post.Title,
-			Content:      post.Content,
-			Score:        score,
-			CommentCount: commentCount,
-			MyVote:       myVote,
-			Author: userSummary{
-				ID:       author.ID,
-				Nickname: author.Nickname,
-			},
-			Board:     boardInfo,
-			CreatedAt: post.CreatedAt,
-		})
-	}
+	items := h.buildPostItems(posts[start:end], viewerID)
 
 	resp := struct {
 		Items []postItem `json:"items"`
@@ -175,6 +178,61 @@ func (h *Handler) listPosts(w http.ResponseWriter, r *http.Request) {
 	transport.WriteJSON(w, http.StatusOK, resp)
 }
 
+// buildPostItems assembles list items for a page of posts, loading their attachments in one query.
+func (h *Handler) buildPostItems(posts []store.Post, viewerID string) []postItem {
+	postIDs := make([]string, 0, len(posts))
+	for _, post := range posts {
+		postIDs = append(postIDs, post.ID)
+	}
+	files := h.Store.PostAttachments(postIDs)
+
+	items := make([]postItem, 0, len(posts))
+	for _, post := range posts {
+		items = append(items, h.buildPostItem(post, viewerID, files[post.ID]))
+	}
+	return items
+}
+
+// buildPostItem assembles the list representation of a post for the given viewer.
+func (h *Handler) buildPostItem(post store.Post, viewerID string, files []store.FileMeta) postItem {
+	author, _ := h.Store.GetUser(post.AuthorID)
+	board, _ := h.Store.GetBoard(post.BoardID)
+	var boardInfo *boardSummary
+	if strings.TrimSpace(board.ID) != "" {
+		boardInfo = &boardSummary{
+			ID:       board.ID,
+			Name:     board.Name,
+			Archived: board.Archived,
+			ReadOnly: board.ReadOnly,
+		}
+	}
+	myVote := 0
+	myBookmarked := false
+	if viewerID != "" {
+		myVote = h.Store.PostVote(post.ID, viewerID)
+		myBookmarked = h.Store.PostBookmarked(post.ID, viewerID)
+	}
+
+	return postItem{
+		ID:           post.ID,
+		Title:        post.Title,
+		Content:      post.Content,
+		Score:        h.Store.PostScore(post.ID),
+		CommentCount: h.Store.CommentCount(post.ID),
+		MyVote:       myVote,
+		MyBookmarked: myBookmarked,
+		Pinned:       post.PinnedAt != "",
+		Locked:       post.LockedAt != "",
+		Author: userSummary{
+			ID:       author.ID,
+			Nickname: author.Nickname,
+		},
+		Board:       boardInfo,
+		Attachments: attachmentItems(files),
+		CreatedAt:   post.CreatedAt,
+	}
+}
+
 func (h *Handler) createPost(w http.ResponseWriter, r *http.Request) {
 	user, ok := h.Auth.RequireUser(w, r)
 	if !ok {
@@ -186,9 +244,10 @@ func (h *Handler) createPost(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		BoardID string `json:"board_id"`
-		Title   string `json:"title"`
-		Content string `json:"content"`
+		BoardID     string   `json:"board_id"`
+		Title       string   `json:"title"`
+		Content     string   `json:"content"`
+		Attachments []string `json:"attachments"`
 	}
 	if err := transport.ReadJSON(r, &req); err != nil {
 		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid json")
@@ -198,26 +257,62 @@ func (h *Handler) createPost(w http.ResponseWriter, r *http.Request) {
 		transport.WriteError(w, http.StatusBadRequest, 2001, "missing fields")
 		return
 	}
-	if _, ok := h.Store.GetBoard(req.BoardID); !ok {
+	board, ok := h.Store.GetBoard(req.BoardID)
+	if !ok {
 		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid board_id")
 		return
 	}
+	if !board.Writable() {
+		transport.WriteError(w, http.StatusForbidden, 2003, "board read-only")
+		return
+	}
+	attachmentIDs, ok := h.validateAttachments(user.ID, req.Attachments)
+	if !ok {
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid attachments")
+		return
+	}
+	verdict, ok := h.screen(w, &req.Title, &req.Content)
+	if !ok {
+		return
+	}
+	spamText := req.Title + "\n" + req.Content
+	spamVerdict, ok := h.checkSpam(w, user, spamText)
+	if !ok {
+		return
+	}
 
-	post := h.Store.CreatePost(req.BoardID, user.ID, req.Title, req.Content)
+	post, err := h.Store.CreatePost(req.BoardID, user.ID, req.Title, req.Content, attachmentIDs)
+	if err != nil {
+		h.writeCreateError(w, err)
+		return
+	}
+	spamDetector.Record(spam.Submission{UserID: user.ID, Text: spamText})
+	pending := verdict.Action == contentfilter.ActionReview || spamVerdict.Action == spam.ActionFlag
+	if pending {
+		if err := h.Store.SetPostHidden(post.ID, true); err != nil {
+			transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+			return
+		}
+		h.queueForReview("post", post.ID, verdict, spamVerdict)
+	}
 	resp := struct {
-		ID        string `json:"id"`
-		BoardID   string `json:"board_id"`
-		AuthorID  string `json:"author_id"`
-		Title     string `json:"title"`
-		Content   string `json:"content"`
-		CreatedAt string `json:"created_at"`
+		ID            string           `json:"id"`
+		BoardID       string           `json:"board_id"`
+		AuthorID      string           `json:"author_id"`
+		Title         string           `json:"title"`
+		Content       string           `json:"content"`
+		Attachments   []attachmentItem `json:"attachments"`
+		PendingReview bool             `json:"pending_review"`
+		CreatedAt     string           `json:"created_at"`
 	}{
-		ID:        post.ID,
-		BoardID:   post.BoardID,
-		AuthorID:  post.AuthorID,
-		Title:     post.Title,
-		Content:   post.Content,
-		CreatedAt: post.CreatedAt,
+		ID:            post.ID,
+		BoardID:       post.BoardID,
+		AuthorID:      post.AuthorID,
+		Title:         post.Title,
+		Content:       post.Content,
+		Attachments:   h.attachments(post.ID, ""),
+		PendingReview: pending,
+		CreatedAt:     post.CreatedAt,
 	}
 
 	transport.WriteJSON(w, http.StatusOK, resp)
@@ -231,6 +326,7 @@ func (h *Handler) listComments(w http.ResponseWriter, r *http.Request, postID st
 
 	viewerID := h.viewerID(r)
 	comments := h.Store.Comments(postID)
+	files := h.Store.CommentAttachments(postID)
 	items := make([]commentItem, 0, len(comments))
 	for _, comment := range comments {
 		author, _ := h.Store.GetUser(comment.AuthorID)
@@ -251,10 +347,11 @@ func (h *Handler) listComments(w http.ResponseWriter, r *http.Request, postID st
 				ID:       author.ID,
 				Nickname: author.Nickname,
 			},
-			Content:   comment.Content,
-			CreatedAt: comment.CreatedAt,
-			Score:     score,
-			MyVote:    myVote,
+			Content:     comment.Content,
+			Attachments: attachmentItems(files[comment.ID]),
+			CreatedAt:   comment.CreatedAt,
+			Score:       score,
+			MyVote:      myVote,
 		})
 	}
 
@@ -270,14 +367,14 @@ func (h *Handler) createComment(w http.ResponseWriter, r *http.Request, postID s
 		transport.WriteError(w, http.StatusTooManyRequests, 1005, "rate limited")
 		return
 	}
-	if _, ok := h.Store.GetPost(postID); !ok {
-		transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+	if _, ok := h.requireWritablePost(w, postID); !ok {
 		return
 	}
 
 	var req struct {
-		Content  string `json:"content"`
-		ParentID string `json:"parent_id"`
+		Content     string   `json:"content"`
+		ParentID    string   `json:"parent_id"`
+		Attachments []string `json:"attachments"`
 	}
 	if err := transport.ReadJSON(r, &req); err != nil {
 		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid json")
@@ -294,31 +391,61 @@ func (h *Handler) createComment(w http.ResponseWriter, r *http.Request, postID s
 			return
 		}
 	}
+	attachmentIDs, ok := h.validateAttachments(user.ID, req.Attachments)
+	if !ok {
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid attachments")
+		return
+	}
+	verdict, ok := h.screen(w, &req.Content)
+	if !ok {
+		return
+	}
+	spamVerdict, ok := h.checkSpam(w, user, req.Content)
+	if !ok {
+		return
+	}
 
-	comment := h.Store.CreateComment(postID, user.ID, req.Content, parentIDValue)
+	comment, err := h.Store.CreateComment(postID, user.ID, req.Content, parentIDValue, attachmentIDs)
+	if err != nil {
+		h.writeCreateError(w, err)
+		return
+	}
+	spamDetector.Record(spam.Submission{UserID: user.ID, Text: req.Content})
+	pending := verdict.Action == contentfilter.ActionReview || spamVerdict.Action == spam.ActionFlag
+	if pending {
+		if err := h.Store.SetCommentHidden(postID, comment.ID, true); err != nil {
+			transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+			return
+		}
+		h.queueForReview("comment", comment.ID, verdict, spamVerdict)
+	}
 	var parentID *string
 	if strings.TrimSpace(comment.ParentID) != "" {
 		value := comment.ParentID
 		parentID = &value
 	}
 	resp := struct {
-		ID        string  `json:"id"`
-		PostID    string  `json:"post_id"`
-		ParentID  *string `json:"parent_id"`
-		AuthorID  string  `json:"author_id"`
-		Content   string  `json:"content"`
-		CreatedAt string  `json:"created_at"`
-		Score     int     `json:"score"`
-		MyVote    int     `json:"my_vote"`
+		ID            string           `json:"id"`
+		PostID        string           `json:"post_id"`
+		ParentID      *string          `json:"parent_id"`
+		AuthorID      string           `json:"author_id"`
+		Content       string           `json:"content"`
+		Attachments   []attachmentItem `json:"attachments"`
+		CreatedAt     string           `json:"created_at"`
+		Score         int              `json:"score"`
+		MyVote        int              `json:"my_vote"`
+		PendingReview bool             `json:"pending_review"`
 	}{
-		ID:        comment.ID,
-		PostID:    comment.PostID,
-		ParentID:  parentID,
-		AuthorID:  comment.AuthorID,
-		Content:   comment.Content,
-		CreatedAt: comment.CreatedAt,
-		Score:     0,
-		MyVote:    0,
+		ID:            comment.ID,
+		PostID:        comment.PostID,
+		ParentID:      parentID,
+		AuthorID:      comment.AuthorID,
+		Content:       comment.Content,
+		Attachments:   h.attachments(postID, comment.ID),
+		CreatedAt:     comment.CreatedAt,
+		Score:         0,
+		MyVote:        0,
+		PendingReview: pending,
 	}
 
 	transport.WriteJSON(w, http.StatusOK, resp)
@@ -336,14 +463,27 @@ func (h *Handler) getPost(w http.ResponseWriter, r *http.Request, postID string)
 		transport.WriteError(w, http.StatusNotFound, 2001, "not found")
 		return
 	}
+	viewerID := h.viewerID(r)
+	if post.HiddenAt != "" && !h.canSeeHidden(viewerID, post) {
+		// Auto-hidden posts stay visible to their author and the board's moderators only.
+		transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+		return
+	}
 
 	board, _ := h.Store.GetBoard(post.BoardID)
 	author, _ := h.Store.GetUser(post.AuthorID)
 	score := h.Store.PostScore(post.ID)
 	commentCount := h.Store.CommentCount(post.ID)
 	myVote := 0
-	if viewerID := h.viewerID(r); viewerID != "" {
+	myBookmarked := false
+	if viewerID != "" {
 		myVote = h.Store.PostVote(post.ID, viewerID)
+		myBookmarked = h.Store.PostBookmarked(post.ID, viewerID)
+	}
+
+	chatOnline := 0
+	if h.ChatOnline != nil {
+		chatOnline = h.ChatOnline(post.ID)
 	}
 
 	var deletedAt *string
@@ -353,21 +493,30 @@ func (h *Handler) getPost(w http.ResponseWriter, r *http.Request, postID string)
 	}
 
 	resp := struct {
-		ID           string `json:"id"`
-		Board        any    `json:"board"`
-		Author       any    `json:"author"`
-		Title        string `json:"title"`
-		Content      string `json:"content"`
-		Score        int    `json:"score"`
-		MyVote       int    `json:"my_vote"`
-		CommentCount int    `json:"comment_count"`
-		CreatedAt    string `json:"created_at"`
-		DeletedAt    any    `json:"deleted_at"`
+		ID           string           `json:"id"`
+		Board        any              `json:"board"`
+		Author       any              `json:"author"`
+		Title        string           `json:"title"`
+		Content      string           `json:"content"`
+		Attachments  []attachmentItem `json:"attachments"`
+		Score        int              `json:"score"`
+		MyVote       int              `json:"my_vote"`
+		MyBookmarked bool             `json:"my_bookmarked"`
+		CommentCount int              `json:"comment_count"`
+		Pinned       bool             `json:"pinned"`
+		Locked       bool             `json:"locked"`
+		Hidden       bool             `json:"hidden"`
+		ChatRoomID   string           `json:"chat_room_id"`
+		ChatOnline   int              `json:"chat_online"`
+		CreatedAt    string           `json:"created_at"`
+		DeletedAt    any              `json:"deleted_at"`
 	}{
 		ID: post.ID,
-		Board: map[string]any{
-			"id":   board.ID,
-			"name": board.Name,
+		Board: boardSummary{
+			ID:       board.ID,
+			Name:     board.Name,
+			Archived: board.Archived,
+			ReadOnly: board.ReadOnly,
 		},
 		Author: map[string]any{
 			"id":       author.ID,
@@ -375,9 +524,16 @@ func (h *Handler) getPost(w http.ResponseWriter, r *http.Request, postID string)
 		},
 		Title:        post.Title,
 		Content:      post.Content,
+		Attachments:  h.attachments(post.ID, ""),
 		Score:        score,
 		MyVote:       myVote,
+		MyBookmarked: myBookmarked,
 		CommentCount: commentCount,
+		Pinned:       post.PinnedAt != "",
+		Locked:       post.LockedAt != "",
+		Hidden:       post.HiddenAt != "",
+		ChatRoomID:   store.PostRoomID(post.ID),
+		ChatOnline:   chatOnline,
 		CreatedAt:    post.CreatedAt,
 		DeletedAt:    deletedAt,
 	}
@@ -402,6 +558,9 @@ func (h *Handler) deletePost(w http.ResponseWriter, r *http.Request, postID stri
 		}
 		return
 	}
+	if h.PostChanged != nil {
+		h.PostChanged(postID)
+	}
 
 	transport.WriteJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
 }
@@ -432,6 +591,9 @@ func (h *Handler) votePost(w http.ResponseWriter, r *http.Request, postID string
 	if !ok {
 		return
 	}
+	if _, ok := h.requireWritablePost(w, postID); !ok {
+		return
+	}
 
 	var req struct {
 		Value int `json:"value"`
@@ -471,6 +633,9 @@ func (h *Handler) clearVote(w http.ResponseWriter, r *http.Request, postID strin
 	if !ok {
 		return
 	}
+	if _, ok := h.requireWritablePost(w, postID); !ok {
+		return
+	}
 
 	score, myVote, err := h.Store.ClearPostVote(postID, user.ID)
 	if err != nil {
@@ -498,6 +663,9 @@ func (h *Handler) voteComment(w http.ResponseWriter, r *http.Request, postID, co
 	if !ok {
 		return
 	}
+	if _, ok := h.requireWritablePost(w, postID); !ok {
+		return
+	}
 
 	var req struct {
 		Value int `json:"value"`
@@ -537,6 +705,9 @@ func (h *Handler) clearCommentVote(w http.ResponseWriter, r *http.Request, postI
 	if !ok {
 		return
 	}
+	if _, ok := h.requireWritablePost(w, postID); !ok {
+		return
+	}
 
 	score, myVote, err := h.Store.ClearCommentVote(postID, commentID, user.ID)
 	if err != nil {
@@ -559,8 +730,27 @@ func (h *Handler) clearCommentVote(w http.ResponseWriter, r *http.Request, postI
 	transport.WriteJSON(w, http.StatusOK, resp)
 }
 
+// requireWritablePost loads a live post and rejects interaction with it when the post is
+// locked (2002) or its board is read-only/archived (2003).
+func (h *Handler) requireWritablePost(w http.ResponseWriter, postID string) (store.Post, bool) {
+	post, ok := h.Store.GetPost(postID)
+	if !ok {
+		transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+		return store.Post{}, false
+	}
+	if post.LockedAt != "" {
+		transport.WriteError(w, http.StatusForbidden, 2002, "post locked")
+		return store.Post{}, false
+	}
+	if board, ok := h.Store.GetBoard(post.BoardID); ok && !board.Writable() {
+		transport.WriteError(w, http.StatusForbidden, 2003, "board read-only")
+		return store.Post{}, false
+	}
+	return post, true
+}
+
 func (h *Handler) allowWrite(limiter *ratelimit.FixedWindow, r *http.Request, userID string) bool {
-	ip := clientIP(r)
+	ip := transport.ClientIP(r)
 	if ip != "" && !limiter.Allow("ip:"+ip) {
 		return false
 	}
@@ -570,53 +760,59 @@ func (h *Handler) allowWrite(limiter *ratelimit.FixedWindow, r *http.Request, us
 	return true
 }
 
-func clientIP(r *http.Request) string {
-	forwarded := strings.TrimSpace(r.Header.Get("X-Forwarded-For"))
-	if forwarded != "" {
-		first := strings.TrimSpace(strings.Split(forwarded, ",")[0])
-		if addr, err := netip.ParseAddr(first); err == nil {
-			return addr.String()
-		}
-	}
-
-	hostport := strings.TrimSpace(r.RemoteAddr)
-	if hostport == "" {
-		return ""
-	}
-	if addrPort, err := netip.ParseAddrPort(hostport); err == nil {
-		return addrPort.Addr().String()
-	}
-	if addr, err := netip.ParseAddr(hostport); err == nil {
-		return addr.String()
-	}
-	return ""
+type postItem struct {
+	ID           string           `json:"id"`
+	Title        string           `json:"title"`
+	Content      string           `json:"content"`
+	Score        int              `json:"score"`
+	CommentCount int              `json:"comment_count"`
+	MyVote       int              `json:"my_vote"`
+	MyBookmarked bool             `json:"my_bookmarked"`
+	Pinned       bool             `json:"pinned"`
+	Locked       bool             `json:"locked"`
+	Author       userSummary      `json:"author"`
+	Board        *boardSummary    `json:"board,omitempty"`
+	Attachments  []attachmentItem `json:"attachments"`
+	CreatedAt    string           `json:"created_at"`
 }
 
-type postItem struct {
-	ID           string        `json:"id"`
-	Title        string        `json:"title"`
-	Content      string        `json:"content"`
-	Score        int           `json:"score"`
-	CommentCount int           `json:"comment_count"`
-	MyVote       int           `json:"my_vote"`
-	Author       userSummary   `json:"author"`
-	Board        *boardSummary `json:"board,omitempty"`
-	CreatedAt    string        `json:"created_at"`
+type boardItem struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	Icon            string `json:"icon"`
+	Rules           string `json:"rules"`
+	QQGroup         string `json:"qq_group"`
+	Archived        bool   `json:"archived"`
+	ReadOnly        bool   `json:"read_only"`
+	SubscriberCount int    `json:"subscriber_count"`
+	Subscribed      bool   `json:"subscribed"`
 }
 
 type boardSummary struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Archived bool   `json:"archived"`
+	ReadOnly bool   `json:"read_only"`
 }
 
 type commentItem struct {
-	ID        string      `json:"id"`
-	ParentID  *string     `json:"parent_id"`
-	Author    userSummary `json:"author"`
-	Content   string      `json:"content"`
-	CreatedAt string      `json:"created_at"`
-	Score     int         `json:"score"`
-	MyVote    int         `json:"my_vote"`
+	ID          string           `json:"id"`
+	ParentID    *string          `json:"parent_id"`
+	Author      userSummary      `json:"author"`
+	Content     string           `json:"content"`
+	Attachments []attachmentItem `json:"attachments"`
+	CreatedAt   string           `json:"created_at"`
+	Score       int              `json:"score"`
+	MyVote      int              `json:"my_vote"`
+}
+
+type attachmentItem struct {
+	ID          string `json:"id"`
+	Filename    string `json:"filename"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type"`
+	URL         string `json:"url"`
 }
 
 type userSummary struct {
@@ -624,6 +820,62 @@ type userSummary struct {
 	Nickname string `json:"nickname"`
 }
 
+// maxAttachments caps how many files a single post or comment may carry.
+const maxAttachments = 9
+
+// validateAttachments trims and de-duplicates file IDs and checks that each file
+// was uploaded by the author and is not attached elsewhere yet.
+func (h *Handler) validateAttachments(authorID string, fileIDs []string) ([]string, bool) {
+	ids := make([]string, 0, len(fileIDs))
+	seen := map[string]bool{}
+	for _, raw := range fileIDs {
+		id := strings.TrimSpace(raw)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		file, ok := h.Store.GetFile(id)
+		if !ok || file.UploaderID != authorID || file.PostID != "" {
+			return nil, false
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) > maxAttachments {
+		return nil, false
+	}
+	return ids, true
+}
+
+// writeCreateError reports a failed CreatePost/CreateComment. The store refuses attachments that
+// were attached elsewhere after validateAttachments ran, which is the same client error.
+func (h *Handler) writeCreateError(w http.ResponseWriter, err error) {
+	switch err {
+	case store.ErrForbidden:
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid attachments")
+	default:
+		transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+	}
+}
+
+// attachments loads attachment summaries for a post (commentID empty) or a comment.
+func (h *Handler) attachments(postID, commentID string) []attachmentItem {
+	return attachmentItems(h.Store.Attachments(postID, commentID))
+}
+
+func attachmentItems(files []store.FileMeta) []attachmentItem {
+	items := make([]attachmentItem, 0, len(files))
+	for _, file := range files {
+		items = append(items, attachmentItem{
+			ID:          file.ID,
+			Filename:    file.Filename,
+			Size:        file.Size,
+			ContentType: file.ContentType,
+			URL:         "/files/" + file.ID,
+		})
+	}
+	return items
+}
+
 // parsePositiveInt parses a positive int and falls back when the input is empty or invalid.
 func parsePositiveInt(value string, fallback int) int {
 	if value == "" {
@@ -636,12 +888,68 @@ func parsePositiveInt(value string, fallback int) int {
 	return parsed
 }
 
+// screen runs the given fields through the content filter, replacing them with their masked
+// text. It writes a 400 (code 2005) and returns false when any field is rejected; the returned
+// verdict carries the most severe action and all matched categories.
+func (h *Handler) screen(w http.ResponseWriter, fields ...*string) (contentfilter.Result, bool) {
+	var verdict contentfilter.Result
+	if h.Filter == nil {
+		return verdict, true
+	}
+	for _, field := range fields {
+		result := h.Filter.Check(*field)
+		*field = result.Text
+		verdict = contentfilter.Merge(verdict, result)
+	}
+	if verdict.Action == contentfilter.ActionReject {
+		transport.WriteError(w, http.StatusBadRequest, 2005, "content rejected")
+		return verdict, false
+	}
+	return verdict, true
+}
+
+// checkSpam runs the duplicate/link heuristics. It writes a 400 (code 2006) and returns false
+// when the submission is rejected. Callers record the text with spamDetector.Record once it is stored.
+func (h *Handler) checkSpam(w http.ResponseWriter, user store.User, text string) (spam.Verdict, bool) {
+	created, _ := time.Parse(time.RFC3339, user.CreatedAt)
+	verdict := spamDetector.Check(spam.Submission{UserID: user.ID, AccountCreated: created, Text: text})
+	if verdict.Action == spam.ActionReject {
+		transport.WriteError(w, http.StatusBadRequest, 2006, verdict.Reason)
+		return verdict, false
+	}
+	return verdict, true
+}
+
+// queueForReview files a system report so the held-back content shows up in the moderators'
+// report queue; dismissing the report makes it visible again.
+func (h *Handler) queueForReview(targetType, targetID string, verdict contentfilter.Result, spamVerdict spam.Verdict) {
+	reason, detail := "content_filter", "matched categories: "+strings.Join(verdict.Categories, ", ")
+	if verdict.Action != contentfilter.ActionReview {
+		reason, detail = "spam", spamVerdict.Reason
+	}
+	if _, err := h.Store.CreateReport("system", targetType, targetID, reason, detail); err != nil {
+		log.Printf("queue %s %s for review: %v", targetType, targetID, err)
+	}
+}
+
+// canSeeHidden reports whether the viewer may open an auto-hidden post.
+func (h *Handler) canSeeHidden(viewerID string, post store.Post) bool {
+	if viewerID == "" {
+		return false
+	}
+	if viewerID == post.AuthorID {
+		return true
+	}
+	viewer, ok := h.Store.GetUser(viewerID)
+	return ok && h.Auth.CanModerate(viewer, post.BoardID)
+}
+
 func (h *Handler) viewerID(r *http.Request) string {
 	token := bearerToken(r)
 	if token == "" {
 		return ""
 	}
-	user, ok := h.Store.UserByToken(token)
+	user, ok := h.Auth.UserByToken(r, token)
 	if !ok {
 		return ""
 	}
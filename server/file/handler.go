@@ -3,6 +3,8 @@ package file
 import (
 	"fmt"
 	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -65,21 +67,26 @@ func (h *Handler) Upload(w http.ResponseWriter, r *http.Request) {
 	}
 	defer dst.Close()
 
-	if _, err := io.Copy(dst, file); err != nil {
+	size, err := io.Copy(dst, file)
+	if err != nil {
 		transport.WriteError(w, http.StatusInternalServerError, 5000, "failed to write file")
 		return
 	}
 
-	meta := h.Store.SaveFile(user.ID, filename, storageKey, storagePath)
+	meta := h.Store.SaveFile(user.ID, filename, storageKey, storagePath, detectContentType(header), size)
 
 	resp := struct {
-		ID       string `json:"id"`
-		Filename string `json:"filename"`
-		URL      string `json:"url"`
+		ID          string `json:"id"`
+		Filename    string `json:"filename"`
+		Size        int64  `json:"size"`
+		ContentType string `json:"content_type"`
+		URL         string `json:"url"`
 	}{
-		ID:       meta.ID,
-		Filename: meta.Filename,
-		URL:      "/files/" + meta.ID,
+		ID:          meta.ID,
+		Filename:    meta.Filename,
+		Size:        meta.Size,
+		ContentType: meta.ContentType,
+		URL:         "/files/" + meta.ID,
 	}
 
 	transport.WriteJSON(w, http.StatusOK, resp)
@@ -108,6 +115,18 @@ func (h *Handler) Download(fileID string) http.HandlerFunc {
 	}
 }
 
+// detectContentType prefers the part's declared type and falls back to the file extension.
+func detectContentType(header *multipart.FileHeader) string {
+	contentType := strings.TrimSpace(header.Header.Get("Content-Type"))
+	if contentType != "" && contentType != "application/octet-stream" {
+		return contentType
+	}
+	if byExt := mime.TypeByExtension(filepath.Ext(header.Filename)); byExt != "" {
+		return byExt
+	}
+	return "application/octet-stream"
+}
+
 // sanitizeFilename strips directory components and trims whitespace to prevent path traversal.
 func sanitizeFilename(name string) string {
 	cleaned := strings.ReplaceAll(name, "\\", "/")
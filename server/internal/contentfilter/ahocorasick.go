@@ -0,0 +1,87 @@
+package contentfilter
+
+import "unicode"
+
+// matcher is an Aho-Corasick automaton over lower-cased runes.
+type matcher struct {
+	nodes []node
+	words []Word
+}
+
+type node struct {
+	next map[rune]int
+	fail int
+	// out lists indexes into matcher.words that end at this node (including via fail links).
+	out []int
+}
+
+// hit is a word occurrence as rune offsets [start, end).
+type hit struct {
+	word       int
+	start, end int
+}
+
+func newMatcher(words []Word) *matcher {
+	m := &matcher{nodes: []node{{next: map[rune]int{}}}, words: words}
+	for i, word := range words {
+		cur := 0
+		for _, r := range []rune(word.Word) {
+			r = unicode.ToLower(r)
+			child, ok := m.nodes[cur].next[r]
+			if !ok {
+				child = len(m.nodes)
+				m.nodes = append(m.nodes, node{next: map[rune]int{}})
+				m.nodes[cur].next[r] = child
+			}
+			cur = child
+		}
+		m.nodes[cur].out = append(m.nodes[cur].out, i)
+	}
+
+	// Breadth-first pass to wire failure links; children of the root fail back to the root.
+	queue := make([]int, 0, len(m.nodes))
+	for _, child := range m.nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for r, child := range m.nodes[cur].next {
+			fail := m.nodes[cur].fail
+			for fail != 0 {
+				if _, ok := m.nodes[fail].next[r]; ok {
+					break
+				}
+				fail = m.nodes[fail].fail
+			}
+			if target, ok := m.nodes[fail].next[r]; ok && target != child {
+				fail = target
+			}
+			m.nodes[child].fail = fail
+			m.nodes[child].out = append(m.nodes[child].out, m.nodes[fail].out...)
+			queue = append(queue, child)
+		}
+	}
+	return m
+}
+
+// find returns every occurrence of every word in text, overlapping matches included.
+func (m *matcher) find(text []rune) []hit {
+	var hits []hit
+	cur := 0
+	for i, r := range text {
+		r = unicode.ToLower(r)
+		for cur != 0 {
+			if _, ok := m.nodes[cur].next[r]; ok {
+				break
+			}
+			cur = m.nodes[cur].fail
+		}
+		cur = m.nodes[cur].next[r] // a missing transition from the root yields 0, the root
+		for _, idx := range m.nodes[cur].out {
+			length := len([]rune(m.words[idx].Word))
+			hits = append(hits, hit{word: idx, start: i + 1 - length, end: i + 1})
+		}
+	}
+	return hits
+}
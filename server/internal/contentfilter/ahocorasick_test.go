@@ -0,0 +1,79 @@
+package contentfilter
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestMatcherFind(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []string
+		text  string
+		want  []string // "word@start-end" in rune offsets
+	}{
+		{
+			name:  "no match",
+			words: []string{"spam"},
+			text:  "hello world",
+			want:  nil,
+		},
+		{
+			name:  "overlapping words share suffixes through failure links",
+			words: []string{"he", "she", "his", "hers"},
+			text:  "ushers",
+			want:  []string{"he@2-4", "hers@2-6", "she@1-4"},
+		},
+		{
+			name:  "failure link falls back to a shorter prefix",
+			words: []string{"abcd", "bcx"},
+			text:  "abcx",
+			want:  []string{"bcx@1-4"},
+		},
+		{
+			name:  "nested word inside a longer one",
+			words: []string{"abc", "b"},
+			text:  "zabcz",
+			want:  []string{"abc@1-4", "b@2-3"},
+		},
+		{
+			name:  "repeated and adjacent occurrences",
+			words: []string{"aa"},
+			text:  "aaaa",
+			want:  []string{"aa@0-2", "aa@1-3", "aa@2-4"},
+		},
+		{
+			name:  "case folding on both sides",
+			words: []string{"SpAm"},
+			text:  "SPAM and spam",
+			want:  []string{"SpAm@0-4", "SpAm@9-13"},
+		},
+		{
+			name:  "CJK offsets count runes not bytes",
+			words: []string{"广告", "代写"},
+			text:  "这是广告，代写论文",
+			want:  []string{"代写@5-7", "广告@2-4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words := make([]Word, 0, len(tt.words))
+			for _, w := range tt.words {
+				words = append(words, Word{Word: w})
+			}
+			m := newMatcher(words)
+
+			var got []string
+			for _, h := range m.find([]rune(tt.text)) {
+				got = append(got, fmt.Sprintf("%s@%d-%d", m.words[h.word].Word, h.start, h.end))
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("find(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
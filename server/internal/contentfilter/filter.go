@@ -0,0 +1,173 @@
+// Package contentfilter checks user-generated text against an admin-managed list of
+// sensitive words grouped into categories, each with its own action.
+package contentfilter
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Versifine/Cumt-cumpus-hub/server/store"
+)
+
+// Actions a category can be configured with, from least to most severe.
+const (
+	ActionMask   = "mask"   // replace the matched word with asterisks and accept the text
+	ActionReview = "review" // accept the text but queue it for moderator review
+	ActionReject = "reject" // refuse the text outright
+)
+
+// DefaultAction applies to categories without a configured action.
+const DefaultAction = ActionMask
+
+// ValidAction reports whether action is one of the supported actions.
+func ValidAction(action string) bool {
+	return severity(action) > 0
+}
+
+func severity(action string) int {
+	switch action {
+	case ActionMask:
+		return 1
+	case ActionReview:
+		return 2
+	case ActionReject:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Word is a sensitive word and the category it belongs to.
+type Word struct {
+	Word     string
+	Category string
+}
+
+// Result describes what the filter decided for a piece of text.
+type Result struct {
+	// Action is the most severe action among matched categories, or "" if nothing matched.
+	Action string
+	// Categories lists the matched categories, sorted.
+	Categories []string
+	// Text is the input with every word from a mask category replaced by asterisks.
+	Text string
+}
+
+// Matched reports whether any word matched.
+func (r Result) Matched() bool {
+	return r.Action != ""
+}
+
+// Merge combines the verdicts of several fields checked separately (e.g. title and content);
+// the Text of the result is that of b.
+func Merge(a, b Result) Result {
+	merged := Result{Action: a.Action, Text: b.Text}
+	if severity(b.Action) > severity(merged.Action) {
+		merged.Action = b.Action
+	}
+	seen := map[string]bool{}
+	for _, category := range append(append([]string{}, a.Categories...), b.Categories...) {
+		if !seen[category] {
+			seen[category] = true
+			merged.Categories = append(merged.Categories, category)
+		}
+	}
+	sort.Strings(merged.Categories)
+	return merged
+}
+
+// Filter is safe for concurrent use; Load swaps the word list atomically so it can be
+// reloaded while requests are being checked.
+type Filter struct {
+	mu      sync.RWMutex
+	matcher *matcher
+	actions map[string]string
+}
+
+// New returns an empty filter that matches nothing.
+func New() *Filter {
+	return &Filter{matcher: newMatcher(nil), actions: map[string]string{}}
+}
+
+// Load replaces the word list and the per-category actions.
+func (f *Filter) Load(words []Word, actions map[string]string) {
+	cleaned := make([]Word, 0, len(words))
+	for _, word := range words {
+		word.Word = strings.TrimSpace(word.Word)
+		if word.Word != "" {
+			cleaned = append(cleaned, word)
+		}
+	}
+	copied := make(map[string]string, len(actions))
+	for category, action := range actions {
+		copied[category] = action
+	}
+
+	m := newMatcher(cleaned)
+	f.mu.Lock()
+	f.matcher = m
+	f.actions = copied
+	f.mu.Unlock()
+}
+
+// Source supplies the persisted word list; store.API satisfies it.
+type Source interface {
+	FilterWords() []store.FilterWord
+	FilterCategories() []store.FilterCategory
+}
+
+// Reload loads the current word list and category actions from src.
+func (f *Filter) Reload(src Source) {
+	var words []Word
+	for _, word := range src.FilterWords() {
+		words = append(words, Word{Word: word.Word, Category: word.Category})
+	}
+	actions := map[string]string{}
+	for _, category := range src.FilterCategories() {
+		actions[category.Name] = category.Action
+	}
+	f.Load(words, actions)
+}
+
+// Check runs text through the filter.
+func (f *Filter) Check(text string) Result {
+	f.mu.RLock()
+	m, actions := f.matcher, f.actions
+	f.mu.RUnlock()
+
+	runes := []rune(text)
+	hits := m.find(runes)
+	result := Result{Text: text}
+	if len(hits) == 0 {
+		return result
+	}
+
+	categories := map[string]bool{}
+	masked := false
+	for _, h := range hits {
+		category := m.words[h.word].Category
+		action := actions[category]
+		if !ValidAction(action) {
+			action = DefaultAction
+		}
+		categories[category] = true
+		if severity(action) > severity(result.Action) {
+			result.Action = action
+		}
+		if action == ActionMask {
+			for i := h.start; i < h.end; i++ {
+				runes[i] = '*'
+			}
+			masked = true
+		}
+	}
+	if masked {
+		result.Text = string(runes)
+	}
+	for category := range categories {
+		result.Categories = append(result.Categories, category)
+	}
+	sort.Strings(result.Categories)
+	return result
+}
@@ -0,0 +1,148 @@
+package contentfilter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Versifine/Cumt-cumpus-hub/server/store"
+)
+
+func TestFilterCheck(t *testing.T) {
+	words := []Word{
+		{Word: "ab", Category: "mask"},
+		{Word: "abc", Category: "mask"},
+		{Word: "广告", Category: "mask"},
+		{Word: "Spam", Category: "mask"},
+		{Word: "review me", Category: "review"},
+		{Word: "forbidden", Category: "reject"},
+		{Word: "oops", Category: "unconfigured"},
+	}
+	actions := map[string]string{
+		"mask":   ActionMask,
+		"review": ActionReview,
+		"reject": ActionReject,
+	}
+
+	tests := []struct {
+		name       string
+		text       string
+		action     string
+		categories []string
+		masked     string
+	}{
+		{
+			name:   "clean text is untouched",
+			text:   "hello",
+			action: "",
+			masked: "hello",
+		},
+		{
+			name:       "overlapping mask words mask the longest span",
+			text:       "xabcd",
+			action:     ActionMask,
+			categories: []string{"mask"},
+			masked:     "x***d",
+		},
+		{
+			name:       "CJK words are masked per rune",
+			text:       "这是广告！",
+			action:     ActionMask,
+			categories: []string{"mask"},
+			masked:     "这是**！",
+		},
+		{
+			name:       "case-insensitive match keeps the rest of the text",
+			text:       "no SPAM here",
+			action:     ActionMask,
+			categories: []string{"mask"},
+			masked:     "no **** here",
+		},
+		{
+			name:       "review outranks mask and only mask words are hidden",
+			text:       "ab, review me",
+			action:     ActionReview,
+			categories: []string{"mask", "review"},
+			masked:     "**, review me",
+		},
+		{
+			name:       "reject outranks everything",
+			text:       "forbidden ab review me",
+			action:     ActionReject,
+			categories: []string{"mask", "reject", "review"},
+			masked:     "forbidden ** review me",
+		},
+		{
+			name:       "categories without an action fall back to the default",
+			text:       "oops",
+			action:     DefaultAction,
+			categories: []string{"unconfigured"},
+			masked:     "****",
+		},
+	}
+
+	f := New()
+	f.Load(words, actions)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := f.Check(tt.text)
+			if got.Action != tt.action {
+				t.Errorf("Action = %q, want %q", got.Action, tt.action)
+			}
+			if !reflect.DeepEqual(got.Categories, tt.categories) {
+				t.Errorf("Categories = %v, want %v", got.Categories, tt.categories)
+			}
+			if got.Text != tt.masked {
+				t.Errorf("Text = %q, want %q", got.Text, tt.masked)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	title := Result{Action: ActionMask, Categories: []string{"b", "a"}, Text: "title"}
+	content := Result{Action: ActionReview, Categories: []string{"a", "c"}, Text: "content"}
+
+	got := Merge(title, content)
+	want := Result{Action: ActionReview, Categories: []string{"a", "b", "c"}, Text: "content"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Merge = %+v, want %+v", got, want)
+	}
+	if got := Merge(Result{Text: "x"}, Result{Text: "y"}); got.Matched() {
+		t.Fatalf("Merge of clean results matched: %+v", got)
+	}
+}
+
+type fakeSource struct {
+	words      []store.FilterWord
+	categories []store.FilterCategory
+}
+
+func (s fakeSource) FilterWords() []store.FilterWord          { return s.words }
+func (s fakeSource) FilterCategories() []store.FilterCategory { return s.categories }
+
+func TestFilterReload(t *testing.T) {
+	f := New()
+	if got := f.Check("anything"); got.Matched() {
+		t.Fatalf("empty filter matched: %+v", got)
+	}
+
+	f.Reload(fakeSource{
+		words:      []store.FilterWord{{Word: " old ", Category: "ads"}, {Word: "  "}},
+		categories: []store.FilterCategory{{Name: "ads", Action: ActionReject}},
+	})
+	if got := f.Check("an old post"); got.Action != ActionReject {
+		t.Fatalf("after first reload Action = %q, want %q", got.Action, ActionReject)
+	}
+
+	// A reload replaces both the words and the category actions.
+	f.Reload(fakeSource{
+		words:      []store.FilterWord{{Word: "new", Category: "ads"}},
+		categories: []store.FilterCategory{{Name: "ads", Action: ActionMask}},
+	})
+	if got := f.Check("an old post"); got.Matched() {
+		t.Fatalf("removed word still matched: %+v", got)
+	}
+	if got := f.Check("brand new"); got.Action != ActionMask || got.Text != "brand ***" {
+		t.Fatalf("after second reload got %+v, want masked %q", got, "brand ***")
+	}
+}
@@ -27,6 +27,12 @@ func NewFixedWindow(window time.Duration, limit int) *FixedWindow {
 }
 
 func (l *FixedWindow) Allow(key string) bool {
+	ok, _ := l.Take(key)
+	return ok
+}
+
+// Take is Allow that also says, when the key is over its limit, how long until the window resets.
+func (l *FixedWindow) Take(key string) (bool, time.Duration) {
 	now := time.Now()
 
 	l.mu.Lock()
@@ -38,9 +44,9 @@ func (l *FixedWindow) Allow(key string) bool {
 	}
 	if item.count >= l.limit {
 		l.items[key] = item
-		return false
+		return false, item.resetTime.Sub(now)
 	}
 	item.count++
 	l.items[key] = item
-	return true
+	return true, 0
 }
@@ -0,0 +1,236 @@
+// Package spam flags near-duplicate posts/comments and link-heavy content from new accounts.
+//
+// Like ratelimit, the detector only keeps a sliding window of recent submissions in memory;
+// it is a cheap first line of defence, not a persistent reputation system.
+package spam
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Actions a Verdict can carry.
+const (
+	ActionFlag   = "flag"   // accept but send to moderator review
+	ActionReject = "reject" // refuse the submission
+)
+
+// Config tunes the detector.
+type Config struct {
+	// Window is how long submissions are remembered.
+	Window time.Duration
+	// MaxDistance is the simhash Hamming distance at or below which two texts are near-duplicates.
+	MaxDistance int
+	// MinRunes is the shortest text (letters and digits only) that takes part in duplicate
+	// detection; short replies such as "thanks" repeat naturally.
+	MinRunes int
+	// CrossUserFlag is how many other users must have posted a near-duplicate within Window
+	// before a submission is flagged.
+	CrossUserFlag int
+	// NewAccountAge and NewAccountMaxLinks limit links from freshly registered accounts.
+	NewAccountAge      time.Duration
+	NewAccountMaxLinks int
+	// MaxLinkRepeats is how often one user may post the same link within Window.
+	MaxLinkRepeats int
+}
+
+// DefaultConfig is used by the community handlers.
+var DefaultConfig = Config{
+	Window:             30 * time.Minute,
+	MaxDistance:        8,
+	MinRunes:           12,
+	CrossUserFlag:      2,
+	NewAccountAge:      24 * time.Hour,
+	NewAccountMaxLinks: 1,
+	MaxLinkRepeats:     2,
+}
+
+// Submission is a post or comment about to be stored.
+type Submission struct {
+	UserID         string
+	AccountCreated time.Time
+	Text           string
+}
+
+// Verdict is the detector's decision; Action is "" when the submission looks fine.
+type Verdict struct {
+	Action string
+	Reason string
+}
+
+type entry struct {
+	userID string
+	hash   uint64
+	dedupe bool
+	links  []string
+	at     time.Time
+}
+
+// Detector is safe for concurrent use.
+type Detector struct {
+	mu     sync.Mutex
+	cfg    Config
+	recent []entry
+}
+
+func NewDetector(cfg Config) *Detector {
+	return &Detector{cfg: cfg}
+}
+
+// Check evaluates a submission against the recent ones. It does not remember the submission;
+// call Record once it has actually been stored, so a failed save does not block a retry.
+func (d *Detector) Check(sub Submission) Verdict {
+	now := time.Now()
+	current := d.entry(sub, now)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.prune(now)
+
+	if !sub.AccountCreated.IsZero() && now.Sub(sub.AccountCreated) < d.cfg.NewAccountAge && len(current.links) > d.cfg.NewAccountMaxLinks {
+		return Verdict{Action: ActionReject, Reason: "too many links"}
+	}
+
+	otherUsers := map[string]bool{}
+	linkCounts := map[string]int{}
+	for _, past := range d.recent {
+		if past.userID == sub.UserID {
+			if current.dedupe && past.dedupe && Distance(past.hash, current.hash) <= d.cfg.MaxDistance {
+				return Verdict{Action: ActionReject, Reason: "duplicate content"}
+			}
+			for _, link := range past.links {
+				linkCounts[link]++
+			}
+			continue
+		}
+		if current.dedupe && past.dedupe && Distance(past.hash, current.hash) <= d.cfg.MaxDistance {
+			otherUsers[past.userID] = true
+		}
+	}
+	for _, link := range current.links {
+		if linkCounts[link] >= d.cfg.MaxLinkRepeats {
+			return Verdict{Action: ActionReject, Reason: "repeated link"}
+		}
+	}
+
+	if d.cfg.CrossUserFlag > 0 && len(otherUsers) >= d.cfg.CrossUserFlag {
+		return Verdict{Action: ActionFlag, Reason: "duplicate content across users"}
+	}
+	return Verdict{}
+}
+
+// Record remembers a stored submission for later checks.
+func (d *Detector) Record(sub Submission) {
+	now := time.Now()
+	current := d.entry(sub, now)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.prune(now)
+	d.recent = append(d.recent, current)
+}
+
+func (d *Detector) entry(sub Submission, now time.Time) entry {
+	return entry{
+		userID: sub.UserID,
+		hash:   Simhash(sub.Text),
+		dedupe: len(normalize(sub.Text)) >= d.cfg.MinRunes,
+		links:  Links(sub.Text),
+		at:     now,
+	}
+}
+
+// prune drops entries older than the window; entries are appended in time order.
+func (d *Detector) prune(now time.Time) {
+	cutoff := now.Add(-d.cfg.Window)
+	i := 0
+	for i < len(d.recent) && d.recent[i].at.Before(cutoff) {
+		i++
+	}
+	if i > 0 {
+		d.recent = append(d.recent[:0], d.recent[i:]...)
+	}
+}
+
+// normalize keeps only lower-cased letters and digits so spacing and punctuation tricks
+// do not defeat duplicate detection.
+func normalize(text string) []rune {
+	out := make([]rune, 0, len(text))
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			out = append(out, unicode.ToLower(r))
+		}
+	}
+	return out
+}
+
+const shingle = 3
+
+// Simhash fingerprints text from its 3-rune shingles; similar texts differ in few bits.
+func Simhash(text string) uint64 {
+	runes := normalize(text)
+	if len(runes) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	add := func(feature []rune) {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(string(feature)))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+	if len(runes) < shingle {
+		add(runes)
+	}
+	for i := 0; i+shingle <= len(runes); i++ {
+		add(runes[i : i+shingle])
+	}
+
+	var fingerprint uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			fingerprint |= 1 << bit
+		}
+	}
+	return fingerprint
+}
+
+// Distance is the Hamming distance between two fingerprints.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"'，。）)\]]+`)
+
+// Links extracts the links in text, normalized (no scheme, no "www.", lower-case host,
+// no trailing slash) and de-duplicated.
+func Links(text string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, raw := range linkPattern.FindAllString(text, -1) {
+		link := strings.ToLower(raw)
+		for _, prefix := range []string{"https://", "http://", "www."} {
+			link = strings.TrimPrefix(link, prefix)
+		}
+		link = strings.TrimRight(link, "/.,;:!?")
+		if link != "" && !seen[link] {
+			seen[link] = true
+			out = append(out, link)
+		}
+	}
+	return out
+}
@@ -0,0 +1,213 @@
+package spam
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const (
+	original   = "Selling second-hand calculus textbooks, contact me after class today"
+	nearCopy   = "Selling second hand calculus textbooks!! contact me after class today"
+	unrelated  = "Does anyone know when the library opens during the exam weeks?"
+	shortReply = "thanks a lot"
+)
+
+func testConfig() Config {
+	cfg := DefaultConfig
+	cfg.NewAccountAge = time.Hour
+	return cfg
+}
+
+func TestSimhashDistance(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		near bool
+	}{
+		{name: "identical", a: original, b: original, near: true},
+		{name: "punctuation and case only", a: original, b: "SELLING second-hand CALCULUS textbooks; contact me after class today!!!", near: true},
+		{name: "small edit", a: original, b: nearCopy, near: true},
+		{name: "unrelated text", a: original, b: unrelated, near: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Distance(Simhash(tt.a), Simhash(tt.b))
+			if near := d <= DefaultConfig.MaxDistance; near != tt.near {
+				t.Fatalf("distance = %d (threshold %d), near = %v, want %v", d, DefaultConfig.MaxDistance, near, tt.near)
+			}
+		})
+	}
+}
+
+func TestCheckNearDuplicates(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []Submission
+		sub     Submission
+		want    string
+	}{
+		{
+			name:    "same user near-duplicate is rejected",
+			history: []Submission{{UserID: "u_1", Text: original}},
+			sub:     Submission{UserID: "u_1", Text: nearCopy},
+			want:    ActionReject,
+		},
+		{
+			name:    "same user different content passes",
+			history: []Submission{{UserID: "u_1", Text: original}},
+			sub:     Submission{UserID: "u_1", Text: unrelated},
+			want:    "",
+		},
+		{
+			name:    "short texts are exempt from duplicate detection",
+			history: []Submission{{UserID: "u_1", Text: shortReply}},
+			sub:     Submission{UserID: "u_1", Text: shortReply},
+			want:    "",
+		},
+		{
+			name:    "one other user posting the same text is not enough to flag",
+			history: []Submission{{UserID: "u_2", Text: original}},
+			sub:     Submission{UserID: "u_1", Text: nearCopy},
+			want:    "",
+		},
+		{
+			name: "the same text from enough other users is flagged",
+			history: []Submission{
+				{UserID: "u_2", Text: original},
+				{UserID: "u_3", Text: nearCopy},
+			},
+			sub:  Submission{UserID: "u_1", Text: original},
+			want: ActionFlag,
+		},
+		{
+			name: "repeats by one other user count once",
+			history: []Submission{
+				{UserID: "u_2", Text: original},
+				{UserID: "u_2", Text: nearCopy},
+			},
+			sub:  Submission{UserID: "u_1", Text: original},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDetector(testConfig())
+			for _, past := range tt.history {
+				d.Record(past)
+			}
+			if got := d.Check(tt.sub); got.Action != tt.want {
+				t.Fatalf("Check = %+v, want action %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckDoesNotRecord(t *testing.T) {
+	d := NewDetector(testConfig())
+	sub := Submission{UserID: "u_1", Text: original}
+
+	// A submission whose save failed must not block an honest retry.
+	if got := d.Check(sub); got.Action != "" {
+		t.Fatalf("first Check = %+v, want clean", got)
+	}
+	if got := d.Check(sub); got.Action != "" {
+		t.Fatalf("retry Check = %+v, want clean", got)
+	}
+
+	d.Record(sub)
+	if got := d.Check(sub); got.Action != ActionReject {
+		t.Fatalf("Check after Record = %+v, want %q", got, ActionReject)
+	}
+}
+
+func TestCheckWindowExpires(t *testing.T) {
+	cfg := testConfig()
+	cfg.Window = 20 * time.Millisecond
+	d := NewDetector(cfg)
+	d.Record(Submission{UserID: "u_1", Text: original})
+
+	time.Sleep(30 * time.Millisecond)
+	if got := d.Check(Submission{UserID: "u_1", Text: original}); got.Action != "" {
+		t.Fatalf("Check after window = %+v, want clean", got)
+	}
+}
+
+func TestCheckLinks(t *testing.T) {
+	fresh := time.Now().Add(-time.Minute)
+	old := time.Now().Add(-48 * time.Hour)
+
+	tests := []struct {
+		name    string
+		history []Submission
+		sub     Submission
+		want    string
+	}{
+		{
+			name: "new account with one link passes",
+			sub:  Submission{UserID: "u_1", AccountCreated: fresh, Text: "see https://example.com"},
+			want: "",
+		},
+		{
+			name: "new account with too many links is rejected",
+			sub:  Submission{UserID: "u_1", AccountCreated: fresh, Text: "https://a.example and www.b.example"},
+			want: ActionReject,
+		},
+		{
+			name: "the same link twice counts once",
+			sub:  Submission{UserID: "u_1", AccountCreated: fresh, Text: "https://a.example/ and http://www.A.example"},
+			want: "",
+		},
+		{
+			name: "older accounts may post several links",
+			sub:  Submission{UserID: "u_1", AccountCreated: old, Text: "https://a.example and www.b.example"},
+			want: "",
+		},
+		{
+			name: "a link repeated too often by one user is rejected",
+			history: []Submission{
+				{UserID: "u_1", Text: "first https://shop.example/deal"},
+				{UserID: "u_1", Text: "another post www.shop.example/deal/"},
+			},
+			sub:  Submission{UserID: "u_1", AccountCreated: old, Text: "third time http://shop.example/deal"},
+			want: ActionReject,
+		},
+		{
+			name: "the same link from other users does not count",
+			history: []Submission{
+				{UserID: "u_2", Text: "first https://shop.example/deal"},
+				{UserID: "u_3", Text: "another post www.shop.example/deal/"},
+			},
+			sub:  Submission{UserID: "u_1", AccountCreated: old, Text: "third time http://shop.example/deal"},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDetector(testConfig())
+			for _, past := range tt.history {
+				d.Record(past)
+			}
+			if got := d.Check(tt.sub); got.Action != tt.want {
+				t.Fatalf("Check = %+v, want action %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinks(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "no links here", want: nil},
+		{text: "go to https://Example.com/path/ now", want: []string{"example.com/path"}},
+		{text: "www.example.com, http://example.com.", want: []string{"example.com"}},
+		{text: "链接：https://example.com/a，还有 https://example.com/b。", want: []string{"example.com/a", "example.com/b"}},
+	}
+	for _, tt := range tests {
+		if got := Links(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Links(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
@@ -3,6 +3,9 @@ package transport
 import (
 	"encoding/json"
 	"net/http"
+	"net/netip"
+	"os"
+	"strings"
 )
 
 type ErrorResponse struct {
@@ -28,3 +31,71 @@ func WriteJSON(w http.ResponseWriter, status int, v any) {
 func WriteError(w http.ResponseWriter, status int, code int, message string) {
 	WriteJSON(w, status, ErrorResponse{Code: code, Message: message})
 }
+
+// ClientIP returns the caller's address. X-Forwarded-For is only honored when the request
+// comes from a proxy listed in TRUSTED_PROXIES (IPs or CIDRs, comma/space separated); the
+// header is then read right to left and the first hop that is not itself a trusted proxy wins.
+// Without trusted proxies the header is ignored, since any client can set it.
+func ClientIP(r *http.Request) string {
+	peer, ok := remoteAddr(r)
+	if !ok {
+		return ""
+	}
+	trusted := trustedProxies()
+	if !isTrusted(trusted, peer) {
+		return peer.String()
+	}
+
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		addr = addr.Unmap()
+		if !isTrusted(trusted, addr) {
+			return addr.String()
+		}
+		peer = addr
+	}
+	return peer.String()
+}
+
+func remoteAddr(r *http.Request) (netip.Addr, bool) {
+	hostport := strings.TrimSpace(r.RemoteAddr)
+	if addrPort, err := netip.ParseAddrPort(hostport); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+	if addr, err := netip.ParseAddr(hostport); err == nil {
+		return addr.Unmap(), true
+	}
+	return netip.Addr{}, false
+}
+
+// trustedProxies parses TRUSTED_PROXIES; single addresses become /32 (or /128) prefixes and
+// malformed entries are skipped.
+func trustedProxies() []netip.Prefix {
+	raw := strings.TrimSpace(os.Getenv("TRUSTED_PROXIES"))
+	if raw == "" {
+		return nil
+	}
+	parts := strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == ';' || r == ' ' || r == '\t' || r == '\n' })
+	prefixes := make([]netip.Prefix, 0, len(parts))
+	for _, part := range parts {
+		if prefix, err := netip.ParsePrefix(part); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(part); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+		}
+	}
+	return prefixes
+}
+
+func isTrusted(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package transport
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name      string
+		trusted   string
+		remote    string
+		forwarded string
+		want      string
+	}{
+		{
+			name:   "no proxy",
+			remote: "198.51.100.7:5000",
+			want:   "198.51.100.7",
+		},
+		{
+			name:      "forwarded header is ignored without trusted proxies",
+			remote:    "198.51.100.7:5000",
+			forwarded: "203.0.113.9",
+			want:      "198.51.100.7",
+		},
+		{
+			name:      "forwarded header is ignored from an untrusted peer",
+			trusted:   "10.0.0.1",
+			remote:    "198.51.100.7:5000",
+			forwarded: "203.0.113.9",
+			want:      "198.51.100.7",
+		},
+		{
+			name:      "trusted proxy passes the client through",
+			trusted:   "10.0.0.1",
+			remote:    "10.0.0.1:5000",
+			forwarded: "203.0.113.9",
+			want:      "203.0.113.9",
+		},
+		{
+			name:      "spoofed leftmost hops are skipped",
+			trusted:   "10.0.0.0/8",
+			remote:    "10.0.0.1:5000",
+			forwarded: "192.0.2.1, 203.0.113.9, 10.0.0.2",
+			want:      "203.0.113.9",
+		},
+		{
+			name:      "malformed hop stops at the last trusted address",
+			trusted:   "10.0.0.0/8",
+			remote:    "10.0.0.1:5000",
+			forwarded: "203.0.113.9, bogus, 10.0.0.2",
+			want:      "10.0.0.2",
+		},
+		{
+			name:    "trusted proxy without the header",
+			trusted: "10.0.0.1",
+			remote:  "10.0.0.1:5000",
+			want:    "10.0.0.1",
+		},
+		{
+			name:      "IPv6 proxy",
+			trusted:   "::1",
+			remote:    "[::1]:5000",
+			forwarded: "2001:db8::5",
+			want:      "2001:db8::5",
+		},
+		{
+			name:   "unparsable remote address",
+			remote: "pipe",
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TRUSTED_PROXIES", tt.trusted)
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remote
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if got := ClientIP(r); got != tt.want {
+				t.Fatalf("ClientIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
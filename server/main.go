@@ -1,19 +1,27 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"log"
+	"net"
 	"net/http"
-	"net/netip"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/Versifine/Cumt-cumpus-hub/server/admin"
+	"github.com/Versifine/Cumt-cumpus-hub/server/appeal"
 	"github.com/Versifine/Cumt-cumpus-hub/server/auth"
 	"github.com/Versifine/Cumt-cumpus-hub/server/chat"
 	"github.com/Versifine/Cumt-cumpus-hub/server/community"
 	"github.com/Versifine/Cumt-cumpus-hub/server/file"
+	"github.com/Versifine/Cumt-cumpus-hub/server/internal/contentfilter"
 	"github.com/Versifine/Cumt-cumpus-hub/server/internal/transport"
+	"github.com/Versifine/Cumt-cumpus-hub/server/moderation"
 	"github.com/Versifine/Cumt-cumpus-hub/server/report"
 	"github.com/Versifine/Cumt-cumpus-hub/server/store"
 )
@@ -42,7 +50,13 @@ func main() {
 	}
 
 	// 认证服务：依赖 store，用于登录、获取当前用户等。
-	authService := &auth.Service{Store: dataStore}
+	// 每次成功解析 token 时顺带记录活跃/会话访问，避免在日志中间件里再查一次用户。
+	activity := &activityRecorder{store: dataStore}
+	authService := &auth.Service{Store: dataStore, OnSession: activity.record}
+
+	// 敏感词过滤器：词库保存在 store 中，启动时加载，管理员修改词库后立即热更新。
+	contentFilter := contentfilter.New()
+	contentFilter.Reload(dataStore)
 
 	// 聊天 Hub：用于管理 WebSocket 连接、广播消息等（典型的 hub-and-spoke 结构）。
 	chatHub := chat.NewHub()
@@ -51,12 +65,31 @@ func main() {
 	// 3) 初始化各业务 Handler
 	// -----------------------------
 	// 社区模块 Handler：依赖 store（数据读写）和 Auth（鉴权/当前用户信息）。
-	communityHandler := &community.Handler{Store: dataStore, Auth: authService}
+	communityHandler := &community.Handler{Store: dataStore, Auth: authService, Filter: contentFilter}
 
 	// 聊天模块 Handler：依赖 store（消息/会话数据等）和 Hub（WS 连接管理）。
-	chatHandler := &chat.Handler{Store: dataStore, Hub: chatHub}
+	chatHandler := &chat.Handler{Store: dataStore, Auth: authService, Hub: chatHub, Filter: contentFilter}
+
+	// 帖子讨论室跟随帖子状态：删除/隐藏后关闭、锁帖后只读；帖子详情显示讨论室在线人数。
+	communityHandler.PostChanged = chatHandler.PostChanged
+	communityHandler.ChatOnline = chatHandler.PostRoomOnline
+
+	// 举报 Handler：AUTO_HIDE_* 环境变量控制被多人举报的内容何时自动隐藏。
+	reportHandler := &report.Handler{
+		Store:       dataStore,
+		Auth:        authService,
+		AutoHide:    report.AutoHideRuleFromEnv(),
+		PostChanged: chatHandler.PostChanged,
+	}
+
+	// 申诉 Handler：用户对举报处理结果或处罚提出申诉，管理员在独立队列中处理。
+	appealHandler := &appeal.Handler{Store: dataStore, Auth: authService}
+
+	// 管理后台 Handler：除举报以外的管理员接口（例如彻底删除帖子）。
+	adminHandler := &admin.Handler{Store: dataStore, Auth: authService, Filter: contentFilter, PostChanged: chatHandler.PostChanged}
 
-	reportHandler := &report.Handler{Store: dataStore, Auth: authService}
+	// 版主 Handler：版主在自己负责的版块内删除/恢复、置顶、锁帖并查看操作日志。
+	modHandler := &moderation.Handler{Store: dataStore, Auth: authService, PostChanged: chatHandler.PostChanged}
 
 	// 文件模块 Handler：依赖 store、鉴权服务，以及上传目录配置。
 	fileHandler := &file.Handler{
@@ -88,12 +121,32 @@ func main() {
 	// 获取当前登录用户信息（通常依赖鉴权 token/cookie 等）。
 	mux.HandleFunc("/api/v1/users/me", authService.MeHandler)
 
+	// 当前用户收藏的帖子（分页）。
+	mux.HandleFunc("/api/v1/users/me/bookmarks", communityHandler.MyBookmarks)
+
 	// -----------------------------
 	// 6) REST API：社区相关
 	// -----------------------------
 	// boards 列表/创建等操作（具体取决于 communityHandler.Boards 的实现）。
 	mux.HandleFunc("/api/v1/boards", communityHandler.Boards)
 
+	// 版块订阅：POST/DELETE /api/v1/boards/{board_id}/subscription
+	mux.HandleFunc("/api/v1/boards/", func(w http.ResponseWriter, r *http.Request) {
+		trimmed := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/boards/"), "/")
+		parts := strings.Split(trimmed, "/")
+		if len(parts) == 2 && parts[0] != "" && parts[1] == "subscription" {
+			communityHandler.BoardSubscription(parts[0])(w, r)
+			return
+		}
+		transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+	})
+
+	// 个性化首页：订阅版块的帖子合并后按 sort 排序。
+	mux.HandleFunc("/api/v1/feed", communityHandler.Feed)
+
+	// 用户申请开新版块（POST）/ 查看自己的申请（GET）。
+	mux.HandleFunc("/api/v1/board-applications", communityHandler.BoardApplications)
+
 	// posts 列表/创建等操作。
 	mux.HandleFunc("/api/v1/posts", communityHandler.Posts)
 
@@ -116,6 +169,10 @@ func main() {
 			communityHandler.Votes(parts[0])(w, r)
 			return
 		}
+		if len(parts) == 2 && parts[1] == "bookmark" {
+			communityHandler.Bookmark(parts[0])(w, r)
+			return
+		}
 		if len(parts) == 2 && parts[1] == "comments" {
 			communityHandler.Comments(parts[0])(w, r)
 			return
@@ -147,6 +204,167 @@ func main() {
 		reportHandler.AdminUpdate(reportID)(w, r)
 	})
 
+	// 申诉：被删内容/被处罚的用户提交申诉（禁言/封禁中也可访问）；管理员审核通过后自动恢复内容或解除处罚。
+	mux.HandleFunc("/api/v1/appeals", appealHandler.Appeals)
+	mux.HandleFunc("/api/v1/appeals/eligible", appealHandler.Eligible)
+	mux.HandleFunc("/api/v1/admin/appeals", appealHandler.AdminList)
+	mux.HandleFunc("/api/v1/admin/appeals/", func(w http.ResponseWriter, r *http.Request) {
+		appealID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/admin/appeals/"), "/")
+		if appealID == "" || strings.Contains(appealID, "/") {
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			return
+		}
+		appealHandler.AdminHandle(appealID)(w, r)
+	})
+	mux.HandleFunc("/api/v1/admin/boards", adminHandler.Boards)
+	mux.HandleFunc("/api/v1/admin/boards/", func(w http.ResponseWriter, r *http.Request) {
+		trimmed := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/admin/boards/"), "/")
+		parts := strings.Split(trimmed, "/")
+		if parts[0] == "" {
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			return
+		}
+		switch {
+		case len(parts) == 1:
+			adminHandler.Board(parts[0])(w, r)
+		case len(parts) == 2 && parts[1] == "moderators":
+			adminHandler.Moderators(parts[0])(w, r)
+		case len(parts) == 3 && parts[1] == "moderators" && parts[2] != "":
+			adminHandler.Moderator(parts[0], parts[2])(w, r)
+		default:
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+		}
+	})
+	mux.HandleFunc("/api/v1/admin/board-applications", adminHandler.BoardApplications)
+	mux.HandleFunc("/api/v1/admin/board-applications/", func(w http.ResponseWriter, r *http.Request) {
+		appID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/admin/board-applications/"), "/")
+		if appID == "" || strings.Contains(appID, "/") {
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			return
+		}
+		adminHandler.BoardApplication(appID)(w, r)
+	})
+	mux.HandleFunc("/api/v1/admin/posts/", func(w http.ResponseWriter, r *http.Request) {
+		postID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/admin/posts/"), "/")
+		if postID == "" || strings.Contains(postID, "/") {
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			return
+		}
+		adminHandler.Post(postID)(w, r)
+	})
+	mux.HandleFunc("/api/v1/admin/audit-log", adminHandler.AuditLog)
+
+	// 管理后台统计：日活、注册、发帖/评论/消息数、热门版块、待处理举报、存储占用。
+	mux.HandleFunc("/api/v1/admin/stats", adminHandler.Stats)
+	mux.HandleFunc("/api/v1/admin/filter/words", adminHandler.FilterWords)
+	mux.HandleFunc("/api/v1/admin/filter/words/", func(w http.ResponseWriter, r *http.Request) {
+		wordID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/admin/filter/words/"), "/")
+		if wordID == "" || strings.Contains(wordID, "/") {
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			return
+		}
+		adminHandler.FilterWord(wordID)(w, r)
+	})
+	mux.HandleFunc("/api/v1/admin/filter/categories", adminHandler.FilterCategories)
+	mux.HandleFunc("/api/v1/admin/filter/categories/", func(w http.ResponseWriter, r *http.Request) {
+		name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/admin/filter/categories/"), "/")
+		if name == "" || strings.Contains(name, "/") {
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			return
+		}
+		adminHandler.FilterCategory(name)(w, r)
+	})
+	mux.HandleFunc("/api/v1/admin/filter/reload", adminHandler.ReloadFilter)
+	// 用户管理：搜索、详情（会话/IP/计数）、发帖/评论/被举报记录、重置密码、强制下线、修改角色、处罚。
+	mux.HandleFunc("/api/v1/admin/users", adminHandler.Users)
+	mux.HandleFunc("/api/v1/admin/users/", func(w http.ResponseWriter, r *http.Request) {
+		trimmed := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/admin/users/"), "/")
+		parts := strings.Split(trimmed, "/")
+		if parts[0] == "" {
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			return
+		}
+		if len(parts) == 1 {
+			adminHandler.User(parts[0])(w, r)
+			return
+		}
+		if len(parts) == 2 {
+			switch parts[1] {
+			case "sanctions":
+				adminHandler.UserSanctions(parts[0])(w, r)
+				return
+			case "posts":
+				adminHandler.UserPosts(parts[0])(w, r)
+				return
+			case "comments":
+				adminHandler.UserComments(parts[0])(w, r)
+				return
+			case "reports":
+				adminHandler.UserReports(parts[0])(w, r)
+				return
+			case "sessions":
+				adminHandler.UserSessions(parts[0])(w, r)
+				return
+			case "password":
+				adminHandler.UserPassword(parts[0])(w, r)
+				return
+			case "role":
+				adminHandler.UserRole(parts[0])(w, r)
+				return
+			}
+		}
+		transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+	})
+	mux.HandleFunc("/api/v1/admin/sanctions/", func(w http.ResponseWriter, r *http.Request) {
+		sanctionID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/admin/sanctions/"), "/")
+		if sanctionID == "" || strings.Contains(sanctionID, "/") {
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			return
+		}
+		adminHandler.Sanction(sanctionID)(w, r)
+	})
+
+	// 版主接口：/api/v1/mod/*，权限按版块校验（管理员可管理所有版块）。
+	mux.HandleFunc("/api/v1/mod/boards", modHandler.Boards)
+	mux.HandleFunc("/api/v1/mod/boards/", func(w http.ResponseWriter, r *http.Request) {
+		trimmed := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/mod/boards/"), "/")
+		parts := strings.Split(trimmed, "/")
+		if len(parts) == 1 && parts[0] != "" {
+			modHandler.Board(parts[0])(w, r)
+			return
+		}
+		if len(parts) == 2 && parts[0] != "" && parts[1] == "log" {
+			modHandler.Log(parts[0])(w, r)
+			return
+		}
+		if len(parts) == 2 && parts[0] != "" && parts[1] == "reports" {
+			reportHandler.ModList(parts[0])(w, r)
+			return
+		}
+		transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+	})
+	mux.HandleFunc("/api/v1/mod/posts/", func(w http.ResponseWriter, r *http.Request) {
+		trimmed := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/mod/posts/"), "/")
+		parts := strings.Split(trimmed, "/")
+		if len(parts) == 1 && parts[0] != "" {
+			modHandler.Post(parts[0])(w, r)
+			return
+		}
+		if len(parts) == 3 && parts[0] != "" && parts[1] == "comments" && parts[2] != "" {
+			modHandler.Comment(parts[0], parts[2])(w, r)
+			return
+		}
+		transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+	})
+	mux.HandleFunc("/api/v1/mod/reports/", func(w http.ResponseWriter, r *http.Request) {
+		reportID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/mod/reports/"), "/")
+		if reportID == "" || strings.Contains(reportID, "/") {
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			return
+		}
+		reportHandler.ModUpdate(reportID)(w, r)
+	})
+
 	// -----------------------------
 	// 7) REST API：文件上传/下载
 	// -----------------------------
@@ -169,6 +387,47 @@ func main() {
 	// WebSocket 入口：/ws/chat
 	mux.HandleFunc("/ws/chat", chatHandler.ServeWS)
 
+	// 聊天连接指标：在线连接数、因发送缓冲区满丢弃的消息、被断开的慢消费者与失联连接。
+	mux.HandleFunc("/api/v1/admin/chat/metrics", chatHandler.Metrics)
+
+	// 聊天室登记：列表/创建（GET/POST），详情/归档（GET/DELETE /{room_id}）。chat.join 只接受已登记且未归档的房间。
+	mux.HandleFunc("/api/v1/chat/rooms", chatHandler.Rooms)
+	mux.HandleFunc("/api/v1/chat/rooms/", func(w http.ResponseWriter, r *http.Request) {
+		roomID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/chat/rooms/"), "/")
+		if roomID == "" || strings.Contains(roomID, "/") {
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			return
+		}
+		chatHandler.Room(roomID)(w, r)
+	})
+
+	// 私信：会话列表/发起（GET/POST），详情/退出群聊（GET/DELETE /{conversation_id}），标记已读（POST /{conversation_id}/read）。
+	// 消息本身仍通过 /ws/chat 的 chat.send / chat.history 收发，roomId 即会话 ID。
+	mux.HandleFunc("/api/v1/conversations", chatHandler.Conversations)
+	mux.HandleFunc("/api/v1/conversations/", func(w http.ResponseWriter, r *http.Request) {
+		trimmed := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/conversations/"), "/")
+		parts := strings.Split(trimmed, "/")
+		switch {
+		case len(parts) == 1 && parts[0] != "":
+			chatHandler.Conversation(parts[0])(w, r)
+		case len(parts) == 2 && parts[0] != "" && parts[1] == "read":
+			chatHandler.ConversationRead(parts[0])(w, r)
+		default:
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+		}
+	})
+
+	// 屏蔽用户：列表（GET），屏蔽/取消屏蔽（PUT/DELETE /{user_id}）。被屏蔽者无法向当前用户发起或发送私信。
+	mux.HandleFunc("/api/v1/users/me/blocks", chatHandler.Blocks)
+	mux.HandleFunc("/api/v1/users/me/blocks/", func(w http.ResponseWriter, r *http.Request) {
+		userID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/users/me/blocks/"), "/")
+		if userID == "" || strings.Contains(userID, "/") {
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			return
+		}
+		chatHandler.Block(userID)(w, r)
+	})
+
 	// -----------------------------
 	// 9) 静态资源：前端页面
 	// -----------------------------
@@ -193,7 +452,7 @@ func main() {
 	server := &http.Server{
 		Addr: addr,
 		// 外层套一层 logging 中间件，用于打印请求日志。
-		Handler: logging(dataStore, mux),
+		Handler: logging(mux),
 
 		// 读取请求头的超时时间，避免慢速请求头攻击（Slowloris）。
 		ReadHeaderTimeout: 5 * time.Second,
@@ -221,30 +480,81 @@ func mustCreateStore(uploadDir string) store.API {
 	return dbStore
 }
 
-// logging 是一个非常简单的中间件：
-// 每次请求都会打印 "METHOD PATH"，然后继续交给下游 handler 处理。
-func logging(dataStore store.API, next http.Handler) http.Handler {
+// logging 是请求日志中间件：每个请求结束后打印方法、路径、状态码、客户端 IP、用户和耗时。
+// 用户 ID 由 activityRecorder.record 在认证成功时写入请求上下文，这里不再单独查库。
+func logging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		userID := "-"
+		r = r.WithContext(context.WithValue(r.Context(), requestUserKey{}, &userID))
 		next.ServeHTTP(sw, r)
 
-		userID := "-"
-		token := strings.TrimSpace(r.Header.Get("Authorization"))
-		if strings.HasPrefix(strings.ToLower(token), "bearer ") {
-			token = strings.TrimSpace(token[7:])
-		} else {
-			token = ""
-		}
-		if token != "" {
-			if user, ok := dataStore.UserByToken(token); ok {
-				userID = user.ID
+		log.Printf("%s %s status=%d ip=%s user=%s dur=%s", r.Method, r.URL.Path, sw.status, transport.ClientIP(r), userID, time.Since(start))
+	})
+}
+
+// requestUserKey 对应的上下文值是 logging 为本次请求准备的 *string，用于回填已认证的用户 ID。
+type requestUserKey struct{}
+
+// activityRecorder 记录每日活跃用户（供管理后台统计使用）以及会话最近访问时间/IP（供管理员查看用户）。
+// 为避免每个请求都写库：同一用户每天只记一次活跃，同一会话+IP 每分钟最多更新一次。
+// 内存记录跨天清空，超过一分钟未访问的会话记录会被定期清理。
+type activityRecorder struct {
+	store store.API
+
+	mu      sync.Mutex
+	day     string
+	seen    map[string]bool
+	touched map[string]time.Time
+	pruned  time.Time
+}
+
+const sessionTouchInterval = time.Minute
+
+// record 作为 auth.Service.OnSession 使用，在 token 解析成功时调用。
+func (a *activityRecorder) record(r *http.Request, token string, user store.User) {
+	if slot, ok := r.Context().Value(requestUserKey{}).(*string); ok {
+		*slot = user.ID
+	}
+
+	now := time.Now()
+	day := now.UTC().Format(time.DateOnly)
+	ip := transport.ClientIP(r)
+	sessionKey := token + "|" + ip
+
+	a.mu.Lock()
+	if a.day != day {
+		a.day = day
+		a.seen = make(map[string]bool)
+		a.touched = make(map[string]time.Time)
+	}
+	if now.Sub(a.pruned) >= sessionTouchInterval {
+		for key, at := range a.touched {
+			if now.Sub(at) >= sessionTouchInterval {
+				delete(a.touched, key)
 			}
 		}
+		a.pruned = now
+	}
+	newDay := !a.seen[user.ID]
+	a.seen[user.ID] = true
+	touch := now.Sub(a.touched[sessionKey]) >= sessionTouchInterval
+	if touch {
+		a.touched[sessionKey] = now
+	}
+	a.mu.Unlock()
 
-		ip := clientIP(r)
-		log.Printf("%s %s status=%d ip=%s user=%s dur=%s", r.Method, r.URL.Path, sw.status, ip, userID, time.Since(start))
-	})
+	if newDay {
+		if err := a.store.RecordActivity(user.ID, day); err != nil {
+			log.Printf("record activity %s: %v", user.ID, err)
+		}
+	}
+	if touch {
+		if err := a.store.TouchSession(token, ip); err != nil && err != store.ErrNotFound {
+			log.Printf("touch session %s: %v", user.ID, err)
+		}
+	}
 }
 
 type statusWriter struct {
@@ -257,27 +567,14 @@ func (w *statusWriter) WriteHeader(code int) {
 	w.ResponseWriter.WriteHeader(code)
 }
 
-func clientIP(r *http.Request) string {
-	forwarded := strings.TrimSpace(r.Header.Get("X-Forwarded-For"))
-	if forwarded != "" {
-		first := strings.TrimSpace(strings.Split(forwarded, ",")[0])
-		if addr, err := netip.ParseAddr(first); err == nil {
-			return addr.String()
-		}
-		return first
-	}
-
-	hostport := strings.TrimSpace(r.RemoteAddr)
-	if hostport == "" {
-		return ""
-	}
-	if addrPort, err := netip.ParseAddrPort(hostport); err == nil {
-		return addrPort.Addr().String()
-	}
-	if addr, err := netip.ParseAddr(hostport); err == nil {
-		return addr.String()
+// Hijack 透传给底层 ResponseWriter，否则 /ws/chat 的 WebSocket 升级会失败。
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
 	}
-	return hostport
+	w.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
 }
 
 // defaultUploadDir 推导默认上传目录：
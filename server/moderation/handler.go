@@ -0,0 +1,395 @@
+package moderation
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Versifine/Cumt-cumpus-hub/server/auth"
+	"github.com/Versifine/Cumt-cumpus-hub/server/internal/transport"
+	"github.com/Versifine/Cumt-cumpus-hub/server/store"
+)
+
+// Handler serves the /api/v1/mod/* endpoints used by board moderators (and admins).
+type Handler struct {
+	Store store.API
+	Auth  *auth.Service
+	// PostChanged, if set, is called after a post is removed, locked or hidden (or the reverse)
+	// so its chat room follows.
+	PostChanged func(postID string)
+}
+
+type boardItem struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Archived bool   `json:"archived"`
+	ReadOnly bool   `json:"read_only"`
+}
+
+func newBoardItem(board store.Board) boardItem {
+	return boardItem{ID: board.ID, Name: board.Name, Archived: board.Archived, ReadOnly: board.ReadOnly}
+}
+
+type postState struct {
+	ID      string `json:"id"`
+	BoardID string `json:"board_id"`
+	Removed bool   `json:"removed"`
+	Pinned  bool   `json:"pinned"`
+	Locked  bool   `json:"locked"`
+	Hidden  bool   `json:"hidden"`
+}
+
+// Boards handles GET /api/v1/mod/boards: the boards the current user can moderate.
+func (h *Handler) Boards(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+		return
+	}
+
+	user, ok := h.Auth.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	items := make([]boardItem, 0)
+	if h.Auth.IsAdmin(user) {
+		for _, board := range h.Store.Boards() {
+			items = append(items, newBoardItem(board))
+		}
+	} else {
+		for _, boardID := range h.Store.ModeratedBoardIDs(user.ID) {
+			if board, ok := h.Store.GetBoard(boardID); ok {
+				items = append(items, newBoardItem(board))
+			}
+		}
+	}
+	transport.WriteJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+// Board handles PATCH /api/v1/mod/boards/{board_id}: read-only and archived switches.
+func (h *Handler) Board(boardID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+			return
+		}
+
+		user, ok := h.Auth.RequireUser(w, r)
+		if !ok {
+			return
+		}
+		board, ok := h.Store.GetBoard(boardID)
+		if !ok {
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			return
+		}
+		if !h.Auth.CanModerate(user, board.ID) {
+			transport.WriteError(w, http.StatusForbidden, 1002, "forbidden")
+			return
+		}
+
+		var req struct {
+			ReadOnly *bool  `json:"read_only"`
+			Archived *bool  `json:"archived"`
+			Reason   string `json:"reason"`
+		}
+		if err := transport.ReadJSON(r, &req); err != nil {
+			transport.WriteError(w, http.StatusBadRequest, 2001, "invalid json")
+			return
+		}
+		if req.ReadOnly == nil && req.Archived == nil {
+			transport.WriteError(w, http.StatusBadRequest, 2001, "missing fields")
+			return
+		}
+
+		var entries []store.AuditEntry
+		if req.ReadOnly != nil && *req.ReadOnly != board.ReadOnly {
+			board.ReadOnly = *req.ReadOnly
+			entries = append(entries, flagEntry(pick(board.ReadOnly, "set_board_read_only", "clear_board_read_only"), "read_only", board.ReadOnly))
+		}
+		if req.Archived != nil && *req.Archived != board.Archived {
+			board.Archived = *req.Archived
+			entries = append(entries, flagEntry(pick(board.Archived, "archive_board", "unarchive_board"), "archived", board.Archived))
+		}
+		if len(entries) > 0 {
+			updated, err := h.Store.UpdateBoard(board)
+			if err != nil {
+				writeStoreError(w, err)
+				return
+			}
+			board = updated
+			for _, entry := range entries {
+				entry.ActorID = user.ID
+				entry.TargetType = "board"
+				entry.TargetID = board.ID
+				entry.BoardID = board.ID
+				entry.Reason = req.Reason
+				h.audit(r, entry)
+			}
+		}
+
+		transport.WriteJSON(w, http.StatusOK, map[string]any{
+			"id":        board.ID,
+			"read_only": board.ReadOnly,
+			"archived":  board.Archived,
+		})
+	}
+}
+
+// Post handles PATCH /api/v1/mod/posts/{post_id}.
+func (h *Handler) Post(postID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+			return
+		}
+
+		user, ok := h.Auth.RequireUser(w, r)
+		if !ok {
+			return
+		}
+		post, ok := h.Store.LookupPost(postID)
+		if !ok {
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			return
+		}
+		if !h.Auth.CanModerate(user, post.BoardID) {
+			transport.WriteError(w, http.StatusForbidden, 1002, "forbidden")
+			return
+		}
+
+		var req struct {
+			Removed *bool  `json:"removed"`
+			Pinned  *bool  `json:"pinned"`
+			Locked  *bool  `json:"locked"`
+			Hidden  *bool  `json:"hidden"`
+			Reason  string `json:"reason"`
+		}
+		if err := transport.ReadJSON(r, &req); err != nil {
+			transport.WriteError(w, http.StatusBadRequest, 2001, "invalid json")
+			return
+		}
+		if req.Removed == nil && req.Pinned == nil && req.Locked == nil && req.Hidden == nil {
+			transport.WriteError(w, http.StatusBadRequest, 2001, "missing fields")
+			return
+		}
+
+		changes := []struct {
+			value   *bool
+			current bool
+			set     func(string, bool) error
+			field   string
+			on, off string
+		}{
+			{req.Removed, post.DeletedAt != "", h.Store.SetPostDeleted, "removed", "remove_post", "restore_post"},
+			{req.Pinned, post.PinnedAt != "", h.Store.SetPostPinned, "pinned", "pin_post", "unpin_post"},
+			{req.Locked, post.LockedAt != "", h.Store.SetPostLocked, "locked", "lock_post", "unlock_post"},
+			{req.Hidden, post.HiddenAt != "", h.Store.SetPostHidden, "hidden", "hide_post", "unhide_post"},
+		}
+		for _, change := range changes {
+			if change.value == nil || *change.value == change.current {
+				continue
+			}
+			if err := change.set(post.ID, *change.value); err != nil {
+				writeStoreError(w, err)
+				return
+			}
+			h.audit(r, store.AuditEntry{
+				ActorID:    user.ID,
+				Action:     pick(*change.value, change.on, change.off),
+				TargetType: "post",
+				TargetID:   post.ID,
+				BoardID:    post.BoardID,
+				Reason:     req.Reason,
+				Before:     flagState(change.field, change.current),
+				After:      flagState(change.field, *change.value),
+			})
+		}
+
+		updated, _ := h.Store.LookupPost(post.ID)
+		if h.PostChanged != nil && (req.Removed != nil || req.Locked != nil || req.Hidden != nil) {
+			h.PostChanged(post.ID)
+		}
+		transport.WriteJSON(w, http.StatusOK, postState{
+			ID:      updated.ID,
+			BoardID: updated.BoardID,
+			Removed: updated.DeletedAt != "",
+			Pinned:  updated.PinnedAt != "",
+			Locked:  updated.LockedAt != "",
+			Hidden:  updated.HiddenAt != "",
+		})
+	}
+}
+
+// Comment handles PATCH /api/v1/mod/posts/{post_id}/comments/{comment_id}.
+func (h *Handler) Comment(postID, commentID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+			return
+		}
+
+		user, ok := h.Auth.RequireUser(w, r)
+		if !ok {
+			return
+		}
+		post, ok := h.Store.LookupPost(postID)
+		if !ok {
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			return
+		}
+		comment, ok := h.Store.LookupComment(commentID)
+		if !ok || comment.PostID != post.ID {
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			return
+		}
+		if !h.Auth.CanModerate(user, post.BoardID) {
+			transport.WriteError(w, http.StatusForbidden, 1002, "forbidden")
+			return
+		}
+
+		var req struct {
+			Removed *bool  `json:"removed"`
+			Hidden  *bool  `json:"hidden"`
+			Reason  string `json:"reason"`
+		}
+		if err := transport.ReadJSON(r, &req); err != nil {
+			transport.WriteError(w, http.StatusBadRequest, 2001, "invalid json")
+			return
+		}
+		if req.Removed == nil && req.Hidden == nil {
+			transport.WriteError(w, http.StatusBadRequest, 2001, "missing fields")
+			return
+		}
+
+		changes := []struct {
+			value   *bool
+			current bool
+			set     func(string, string, bool) error
+			field   string
+			on, off string
+		}{
+			{req.Removed, comment.DeletedAt != "", h.Store.SetCommentDeleted, "removed", "remove_comment", "restore_comment"},
+			{req.Hidden, comment.HiddenAt != "", h.Store.SetCommentHidden, "hidden", "hide_comment", "unhide_comment"},
+		}
+		for _, change := range changes {
+			if change.value == nil || *change.value == change.current {
+				continue
+			}
+			if err := change.set(post.ID, comment.ID, *change.value); err != nil {
+				writeStoreError(w, err)
+				return
+			}
+			h.audit(r, store.AuditEntry{
+				ActorID:    user.ID,
+				Action:     pick(*change.value, change.on, change.off),
+				TargetType: "comment",
+				TargetID:   comment.ID,
+				BoardID:    post.BoardID,
+				Reason:     req.Reason,
+				Before:     flagState(change.field, change.current),
+				After:      flagState(change.field, *change.value),
+			})
+		}
+
+		updated, _ := h.Store.LookupComment(comment.ID)
+		transport.WriteJSON(w, http.StatusOK, map[string]any{
+			"id":      updated.ID,
+			"post_id": post.ID,
+			"removed": updated.DeletedAt != "",
+			"hidden":  updated.HiddenAt != "",
+		})
+	}
+}
+
+// Log handles GET /api/v1/mod/boards/{board_id}/log: the board's moderation audit trail.
+func (h *Handler) Log(boardID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+			return
+		}
+
+		user, ok := h.Auth.RequireUser(w, r)
+		if !ok {
+			return
+		}
+		if _, ok := h.Store.GetBoard(boardID); !ok {
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			return
+		}
+		if !h.Auth.CanModerate(user, boardID) {
+			transport.WriteError(w, http.StatusForbidden, 1002, "forbidden")
+			return
+		}
+
+		page := parsePositiveInt(r.URL.Query().Get("page"), 1)
+		pageSize := parsePositiveInt(r.URL.Query().Get("page_size"), 20)
+		filter := store.AuditFilter{
+			BoardID: boardID,
+			ActorID: strings.TrimSpace(r.URL.Query().Get("actor_id")),
+			Action:  strings.TrimSpace(r.URL.Query().Get("action")),
+		}
+		items, total, err := h.Store.AuditEntries(filter, page, pageSize)
+		if err != nil {
+			transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+			return
+		}
+		transport.WriteJSON(w, http.StatusOK, map[string]any{
+			"items": items,
+			"total": total,
+		})
+	}
+}
+
+// audit records a moderation action; the action itself already succeeded, so failures are only logged.
+func (h *Handler) audit(r *http.Request, entry store.AuditEntry) {
+	entry.IP = transport.ClientIP(r)
+	if _, err := h.Store.AddAuditEntry(entry); err != nil {
+		log.Printf("audit %s %s: %v", entry.Action, entry.TargetID, err)
+	}
+}
+
+// flagEntry starts an audit entry for a boolean switch that was just flipped to value.
+func flagEntry(action, field string, value bool) store.AuditEntry {
+	return store.AuditEntry{
+		Action: action,
+		Before: flagState(field, !value),
+		After:  flagState(field, value),
+	}
+}
+
+func flagState(field string, value bool) json.RawMessage {
+	return store.AuditState(map[string]bool{field: value})
+}
+
+// pick returns on when flag is set and off otherwise; used to name audit actions.
+func pick(flag bool, on, off string) string {
+	if flag {
+		return on
+	}
+	return off
+}
+
+func writeStoreError(w http.ResponseWriter, err error) {
+	switch err {
+	case store.ErrNotFound:
+		transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+	default:
+		transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+	}
+}
+
+func parsePositiveInt(value string, fallback int) int {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		return fallback
+	}
+	return parsed
+}
@@ -0,0 +1,163 @@
+package report
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Versifine/Cumt-cumpus-hub/server/store"
+)
+
+// AutoHideRule decides when reported posts/comments are hidden from public listings
+// before a moderator has looked at them.
+//
+// Every distinct reporter with an open report contributes a weight: accounts younger than
+// NewAccountAge count half, accounts with at least TrustedKarma count one and a half, everyone
+// else counts one. Once the sum reaches Threshold the target is hidden. A Threshold <= 0
+// disables auto-hiding.
+type AutoHideRule struct {
+	Threshold     float64
+	NewAccountAge time.Duration
+	TrustedKarma  int
+}
+
+const (
+	newAccountWeight = 0.5
+	trustedWeight    = 1.5
+)
+
+// DefaultAutoHideRule is used when no environment overrides are set.
+var DefaultAutoHideRule = AutoHideRule{
+	Threshold:     3,
+	NewAccountAge: 72 * time.Hour,
+	TrustedKarma:  20,
+}
+
+// AutoHideRuleFromEnv reads AUTO_HIDE_THRESHOLD, AUTO_HIDE_NEW_ACCOUNT_HOURS and
+// AUTO_HIDE_TRUSTED_KARMA, falling back to DefaultAutoHideRule for unset or invalid values.
+func AutoHideRuleFromEnv() AutoHideRule {
+	rule := DefaultAutoHideRule
+	if value, ok := envFloat("AUTO_HIDE_THRESHOLD"); ok {
+		rule.Threshold = value
+	}
+	if value, ok := envFloat("AUTO_HIDE_NEW_ACCOUNT_HOURS"); ok && value >= 0 {
+		rule.NewAccountAge = time.Duration(value * float64(time.Hour))
+	}
+	if value, ok := envFloat("AUTO_HIDE_TRUSTED_KARMA"); ok {
+		rule.TrustedKarma = int(value)
+	}
+	return rule
+}
+
+func envFloat(name string) (float64, bool) {
+	raw := strings.TrimSpace(os.Getenv(name))
+	if raw == "" {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		log.Printf("ignoring invalid %s=%q", name, raw)
+		return 0, false
+	}
+	return value, true
+}
+
+// reporterWeight returns how much a single reporter counts towards the threshold.
+func (h *Handler) reporterWeight(reporterID string, now time.Time) float64 {
+	user, ok := h.Store.GetUser(reporterID)
+	if !ok {
+		return 0
+	}
+	if created, err := time.Parse(time.RFC3339, user.CreatedAt); err == nil && now.Sub(created) < h.AutoHide.NewAccountAge {
+		return newAccountWeight
+	}
+	if h.Store.UserKarma(reporterID) >= h.AutoHide.TrustedKarma {
+		return trustedWeight
+	}
+	return 1
+}
+
+// reportWeight sums the weights of distinct reporters with open reports on the target. Reports
+// filed before the most recent dismissal are ignored so a reviewed target is not re-hidden by
+// the same wave of reports.
+func (h *Handler) reportWeight(targetType, targetID string) float64 {
+	reports := h.Store.TargetReports(targetType, targetID)
+
+	var reviewedAt string
+	for _, report := range reports {
+		if report.Action == store.ReportActionDismiss && report.UpdatedAt > reviewedAt {
+			reviewedAt = report.UpdatedAt
+		}
+	}
+
+	now := time.Now()
+	seen := make(map[string]bool)
+	var total float64
+	for _, report := range reports {
+		if report.Status != "open" || report.CreatedAt < reviewedAt || seen[report.ReporterID] {
+			continue
+		}
+		seen[report.ReporterID] = true
+		total += h.reporterWeight(report.ReporterID, now)
+	}
+	return total
+}
+
+// applyAutoHide hides the report's target once the weighted report count reaches the
+// threshold; it reports whether the target is hidden afterwards.
+func (h *Handler) applyAutoHide(report store.Report) bool {
+	hidden, ok := h.targetHidden(report.TargetType, report.TargetID)
+	if !ok || hidden {
+		return hidden
+	}
+	if h.AutoHide.Threshold <= 0 || h.reportWeight(report.TargetType, report.TargetID) < h.AutoHide.Threshold {
+		return false
+	}
+
+	var err error
+	switch report.TargetType {
+	case "post":
+		err = h.Store.SetPostHidden(report.TargetID, true)
+	case "comment":
+		comment, _ := h.Store.LookupComment(report.TargetID)
+		err = h.Store.SetCommentHidden(comment.PostID, comment.ID, true)
+	}
+	if err != nil {
+		log.Printf("auto-hide %s %s: %v", report.TargetType, report.TargetID, err)
+		return false
+	}
+	if report.TargetType == "post" && h.PostChanged != nil {
+		h.PostChanged(report.TargetID)
+	}
+
+	if _, err := h.Store.AddAuditEntry(store.AuditEntry{
+		ActorID:    "system",
+		Action:     "auto_hide_" + report.TargetType,
+		TargetType: report.TargetType,
+		TargetID:   report.TargetID,
+		BoardID:    report.BoardID,
+		Reason:     "report threshold reached",
+		Before:     store.AuditState(map[string]bool{"hidden": false}),
+		After:      store.AuditState(map[string]bool{"hidden": true}),
+	}); err != nil {
+		log.Printf("audit auto_hide_%s %s: %v", report.TargetType, report.TargetID, err)
+	}
+	return true
+}
+
+// targetHidden reports whether a post/comment target is currently hidden; ok is false for
+// target types that cannot be hidden.
+func (h *Handler) targetHidden(targetType, targetID string) (hidden, ok bool) {
+	switch targetType {
+	case "post":
+		post, found := h.Store.LookupPost(targetID)
+		return post.HiddenAt != "", found
+	case "comment":
+		comment, found := h.Store.LookupComment(targetID)
+		return comment.HiddenAt != "", found
+	default:
+		return false, false
+	}
+}
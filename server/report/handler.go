@@ -1,8 +1,8 @@
 package report
 
 import (
+	"log"
 	"net/http"
-	"os"
 	"strconv"
 	"strings"
 
@@ -12,8 +12,19 @@ import (
 )
 
 type Handler struct {
-	Store store.API
-	Auth  *auth.Service
+	Store    store.API
+	Auth     *auth.Service
+	AutoHide AutoHideRule
+	// PostChanged, if set, is called after a post is removed or hidden through a report.
+	PostChanged func(postID string)
+}
+
+// reportItem is a report plus how often its target has been reported overall.
+type reportItem struct {
+	store.Report
+	TargetReportCount int  `json:"target_report_count"`
+	TargetOpenCount   int  `json:"target_open_count"`
+	TargetHidden      bool `json:"target_hidden"`
 }
 
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
@@ -38,11 +49,29 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	switch strings.TrimSpace(req.TargetType) {
+	case "post", "comment", "user", "message":
+	default:
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid target_type")
+		return
+	}
+
+	// Private conversations and rooms must not leak through reports: only someone who can read
+	// the message may report it (and so copy it into the snapshot moderators see).
+	if strings.TrimSpace(req.TargetType) == "message" && !h.canSeeMessage(user, strings.TrimSpace(req.TargetID)) {
+		transport.WriteError(w, http.StatusNotFound, 2001, "target not found")
+		return
+	}
+
 	report, err := h.Store.CreateReport(user.ID, req.TargetType, req.TargetID, req.Reason, req.Detail)
 	if err != nil {
 		switch err {
 		case store.ErrInvalidInput:
 			transport.WriteError(w, http.StatusBadRequest, 2001, "missing fields")
+		case store.ErrNotFound:
+			transport.WriteError(w, http.StatusNotFound, 2001, "target not found")
+		case store.ErrConflict:
+			transport.WriteError(w, http.StatusConflict, 2001, "already reported")
 		default:
 			transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
 		}
@@ -50,9 +79,10 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	resp := map[string]any{
-		"id":         report.ID,
-		"status":     report.Status,
-		"created_at": report.CreatedAt,
+		"id":            report.ID,
+		"status":        report.Status,
+		"created_at":    report.CreatedAt,
+		"target_hidden": h.applyAutoHide(report),
 	}
 	transport.WriteJSON(w, http.StatusOK, resp)
 }
@@ -63,25 +93,57 @@ func (h *Handler) AdminList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, ok := h.Auth.RequireUser(w, r)
-	if !ok {
+	if _, ok := h.Auth.RequireAdmin(w, r); !ok {
 		return
 	}
-	if !isAdmin(user) {
-		transport.WriteError(w, http.StatusForbidden, 1002, "forbidden")
-		return
+
+	h.list(w, r, strings.TrimSpace(r.URL.Query().Get("board_id")))
+}
+
+// ModList handles GET /api/v1/mod/boards/{board_id}/reports for the board's moderators.
+func (h *Handler) ModList(boardID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+			return
+		}
+
+		user, ok := h.Auth.RequireUser(w, r)
+		if !ok {
+			return
+		}
+		if _, ok := h.Store.GetBoard(boardID); !ok {
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			return
+		}
+		if !h.Auth.CanModerate(user, boardID) {
+			transport.WriteError(w, http.StatusForbidden, 1002, "forbidden")
+			return
+		}
+
+		h.list(w, r, boardID)
 	}
+}
 
+func (h *Handler) list(w http.ResponseWriter, r *http.Request, boardID string) {
 	status := strings.TrimSpace(r.URL.Query().Get("status"))
 	page := parsePositiveInt(r.URL.Query().Get("page"), 1)
 	pageSize := parsePositiveInt(r.URL.Query().Get("page_size"), 20)
 
-	items, total, err := h.Store.Reports(status, page, pageSize)
+	reports, total, err := h.Store.Reports(status, boardID, page, pageSize)
 	if err != nil {
 		transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
 		return
 	}
 
+	items := make([]reportItem, 0, len(reports))
+	for _, report := range reports {
+		item := reportItem{Report: report}
+		item.TargetReportCount, item.TargetOpenCount = h.Store.ReportCounts(report.TargetType, report.TargetID)
+		item.TargetHidden, _ = h.targetHidden(report.TargetType, report.TargetID)
+		items = append(items, item)
+	}
+
 	resp := map[string]any{
 		"items": items,
 		"total": total,
@@ -90,6 +152,23 @@ func (h *Handler) AdminList(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) AdminUpdate(reportID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
+			return
+		}
+
+		user, ok := h.Auth.RequireAdmin(w, r)
+		if !ok {
+			return
+		}
+		h.update(w, r, user, reportID)
+	}
+}
+
+// ModUpdate handles PATCH /api/v1/mod/reports/{report_id}; moderators may only handle reports
+// on their own boards, reports without a board are admin-only.
+func (h *Handler) ModUpdate(reportID string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPatch {
 			transport.WriteError(w, http.StatusMethodNotAllowed, 2001, "method not allowed")
@@ -100,52 +179,130 @@ func (h *Handler) AdminUpdate(reportID string) http.HandlerFunc {
 		if !ok {
 			return
 		}
-		if !isAdmin(user) {
+		report, ok := h.Store.GetReport(reportID)
+		if !ok {
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+			return
+		}
+		if !h.Auth.CanModerate(user, report.BoardID) {
 			transport.WriteError(w, http.StatusForbidden, 1002, "forbidden")
 			return
 		}
+		h.update(w, r, user, reportID)
+	}
+}
 
-		var req struct {
-			Status string `json:"status"`
-			Action string `json:"action"`
-			Note   string `json:"note"`
-		}
-		if err := transport.ReadJSON(r, &req); err != nil {
-			transport.WriteError(w, http.StatusBadRequest, 2001, "invalid json")
+func (h *Handler) update(w http.ResponseWriter, r *http.Request, user store.User, reportID string) {
+	var req struct {
+		Action      string `json:"action"`
+		Note        string `json:"note"`
+		SuspendDays int    `json:"suspend_days"`
+	}
+	if err := transport.ReadJSON(r, &req); err != nil {
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid json")
+		return
+	}
+
+	action := strings.TrimSpace(req.Action)
+	switch action {
+	case store.ReportActionDismiss, store.ReportActionRemoveContent, store.ReportActionWarnUser:
+	case store.ReportActionSuspendUser, store.ReportActionBanUser:
+		// Site-wide account sanctions stay with admins; moderators only act within their board.
+		if !h.Auth.IsAdmin(user) {
+			transport.WriteError(w, http.StatusForbidden, 1002, "forbidden")
 			return
 		}
+	default:
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid action")
+		return
+	}
+	if req.SuspendDays == 0 {
+		req.SuspendDays = store.DefaultSuspendDays
+	}
+	if req.SuspendDays < 0 || req.SuspendDays > store.MaxSuspendDays {
+		transport.WriteError(w, http.StatusBadRequest, 2001, "invalid suspend_days")
+		return
+	}
 
-		updated, err := h.Store.UpdateReport(reportID, req.Status, req.Action, req.Note, user.ID)
-		if err != nil {
-			switch err {
-			case store.ErrInvalidInput:
-				transport.WriteError(w, http.StatusBadRequest, 2001, "missing fields")
-			case store.ErrNotFound:
-				transport.WriteError(w, http.StatusNotFound, 2001, "not found")
-			default:
-				transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
-			}
+	before, _ := h.Store.GetReport(reportID)
+	if action == store.ReportActionWarnUser || action == store.ReportActionSuspendUser || action == store.ReportActionBanUser {
+		// Same rule as the direct sanction endpoint: a report must not lock an admin out.
+		if target, ok := h.Store.GetUser(reportTargetUserID(before)); ok && h.Auth.IsAdmin(target) {
+			transport.WriteError(w, http.StatusBadRequest, 2001, "cannot sanction an admin")
 			return
 		}
-		transport.WriteJSON(w, http.StatusOK, updated)
 	}
+	updated, err := h.Store.UpdateReport(reportID, store.ReportResolution{
+		Action:      action,
+		Note:        req.Note,
+		HandledBy:   user.ID,
+		SuspendDays: req.SuspendDays,
+	})
+	if err != nil {
+		switch err {
+		case store.ErrInvalidInput:
+			transport.WriteError(w, http.StatusBadRequest, 2001, "action not applicable to target")
+		case store.ErrNotFound:
+			transport.WriteError(w, http.StatusNotFound, 2001, "not found")
+		case store.ErrConflict:
+			transport.WriteError(w, http.StatusConflict, 2001, "report already handled")
+		default:
+			transport.WriteError(w, http.StatusInternalServerError, 5000, "server error")
+		}
+		return
+	}
+
+	if _, err := h.Store.AddAuditEntry(store.AuditEntry{
+		ActorID:    user.ID,
+		Action:     "handle_report",
+		TargetType: "report",
+		TargetID:   updated.ID,
+		BoardID:    updated.BoardID,
+		Reason:     req.Note,
+		Before:     store.AuditState(map[string]string{"status": before.Status}),
+		After: store.AuditState(map[string]string{
+			"status":  updated.Status,
+			"action":  updated.Action,
+			"outcome": updated.Outcome,
+		}),
+		IP: transport.ClientIP(r),
+	}); err != nil {
+		log.Printf("audit handle_report %s: %v", updated.ID, err)
+	}
+	if updated.TargetType == "post" && updated.Action == store.ReportActionRemoveContent && h.PostChanged != nil {
+		h.PostChanged(updated.TargetID)
+	}
+	transport.WriteJSON(w, http.StatusOK, updated)
 }
 
-func isAdmin(user store.User) bool {
-	raw := strings.TrimSpace(os.Getenv("ADMIN_ACCOUNTS"))
-	if raw == "" {
+// canSeeMessage reports whether the user can read the message: a member of its conversation,
+// or someone with access to its chat room (public, owned, or an admin).
+func (h *Handler) canSeeMessage(user store.User, messageID string) bool {
+	message, ok := h.Store.GetMessage(messageID)
+	if !ok {
 		return false
 	}
-	parts := strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == ';' || r == ' ' || r == '\t' || r == '\n' })
-	for _, part := range parts {
-		if strings.TrimSpace(part) == "" {
-			continue
-		}
-		if strings.EqualFold(strings.TrimSpace(part), user.Nickname) {
-			return true
-		}
+	if store.IsConversationID(message.RoomID) {
+		convo, ok := h.Store.GetConversation(message.RoomID)
+		return ok && convo.HasMember(user.ID)
+	}
+	room, ok := h.Store.GetChatRoom(message.RoomID)
+	if !ok {
+		return false
+	}
+	return room.Visibility == store.RoomPublic || room.OwnerID == user.ID || h.Auth.IsAdmin(user)
+}
+
+// reportTargetUserID is the user a report's sanction would apply to. Reports filed before
+// target users were recorded fall back to the target itself when it is a user.
+func reportTargetUserID(report store.Report) string {
+	if report.TargetUserID != "" {
+		return report.TargetUserID
+	}
+	if report.TargetType == "user" {
+		return report.TargetID
 	}
-	return false
+	return ""
 }
 
 func parsePositiveInt(value string, fallback int) int {
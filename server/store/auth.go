@@ -14,6 +14,7 @@ var (
 	ErrAccountExists      = errors.New("account already exists")
 	ErrNotFound           = errors.New("not found")
 	ErrForbidden          = errors.New("forbidden")
+	ErrConflict           = errors.New("conflict")
 )
 
 func hashPassword(password string) (string, error) {
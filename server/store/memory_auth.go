@@ -2,6 +2,7 @@ package store
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -33,6 +34,7 @@ func (s *Store) Register(account, password string) (string, User, error) {
 		user := User{
 			ID:        userID,
 			Nickname:  trimmedAccount,
+			Role:      RoleUser,
 			CreatedAt: now(),
 		}
 		s.users[userID] = user
@@ -40,17 +42,10 @@ func (s *Store) Register(account, password string) (string, User, error) {
 		s.passwords[trimmedAccount] = passwordHash
 	}
 
-	token, err := newToken()
+	token, err := s.rotateToken(userID)
 	if err != nil {
 		return "", User{}, err
 	}
-
-	if old := s.userTokens[userID]; old != "" {
-		delete(s.tokens, old)
-	}
-	s.tokens[token] = userID
-	s.userTokens[userID] = token
-
 	return token, s.users[userID], nil
 }
 
@@ -75,19 +70,257 @@ func (s *Store) Login(account, password string) (string, User, error) {
 		return "", User{}, ErrInvalidCredentials
 	}
 
-	token, err := newToken()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, err := s.rotateToken(userID)
 	if err != nil {
 		return "", User{}, err
 	}
+	return token, user, nil
+}
+
+// rotateToken replaces the user's session with a fresh token. Callers must hold s.mu.
+func (s *Store) rotateToken(userID string) (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+	s.dropToken(userID)
+	s.tokens[token] = userID
+	s.userTokens[userID] = token
+	s.sessions[token] = Session{TokenPrefix: token[:sessionTokenPrefix], CreatedAt: now()}
+	return token, nil
+}
+
+// dropToken ends the user's session, if any, and reports whether there was one. Callers must hold s.mu.
+func (s *Store) dropToken(userID string) bool {
+	old := s.userTokens[userID]
+	if old == "" {
+		return false
+	}
+	delete(s.tokens, old)
+	delete(s.sessions, old)
+	delete(s.userTokens, userID)
+	return true
+}
+
+// accountOf returns the login account for a user. Callers must hold s.mu.
+func (s *Store) accountOf(userID string) string {
+	for account, id := range s.accounts {
+		if id == userID {
+			return account
+		}
+	}
+	return ""
+}
+
+func (s *Store) userRecord(user User) UserRecord {
+	return UserRecord{
+		ID:        user.ID,
+		Nickname:  user.Nickname,
+		Account:   s.accountOf(user.ID),
+		Role:      user.Role,
+		CreatedAt: user.CreatedAt,
+	}
+}
+
+// SearchUsers matches query against user IDs (exact) and nicknames/accounts (substring),
+// case-insensitively; an empty query lists every user. Newest users come first.
+func (s *Store) SearchUsers(query string, page, pageSize int) ([]UserRecord, int, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if old := s.userTokens[userID]; old != "" {
-		delete(s.tokens, old)
+	var matched []UserRecord
+	for _, user := range s.users {
+		record := s.userRecord(user)
+		if query == "" ||
+			strings.ToLower(record.ID) == query ||
+			strings.Contains(strings.ToLower(record.Nickname), query) ||
+			strings.Contains(strings.ToLower(record.Account), query) {
+			matched = append(matched, record)
+		}
 	}
-	s.tokens[token] = userID
-	s.userTokens[userID] = token
+	// IDs are u_<seq>, so creation order is numeric ID order.
+	sort.Slice(matched, func(i, j int) bool {
+		return IDSeq(matched[i].ID) > IDSeq(matched[j].ID)
+	})
+	start, end := pageBounds(len(matched), page, pageSize)
+	out := make([]UserRecord, end-start)
+	copy(out, matched[start:end])
+	return out, len(matched), nil
+}
 
-	return token, user, nil
+// GetUserRecord returns the admin view of a user.
+func (s *Store) GetUserRecord(userID string) (UserRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return UserRecord{}, false
+	}
+	return s.userRecord(user), true
+}
+
+// SetUserRole changes a user's role.
+func (s *Store) SetUserRole(userID, role string) error {
+	if !ValidRole(role) {
+		return ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return ErrNotFound
+	}
+	user.Role = role
+	s.users[userID] = user
+	return nil
+}
+
+// SetPassword replaces the password of the user's account. Existing sessions are kept;
+// callers that want to log the user out use RevokeSessions.
+func (s *Store) SetPassword(userID, password string) error {
+	password = strings.TrimSpace(password)
+	if password == "" {
+		return ErrInvalidInput
+	}
+	passwordHash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account := s.accountOf(userID)
+	if account == "" {
+		return ErrNotFound
+	}
+	s.passwords[account] = passwordHash
+	return nil
+}
+
+// UserSessions lists the user's active sessions.
+func (s *Store) UserSessions(userID string) []Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token := s.userTokens[userID]
+	if token == "" {
+		return nil
+	}
+	return []Session{s.sessions[token]}
+}
+
+// RevokeSessions logs the user out everywhere and returns how many sessions were ended.
+func (s *Store) RevokeSessions(userID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[userID]; !ok {
+		return 0, ErrNotFound
+	}
+	if s.dropToken(userID) {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// TouchSession records that token was just used from ip.
+func (s *Store) TouchSession(token, ip string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	userID, ok := s.tokens[token]
+	if !ok {
+		return ErrNotFound
+	}
+	ts := now()
+	session := s.sessions[token]
+	session.LastSeenAt = ts
+	session.LastIP = ip
+	s.sessions[token] = session
+
+	if ip == "" {
+		return nil
+	}
+	ips := s.userIPs[userID]
+	for i := range ips {
+		if ips[i].IP == ip {
+			ips[i].LastSeenAt = ts
+			return nil
+		}
+	}
+	s.userIPs[userID] = append(ips, UserIP{IP: ip, FirstSeenAt: ts, LastSeenAt: ts})
+	return nil
+}
+
+// UserIPs returns up to limit addresses the user was seen from, most recent first.
+func (s *Store) UserIPs(userID string, limit int) []UserIP {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := append([]UserIP(nil), s.userIPs[userID]...)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].LastSeenAt > out[j].LastSeenAt })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// UserPosts lists a user's posts newest first, including deleted and hidden ones.
+func (s *Store) UserPosts(authorID string, page, pageSize int) ([]Post, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var matched []Post
+	for i := len(s.posts) - 1; i >= 0; i-- {
+		if s.posts[i].AuthorID == authorID {
+			matched = append(matched, s.posts[i])
+		}
+	}
+	start, end := pageBounds(len(matched), page, pageSize)
+	out := make([]Post, end-start)
+	copy(out, matched[start:end])
+	return out, len(matched), nil
+}
+
+// UserComments lists a user's comments newest first, including deleted and hidden ones.
+func (s *Store) UserComments(authorID string, page, pageSize int) ([]Comment, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var matched []Comment
+	for i := len(s.comments) - 1; i >= 0; i-- {
+		if s.comments[i].AuthorID == authorID {
+			matched = append(matched, s.comments[i])
+		}
+	}
+	start, end := pageBounds(len(matched), page, pageSize)
+	out := make([]Comment, end-start)
+	copy(out, matched[start:end])
+	return out, len(matched), nil
+}
+
+// ReportsAgainst lists reports whose target was authored by (or is) the user, newest first.
+func (s *Store) ReportsAgainst(userID string, page, pageSize int) ([]Report, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var matched []Report
+	for i := len(s.reports) - 1; i >= 0; i-- {
+		if s.reports[i].TargetUserID == userID {
+			matched = append(matched, s.reports[i])
+		}
+	}
+	start, end := pageBounds(len(matched), page, pageSize)
+	out := make([]Report, end-start)
+	copy(out, matched[start:end])
+	return out, len(matched), nil
 }
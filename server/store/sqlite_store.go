@@ -2,9 +2,11 @@ package store
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"path/filepath"
 	"strings"
 	"time"
@@ -54,6 +56,10 @@ func OpenSQLite(path string) (*SQLiteStore, error) {
 		_ = db.Close()
 		return nil, err
 	}
+	if err := s.seedChatRooms(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
 	return s, nil
 }
 
@@ -72,6 +78,7 @@ func (s *SQLiteStore) migrate() error {
 			seq INTEGER NOT NULL,
 			id TEXT PRIMARY KEY,
 			nickname TEXT NOT NULL,
+			role TEXT NOT NULL DEFAULT 'user',
 			created_at TEXT NOT NULL
 		);`,
 		`CREATE TABLE IF NOT EXISTS accounts (
@@ -81,15 +88,63 @@ func (s *SQLiteStore) migrate() error {
 		);`,
 		`CREATE TABLE IF NOT EXISTS tokens (
 			token TEXT PRIMARY KEY,
-			user_id TEXT NOT NULL UNIQUE
+			user_id TEXT NOT NULL UNIQUE,
+			created_at TEXT NOT NULL DEFAULT '',
+			last_seen_at TEXT NOT NULL DEFAULT '',
+			last_ip TEXT NOT NULL DEFAULT ''
+		);`,
+		`CREATE TABLE IF NOT EXISTS user_ips (
+			user_id TEXT NOT NULL,
+			ip TEXT NOT NULL,
+			first_seen_at TEXT NOT NULL,
+			last_seen_at TEXT NOT NULL,
+			PRIMARY KEY (user_id, ip)
 		);`,
 
 		`CREATE TABLE IF NOT EXISTS boards (
 			seq INTEGER NOT NULL,
 			id TEXT PRIMARY KEY,
 			name TEXT NOT NULL,
-			description TEXT NOT NULL
+			description TEXT NOT NULL,
+			icon TEXT NOT NULL DEFAULT '',
+			rules TEXT NOT NULL DEFAULT '',
+			qq_group TEXT NOT NULL DEFAULT '',
+			archived INTEGER NOT NULL DEFAULT 0,
+			read_only INTEGER NOT NULL DEFAULT 0
+		);`,
+		`CREATE TABLE IF NOT EXISTS board_applications (
+			seq INTEGER NOT NULL,
+			id TEXT PRIMARY KEY,
+			applicant_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			description TEXT NOT NULL,
+			icon TEXT NOT NULL,
+			rules TEXT NOT NULL,
+			qq_group TEXT NOT NULL,
+			reason TEXT NOT NULL,
+			status TEXT NOT NULL,
+			review_note TEXT NOT NULL,
+			reviewed_by TEXT NOT NULL,
+			board_id TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			updated_at TEXT NOT NULL
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_board_applications_status_seq ON board_applications(status, seq);`,
+		`CREATE TABLE IF NOT EXISTS board_subscriptions (
+			board_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			PRIMARY KEY (board_id, user_id)
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_board_subscriptions_user ON board_subscriptions(user_id);`,
+		`CREATE TABLE IF NOT EXISTS board_moderators (
+			board_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			granted_by TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			PRIMARY KEY (board_id, user_id)
 		);`,
+		`CREATE INDEX IF NOT EXISTS idx_board_moderators_user ON board_moderators(user_id);`,
 		`CREATE TABLE IF NOT EXISTS posts (
 			seq INTEGER NOT NULL,
 			id TEXT PRIMARY KEY,
@@ -98,7 +153,10 @@ func (s *SQLiteStore) migrate() error {
 			title TEXT NOT NULL,
 			content TEXT NOT NULL,
 			created_at TEXT NOT NULL,
-			deleted_at TEXT
+			deleted_at TEXT,
+			pinned_at TEXT,
+			locked_at TEXT,
+			hidden_at TEXT
 		);`,
 		`CREATE INDEX IF NOT EXISTS idx_posts_board_seq ON posts(board_id, seq);`,
 		`CREATE TABLE IF NOT EXISTS comments (
@@ -109,7 +167,8 @@ func (s *SQLiteStore) migrate() error {
 			author_id TEXT NOT NULL,
 			content TEXT NOT NULL,
 			created_at TEXT NOT NULL,
-			deleted_at TEXT
+			deleted_at TEXT,
+			hidden_at TEXT
 		);`,
 		`CREATE TABLE IF NOT EXISTS post_votes (
 			post_id TEXT NOT NULL,
@@ -130,23 +189,46 @@ func (s *SQLiteStore) migrate() error {
 		`CREATE INDEX IF NOT EXISTS idx_comment_votes_post ON comment_votes(post_id);`,
 		`CREATE INDEX IF NOT EXISTS idx_comments_post_seq ON comments(post_id, seq);`,
 
+		`CREATE TABLE IF NOT EXISTS bookmarks (
+			user_id TEXT NOT NULL,
+			post_id TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			PRIMARY KEY (user_id, post_id)
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_bookmarks_post ON bookmarks(post_id);`,
+
 		`CREATE TABLE IF NOT EXISTS files (
 			seq INTEGER NOT NULL,
 			id TEXT PRIMARY KEY,
 			uploader_id TEXT NOT NULL,
 			filename TEXT NOT NULL,
+			content_type TEXT NOT NULL DEFAULT '',
+			size INTEGER NOT NULL DEFAULT 0,
 			storage_key TEXT NOT NULL,
 			storage_path TEXT NOT NULL,
+			post_id TEXT,
+			comment_id TEXT,
 			created_at TEXT NOT NULL
 		);`,
 
+		`CREATE TABLE IF NOT EXISTS chat_rooms (
+			seq INTEGER NOT NULL,
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			description TEXT NOT NULL,
+			visibility TEXT NOT NULL,
+			owner_id TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			archived_at TEXT NOT NULL DEFAULT ''
+		);`,
 		`CREATE TABLE IF NOT EXISTS messages (
 			seq INTEGER NOT NULL,
 			id TEXT PRIMARY KEY,
 			room_id TEXT NOT NULL,
 			sender_id TEXT NOT NULL,
 			content TEXT NOT NULL,
-			created_at TEXT NOT NULL
+			created_at TEXT NOT NULL,
+			deleted_at TEXT
 		);`,
 		`CREATE INDEX IF NOT EXISTS idx_messages_room_seq ON messages(room_id, seq);`,
 
@@ -155,6 +237,9 @@ func (s *SQLiteStore) migrate() error {
 			id TEXT PRIMARY KEY,
 			target_type TEXT NOT NULL,
 			target_id TEXT NOT NULL,
+			board_id TEXT NOT NULL DEFAULT '',
+			target_user_id TEXT NOT NULL DEFAULT '',
+			snapshot TEXT NOT NULL DEFAULT '',
 			reporter_id TEXT NOT NULL,
 			reason TEXT NOT NULL,
 			detail TEXT NOT NULL,
@@ -162,10 +247,110 @@ func (s *SQLiteStore) migrate() error {
 			action TEXT NOT NULL,
 			note TEXT NOT NULL,
 			handled_by TEXT NOT NULL,
+			outcome TEXT NOT NULL DEFAULT '',
 			created_at TEXT NOT NULL,
 			updated_at TEXT NOT NULL
 		);`,
 		`CREATE INDEX IF NOT EXISTS idx_reports_status_seq ON reports(status, seq);`,
+		`CREATE INDEX IF NOT EXISTS idx_reports_target ON reports(target_type, target_id);`,
+
+		`CREATE TABLE IF NOT EXISTS filter_words (
+			seq INTEGER NOT NULL,
+			id TEXT PRIMARY KEY,
+			word TEXT NOT NULL,
+			category TEXT NOT NULL,
+			created_by TEXT NOT NULL,
+			created_at TEXT NOT NULL
+		);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_filter_words_word ON filter_words(word COLLATE NOCASE);`,
+		`CREATE TABLE IF NOT EXISTS filter_categories (
+			name TEXT PRIMARY KEY,
+			action TEXT NOT NULL
+		);`,
+
+		`CREATE TABLE IF NOT EXISTS user_activity (
+			user_id TEXT NOT NULL,
+			day TEXT NOT NULL,
+			PRIMARY KEY (day, user_id)
+		);`,
+
+		`CREATE TABLE IF NOT EXISTS sanctions (
+			seq INTEGER NOT NULL,
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			type TEXT NOT NULL,
+			reason TEXT NOT NULL,
+			report_id TEXT NOT NULL,
+			created_by TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			expires_at TEXT NOT NULL,
+			revoked_at TEXT NOT NULL
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_sanctions_user_seq ON sanctions(user_id, seq);`,
+
+		`CREATE TABLE IF NOT EXISTS appeals (
+			seq INTEGER NOT NULL,
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			target_type TEXT NOT NULL,
+			target_id TEXT NOT NULL,
+			reason TEXT NOT NULL,
+			status TEXT NOT NULL,
+			note TEXT NOT NULL DEFAULT '',
+			handled_by TEXT NOT NULL DEFAULT '',
+			outcome TEXT NOT NULL DEFAULT '',
+			created_at TEXT NOT NULL,
+			updated_at TEXT NOT NULL
+		);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_appeals_target ON appeals(target_type, target_id);`,
+		`CREATE INDEX IF NOT EXISTS idx_appeals_status_seq ON appeals(status, seq);`,
+
+		`CREATE TABLE IF NOT EXISTS conversations (
+			seq INTEGER NOT NULL,
+			id TEXT PRIMARY KEY,
+			kind TEXT NOT NULL,
+			title TEXT NOT NULL DEFAULT '',
+			creator_id TEXT NOT NULL,
+			direct_key TEXT NOT NULL DEFAULT '',
+			created_at TEXT NOT NULL
+		);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_conversations_direct ON conversations(direct_key) WHERE direct_key != '';`,
+		`CREATE TABLE IF NOT EXISTS conversation_members (
+			conversation_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			last_read_seq INTEGER NOT NULL DEFAULT 0,
+			joined_at TEXT NOT NULL,
+			PRIMARY KEY(conversation_id, user_id)
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_conversation_members_user ON conversation_members(user_id);`,
+		`CREATE TABLE IF NOT EXISTS user_blocks (
+			user_id TEXT NOT NULL,
+			blocked_id TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			PRIMARY KEY(user_id, blocked_id)
+		);`,
+
+		`CREATE TABLE IF NOT EXISTS audit_log (
+			seq INTEGER NOT NULL,
+			id TEXT PRIMARY KEY,
+			actor_id TEXT NOT NULL,
+			action TEXT NOT NULL,
+			target_type TEXT NOT NULL,
+			target_id TEXT NOT NULL,
+			board_id TEXT NOT NULL,
+			reason TEXT NOT NULL,
+			before_state TEXT,
+			after_state TEXT,
+			ip TEXT NOT NULL DEFAULT '',
+			created_at TEXT NOT NULL
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_audit_log_board_seq ON audit_log(board_id, seq);`,
+		`CREATE INDEX IF NOT EXISTS idx_audit_log_actor_seq ON audit_log(actor_id, seq);`,
+		`CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at);`,
+		`CREATE TRIGGER IF NOT EXISTS audit_log_no_update BEFORE UPDATE ON audit_log
+		 BEGIN SELECT RAISE(ABORT, 'audit_log is append-only'); END;`,
+		`CREATE TRIGGER IF NOT EXISTS audit_log_no_delete BEFORE DELETE ON audit_log
+		 BEGIN SELECT RAISE(ABORT, 'audit_log is append-only'); END;`,
 	}
 
 	for _, stmt := range stmts {
@@ -181,6 +366,20 @@ func (s *SQLiteStore) migrate() error {
 		}
 	}
 
+	// Backward compatible migrations for databases created before admin user management.
+	for _, stmt := range []string{
+		`ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'user';`,
+		`ALTER TABLE tokens ADD COLUMN created_at TEXT NOT NULL DEFAULT '';`,
+		`ALTER TABLE tokens ADD COLUMN last_seen_at TEXT NOT NULL DEFAULT '';`,
+		`ALTER TABLE tokens ADD COLUMN last_ip TEXT NOT NULL DEFAULT '';`,
+	} {
+		if _, err := s.db.Exec(stmt); err != nil {
+			if !isSQLiteDuplicateColumnError(err) {
+				return err
+			}
+		}
+	}
+
 	// Legacy databases may contain demo tokens for accounts without passwords.
 	// Drop those tokens so users must register (set a password) before using the API.
 	_, _ = s.db.Exec(
@@ -213,6 +412,63 @@ func (s *SQLiteStore) migrate() error {
 			return err
 		}
 	}
+
+	// Backward compatible migrations for databases created before post/comment attachments.
+	for _, stmt := range []string{
+		`ALTER TABLE files ADD COLUMN content_type TEXT NOT NULL DEFAULT '';`,
+		`ALTER TABLE files ADD COLUMN size INTEGER NOT NULL DEFAULT 0;`,
+		`ALTER TABLE files ADD COLUMN post_id TEXT;`,
+		`ALTER TABLE files ADD COLUMN comment_id TEXT;`,
+	} {
+		if _, err := s.db.Exec(stmt); err != nil {
+			if !isSQLiteDuplicateColumnError(err) {
+				return err
+			}
+		}
+	}
+	if _, err := s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_files_post ON files(post_id, comment_id);`); err != nil {
+		return err
+	}
+
+	// Backward compatible migrations for databases created before board management.
+	for _, stmt := range []string{
+		`ALTER TABLE boards ADD COLUMN icon TEXT NOT NULL DEFAULT '';`,
+		`ALTER TABLE boards ADD COLUMN rules TEXT NOT NULL DEFAULT '';`,
+		`ALTER TABLE boards ADD COLUMN qq_group TEXT NOT NULL DEFAULT '';`,
+		`ALTER TABLE boards ADD COLUMN archived INTEGER NOT NULL DEFAULT 0;`,
+		`ALTER TABLE boards ADD COLUMN read_only INTEGER NOT NULL DEFAULT 0;`,
+	} {
+		if _, err := s.db.Exec(stmt); err != nil {
+			if !isSQLiteDuplicateColumnError(err) {
+				return err
+			}
+		}
+	}
+
+	// Backward compatible migrations for databases created before board moderation.
+	for _, stmt := range []string{
+		`ALTER TABLE posts ADD COLUMN pinned_at TEXT;`,
+		`ALTER TABLE posts ADD COLUMN locked_at TEXT;`,
+		`ALTER TABLE posts ADD COLUMN hidden_at TEXT;`,
+		`ALTER TABLE comments ADD COLUMN hidden_at TEXT;`,
+		`ALTER TABLE reports ADD COLUMN board_id TEXT NOT NULL DEFAULT '';`,
+		`ALTER TABLE reports ADD COLUMN outcome TEXT NOT NULL DEFAULT '';`,
+		`ALTER TABLE reports ADD COLUMN target_user_id TEXT NOT NULL DEFAULT '';`,
+		`ALTER TABLE reports ADD COLUMN snapshot TEXT NOT NULL DEFAULT '';`,
+		`ALTER TABLE audit_log ADD COLUMN before_state TEXT;`,
+		`ALTER TABLE audit_log ADD COLUMN after_state TEXT;`,
+		`ALTER TABLE audit_log ADD COLUMN ip TEXT NOT NULL DEFAULT '';`,
+		`ALTER TABLE messages ADD COLUMN deleted_at TEXT;`,
+	} {
+		if _, err := s.db.Exec(stmt); err != nil {
+			if !isSQLiteDuplicateColumnError(err) {
+				return err
+			}
+		}
+	}
+	if _, err := s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_reports_board_seq ON reports(board_id, seq);`); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -239,13 +495,54 @@ func nullStringOrValue(value string) any {
 	return value
 }
 
+// seedChatRooms makes sure the default rooms exist and registers rooms that only exist
+// implicitly in message history (created before the room registry) as public rooms.
+// Post rooms are derived from their post and conversations have their own table; neither is
+// registered.
+func (s *SQLiteStore) seedChatRooms() error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	ts := nowRFC3339()
+	for _, room := range defaultChatRooms() {
+		if _, err := tx.Exec(
+			`INSERT OR IGNORE INTO chat_rooms(seq, id, name, description, visibility, owner_id, created_at)
+			 VALUES(0, ?, ?, ?, ?, ?, ?);`,
+			room.ID,
+			room.Name,
+			room.Description,
+			room.Visibility,
+			room.OwnerID,
+			ts,
+		); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(
+		`INSERT OR IGNORE INTO chat_rooms(seq, id, name, description, visibility, owner_id, created_at)
+		 SELECT 0, room_id, room_id, '', ?, 'system', MIN(created_at) FROM messages
+		 WHERE substr(room_id, 1, ?) != ? AND substr(room_id, 1, ?) != ? GROUP BY room_id;`,
+		RoomPublic,
+		len(postRoomPrefix),
+		postRoomPrefix,
+		len(conversationPrefix),
+		conversationPrefix,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 func (s *SQLiteStore) seedBoards() error {
 	var count int
 	if err := s.db.QueryRow(`SELECT COUNT(*) FROM boards;`).Scan(&count); err != nil {
 		return err
 	}
 	if count > 0 {
-		return nil
+		return s.syncBoardCounter()
 	}
 
 	tx, err := s.db.Begin()
@@ -270,7 +567,18 @@ func (s *SQLiteStore) seedBoards() error {
 		return err
 	}
 	log.Printf("seed boards inserted")
-	return nil
+	return s.syncBoardCounter()
+}
+
+// syncBoardCounter keeps the "board" counter ahead of seeded boards, which were
+// inserted with explicit seq values before boards could be created at runtime.
+func (s *SQLiteStore) syncBoardCounter() error {
+	_, err := s.db.Exec(
+		`INSERT INTO counters(name, value)
+		 SELECT 'board', COALESCE(MAX(seq), 0) FROM boards WHERE 1
+		 ON CONFLICT(name) DO UPDATE SET value = MAX(value, excluded.value);`,
+	)
+	return err
 }
 
 func (s *SQLiteStore) nextCounter(tx *sql.Tx, name string) (int, error) {
@@ -302,7 +610,7 @@ func (s *SQLiteStore) rotateToken(tx *sql.Tx, userID string) (string, error) {
 		if err != nil {
 			return "", err
 		}
-		if _, err := tx.Exec(`INSERT INTO tokens(token, user_id) VALUES(?, ?);`, token, userID); err != nil {
+		if _, err := tx.Exec(`INSERT INTO tokens(token, user_id, created_at) VALUES(?, ?, ?);`, token, userID, nowRFC3339()); err != nil {
 			lastErr = err
 			if isSQLiteConstraintError(err) {
 				continue
@@ -352,14 +660,16 @@ func (s *SQLiteStore) Register(account, password string) (string, User, error) {
 		user = User{
 			ID:        fmt.Sprintf("u_%d", seq),
 			Nickname:  trimmedAccount,
+			Role:      RoleUser,
 			CreatedAt: nowRFC3339(),
 		}
 
 		if _, err := tx.Exec(
-			`INSERT INTO users(seq, id, nickname, created_at) VALUES(?, ?, ?, ?);`,
+			`INSERT INTO users(seq, id, nickname, role, created_at) VALUES(?, ?, ?, ?, ?);`,
 			seq,
 			user.ID,
 			user.Nickname,
+			user.Role,
 			user.CreatedAt,
 		); err != nil {
 			return "", User{}, err
@@ -380,8 +690,8 @@ func (s *SQLiteStore) Register(account, password string) (string, User, error) {
 		if _, err := tx.Exec(`UPDATE accounts SET password_hash = ? WHERE account = ?;`, passwordHash, trimmedAccount); err != nil {
 			return "", User{}, err
 		}
-		if err := tx.QueryRow(`SELECT id, nickname, created_at FROM users WHERE id = ?;`, userID).
-			Scan(&user.ID, &user.Nickname, &user.CreatedAt); err != nil {
+		if err := tx.QueryRow(`SELECT id, nickname, role, created_at FROM users WHERE id = ?;`, userID).
+			Scan(&user.ID, &user.Nickname, &user.Role, &user.CreatedAt); err != nil {
 			return "", User{}, err
 		}
 	}
@@ -415,12 +725,12 @@ func (s *SQLiteStore) Login(account, password string) (string, User, error) {
 		passwordHash sql.NullString
 	)
 	err = tx.QueryRow(
-		`SELECT u.id, u.nickname, u.created_at, a.password_hash
+		`SELECT u.id, u.nickname, u.role, u.created_at, a.password_hash
 		 FROM accounts a
 		 JOIN users u ON u.id = a.user_id
 		 WHERE a.account = ?;`,
 		trimmedAccount,
-	).Scan(&user.ID, &user.Nickname, &user.CreatedAt, &passwordHash)
+	).Scan(&user.ID, &user.Nickname, &user.Role, &user.CreatedAt, &passwordHash)
 	if errors.Is(err, sql.ErrNoRows) {
 		return "", User{}, ErrInvalidCredentials
 	}
@@ -446,12 +756,12 @@ func (s *SQLiteStore) Login(account, password string) (string, User, error) {
 func (s *SQLiteStore) UserByToken(token string) (User, bool) {
 	var user User
 	err := s.db.QueryRow(
-		`SELECT u.id, u.nickname, u.created_at
+		`SELECT u.id, u.nickname, u.role, u.created_at
 		 FROM users u
 		 JOIN tokens t ON t.user_id = u.id
 		 WHERE t.token = ?;`,
 		token,
-	).Scan(&user.ID, &user.Nickname, &user.CreatedAt)
+	).Scan(&user.ID, &user.Nickname, &user.Role, &user.CreatedAt)
 	if err != nil {
 		return User{}, false
 	}
@@ -460,831 +770,3416 @@ func (s *SQLiteStore) UserByToken(token string) (User, bool) {
 
 func (s *SQLiteStore) GetUser(userID string) (User, bool) {
 	var user User
-	if err := s.db.QueryRow(`SELECT id, nickname, created_at FROM users WHERE id = ?;`, userID).
-		Scan(&user.ID, &user.Nickname, &user.CreatedAt); err != nil {
+	if err := s.db.QueryRow(`SELECT id, nickname, role, created_at FROM users WHERE id = ?;`, userID).
+		Scan(&user.ID, &user.Nickname, &user.Role, &user.CreatedAt); err != nil {
 		return User{}, false
 	}
 	return user, true
 }
 
-func (s *SQLiteStore) Boards() []Board {
-	rows, err := s.db.Query(`SELECT id, name, description FROM boards ORDER BY seq ASC;`)
+// userRecordColumns selects a UserRecord from users u LEFT JOIN accounts a.
+const userRecordColumns = `u.id, u.nickname, COALESCE(a.account, ''), u.role, u.created_at`
+
+func scanUserRecord(row interface{ Scan(dest ...any) error }) (UserRecord, error) {
+	var u UserRecord
+	if err := row.Scan(&u.ID, &u.Nickname, &u.Account, &u.Role, &u.CreatedAt); err != nil {
+		return UserRecord{}, err
+	}
+	return u, nil
+}
+
+func (s *SQLiteStore) SearchUsers(query string, page, pageSize int) ([]UserRecord, int, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	clause := "1 = 1"
+	var args []any
+	if trimmed := strings.TrimSpace(query); trimmed != "" {
+		// LIKE is case-insensitive for ASCII in SQLite; escape its wildcards in the query.
+		pattern := "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(trimmed) + "%"
+		clause = `(u.id = ? COLLATE NOCASE OR u.nickname LIKE ? ESCAPE '\' OR a.account LIKE ? ESCAPE '\')`
+		args = append(args, trimmed, pattern, pattern)
+	}
+
+	var total int
+	if err := s.db.QueryRow(
+		`SELECT COUNT(*) FROM users u LEFT JOIN accounts a ON a.user_id = u.id WHERE `+clause+`;`,
+		args...,
+	).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.Query(
+		`SELECT `+userRecordColumns+`
+		 FROM users u
+		 LEFT JOIN accounts a ON a.user_id = u.id
+		 WHERE `+clause+`
+		 ORDER BY u.seq DESC
+		 LIMIT ? OFFSET ?;`,
+		append(args, pageSize, (page-1)*pageSize)...,
+	)
 	if err != nil {
-		return nil
+		return nil, 0, err
 	}
 	defer rows.Close()
 
-	var out []Board
+	out := make([]UserRecord, 0, pageSize)
 	for rows.Next() {
-		var b Board
-		if err := rows.Scan(&b.ID, &b.Name, &b.Description); err != nil {
-			return nil
+		u, err := scanUserRecord(rows)
+		if err != nil {
+			return nil, 0, err
 		}
-		out = append(out, b)
+		out = append(out, u)
 	}
-	return out
+	return out, total, rows.Err()
 }
 
-func (s *SQLiteStore) GetBoard(boardID string) (Board, bool) {
-	var board Board
-	err := s.db.QueryRow(`SELECT id, name, description FROM boards WHERE id = ?;`, boardID).
-		Scan(&board.ID, &board.Name, &board.Description)
+func (s *SQLiteStore) GetUserRecord(userID string) (UserRecord, bool) {
+	u, err := scanUserRecord(s.db.QueryRow(
+		`SELECT `+userRecordColumns+` FROM users u LEFT JOIN accounts a ON a.user_id = u.id WHERE u.id = ?;`,
+		userID,
+	))
 	if err != nil {
-		return Board{}, false
+		return UserRecord{}, false
 	}
-	return board, true
+	return u, true
 }
 
-func (s *SQLiteStore) Posts(boardID string) []Post {
-	var (
-		rows *sql.Rows
-		err  error
-	)
-	if boardID == "" {
-		rows, err = s.db.Query(
-			`SELECT id, board_id, author_id, title, content, created_at
-			 FROM posts
-			 WHERE deleted_at IS NULL OR TRIM(deleted_at) = ''
-			 ORDER BY seq ASC;`,
-		)
-	} else {
-		rows, err = s.db.Query(
-			`SELECT id, board_id, author_id, title, content, created_at
-			 FROM posts
-			 WHERE board_id = ?
-			   AND (deleted_at IS NULL OR TRIM(deleted_at) = '')
-			 ORDER BY seq ASC;`,
-			boardID,
-		)
+func (s *SQLiteStore) SetUserRole(userID, role string) error {
+	if !ValidRole(role) {
+		return ErrInvalidInput
+	}
+	res, err := s.db.Exec(`UPDATE users SET role = ? WHERE id = ?;`, role, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStore) SetPassword(userID, password string) error {
+	password = strings.TrimSpace(password)
+	if password == "" {
+		return ErrInvalidInput
+	}
+	passwordHash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	res, err := s.db.Exec(`UPDATE accounts SET password_hash = ? WHERE user_id = ?;`, passwordHash, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
 	}
+	return nil
+}
+
+func (s *SQLiteStore) UserSessions(userID string) []Session {
+	rows, err := s.db.Query(
+		`SELECT substr(token, 1, ?), created_at, last_seen_at, last_ip FROM tokens WHERE user_id = ?;`,
+		sessionTokenPrefix,
+		userID,
+	)
 	if err != nil {
 		return nil
 	}
 	defer rows.Close()
 
-	var out []Post
+	var out []Session
 	for rows.Next() {
-		var p Post
-		if err := rows.Scan(&p.ID, &p.BoardID, &p.AuthorID, &p.Title, &p.Content, &p.CreatedAt); err != nil {
+		var session Session
+		if err := rows.Scan(&session.TokenPrefix, &session.CreatedAt, &session.LastSeenAt, &session.LastIP); err != nil {
 			return nil
 		}
-		out = append(out, p)
+		out = append(out, session)
 	}
 	return out
 }
 
-func (s *SQLiteStore) GetPost(postID string) (Post, bool) {
-	var post Post
-	var deletedAt sql.NullString
-	err := s.db.QueryRow(
-		`SELECT id, board_id, author_id, title, content, created_at, deleted_at
-		 FROM posts
-		 WHERE id = ?
-		   AND (deleted_at IS NULL OR TRIM(deleted_at) = '');`,
-		postID,
-	).Scan(&post.ID, &post.BoardID, &post.AuthorID, &post.Title, &post.Content, &post.CreatedAt, &deletedAt)
+func (s *SQLiteStore) RevokeSessions(userID string) (int, error) {
+	if _, ok := s.GetUser(userID); !ok {
+		return 0, ErrNotFound
+	}
+	res, err := s.db.Exec(`DELETE FROM tokens WHERE user_id = ?;`, userID)
 	if err != nil {
-		return Post{}, false
+		return 0, err
 	}
-	post.DeletedAt = strings.TrimSpace(deletedAt.String)
-	return post, true
+	n, err := res.RowsAffected()
+	return int(n), err
 }
 
-func (s *SQLiteStore) CreatePost(boardID, authorID, title, content string) Post {
+func (s *SQLiteStore) TouchSession(token, ip string) error {
 	tx, err := s.db.Begin()
 	if err != nil {
-		return Post{}
+		return err
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	seq, err := s.nextCounter(tx, "post")
-	if err != nil {
-		return Post{}
-	}
-
-	post := Post{
-		ID:        fmt.Sprintf("p_%d", seq),
-		BoardID:   boardID,
-		AuthorID:  authorID,
-		Title:     title,
-		Content:   content,
-		CreatedAt: nowRFC3339(),
+	var userID string
+	if err := tx.QueryRow(`SELECT user_id FROM tokens WHERE token = ?;`, token).Scan(&userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
 	}
-
-	if _, err := tx.Exec(
-		`INSERT INTO posts(seq, id, board_id, author_id, title, content, created_at, deleted_at)
-		 VALUES(?, ?, ?, ?, ?, ?, ?, NULL);`,
-		seq,
-		post.ID,
-		post.BoardID,
-		post.AuthorID,
-		post.Title,
-		post.Content,
-		post.CreatedAt,
-	); err != nil {
-		return Post{}
+	ts := nowRFC3339()
+	if _, err := tx.Exec(`UPDATE tokens SET last_seen_at = ?, last_ip = ? WHERE token = ?;`, ts, ip, token); err != nil {
+		return err
 	}
-
-	if err := tx.Commit(); err != nil {
-		return Post{}
+	if ip != "" {
+		if _, err := tx.Exec(
+			`INSERT INTO user_ips(user_id, ip, first_seen_at, last_seen_at) VALUES(?, ?, ?, ?)
+			 ON CONFLICT(user_id, ip) DO UPDATE SET last_seen_at = excluded.last_seen_at;`,
+			userID,
+			ip,
+			ts,
+			ts,
+		); err != nil {
+			return err
+		}
 	}
-	return post
+	return tx.Commit()
 }
 
-func (s *SQLiteStore) SoftDeletePost(postID, actorUserID string) error {
-	tx, err := s.db.Begin()
+func (s *SQLiteStore) UserIPs(userID string, limit int) []UserIP {
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := s.db.Query(
+		`SELECT ip, first_seen_at, last_seen_at FROM user_ips WHERE user_id = ? ORDER BY last_seen_at DESC LIMIT ?;`,
+		userID,
+		limit,
+	)
 	if err != nil {
-		return err
+		return nil
 	}
-	defer func() { _ = tx.Rollback() }()
+	defer rows.Close()
 
-	var authorID string
-	var deletedAt sql.NullString
-	err = tx.QueryRow(`SELECT author_id, deleted_at FROM posts WHERE id = ?;`, postID).Scan(&authorID, &deletedAt)
-	if errors.Is(err, sql.ErrNoRows) {
-		return ErrNotFound
+	var out []UserIP
+	for rows.Next() {
+		var ip UserIP
+		if err := rows.Scan(&ip.IP, &ip.FirstSeenAt, &ip.LastSeenAt); err != nil {
+			return nil
+		}
+		out = append(out, ip)
 	}
-	if err != nil {
-		return err
+	return out
+}
+
+func (s *SQLiteStore) UserPosts(authorID string, page, pageSize int) ([]Post, int, error) {
+	if page <= 0 {
+		page = 1
 	}
-	if strings.TrimSpace(deletedAt.String) != "" {
-		return ErrNotFound
+	if pageSize <= 0 {
+		pageSize = 20
 	}
-	if authorID != actorUserID {
-		return ErrForbidden
+
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM posts WHERE author_id = ?;`, authorID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := s.db.Query(
+		`SELECT `+postColumns+` FROM posts WHERE author_id = ? ORDER BY seq DESC LIMIT ? OFFSET ?;`,
+		authorID,
+		pageSize,
+		(page-1)*pageSize,
+	)
+	if err != nil {
+		return nil, 0, err
 	}
+	defer rows.Close()
 
-	if _, err := tx.Exec(`UPDATE posts SET deleted_at = ? WHERE id = ?;`, nowRFC3339(), postID); err != nil {
-		return err
+	out := make([]Post, 0, pageSize)
+	for rows.Next() {
+		p, err := scanPost(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		out = append(out, p)
 	}
-	return tx.Commit()
+	return out, total, rows.Err()
 }
 
-func (s *SQLiteStore) Comments(postID string) []Comment {
+func (s *SQLiteStore) UserComments(authorID string, page, pageSize int) ([]Comment, int, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM comments WHERE author_id = ?;`, authorID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
 	rows, err := s.db.Query(
-		`SELECT id, post_id, parent_id, author_id, content, created_at
+		`SELECT id, post_id, parent_id, author_id, content, created_at, deleted_at, hidden_at
 		 FROM comments
-		 WHERE post_id = ?
-		   AND (deleted_at IS NULL OR TRIM(deleted_at) = '')
-		 ORDER BY seq ASC;`,
-		postID,
+		 WHERE author_id = ?
+		 ORDER BY seq DESC
+		 LIMIT ? OFFSET ?;`,
+		authorID,
+		pageSize,
+		(page-1)*pageSize,
 	)
 	if err != nil {
-		return nil
+		return nil, 0, err
 	}
 	defer rows.Close()
 
-	var out []Comment
+	out := make([]Comment, 0, pageSize)
 	for rows.Next() {
 		var c Comment
-		var parentID sql.NullString
-		if err := rows.Scan(&c.ID, &c.PostID, &parentID, &c.AuthorID, &c.Content, &c.CreatedAt); err != nil {
-			return nil
+		var parentID, deletedAt, hiddenAt sql.NullString
+		if err := rows.Scan(&c.ID, &c.PostID, &parentID, &c.AuthorID, &c.Content, &c.CreatedAt, &deletedAt, &hiddenAt); err != nil {
+			return nil, 0, err
 		}
 		c.ParentID = strings.TrimSpace(parentID.String)
+		c.DeletedAt = strings.TrimSpace(deletedAt.String)
+		c.HiddenAt = strings.TrimSpace(hiddenAt.String)
 		out = append(out, c)
 	}
-	return out
+	return out, total, rows.Err()
 }
 
-func (s *SQLiteStore) CommentCount(postID string) int {
-	var count int
+func (s *SQLiteStore) UserKarma(userID string) int {
+	var karma int
 	err := s.db.QueryRow(
-		`SELECT COUNT(1)
-		 FROM comments
-		 WHERE post_id = ?
-		   AND (deleted_at IS NULL OR TRIM(deleted_at) = '');`,
-		postID,
-	).Scan(&count)
+		`SELECT
+			COALESCE((SELECT SUM(v.value) FROM post_votes v JOIN posts p ON p.id = v.post_id
+			          WHERE p.author_id = ? AND (p.deleted_at IS NULL OR TRIM(p.deleted_at) = '')), 0) +
+			COALESCE((SELECT SUM(v.value) FROM comment_votes v JOIN comments c ON c.id = v.comment_id
+			          WHERE c.author_id = ? AND (c.deleted_at IS NULL OR TRIM(c.deleted_at) = '')), 0);`,
+		userID,
+		userID,
+	).Scan(&karma)
 	if err != nil {
 		return 0
 	}
-	return count
+	return karma
 }
 
-func (s *SQLiteStore) GetComment(postID, commentID string) (Comment, bool) {
-	var comment Comment
-	var deletedAt sql.NullString
-	var parentID sql.NullString
-	err := s.db.QueryRow(
-		`SELECT id, post_id, parent_id, author_id, content, created_at, deleted_at
-		 FROM comments
-		 WHERE post_id = ?
-		   AND id = ?
-		   AND (deleted_at IS NULL OR TRIM(deleted_at) = '');`,
-		postID,
-		commentID,
-	).Scan(&comment.ID, &comment.PostID, &parentID, &comment.AuthorID, &comment.Content, &comment.CreatedAt, &deletedAt)
+func (s *SQLiteStore) RecordActivity(userID, day string) error {
+	if userID == "" || day == "" {
+		return ErrInvalidInput
+	}
+	_, err := s.db.Exec(`INSERT OR IGNORE INTO user_activity(user_id, day) VALUES(?, ?);`, userID, day)
+	return err
+}
+
+func (s *SQLiteStore) SiteStats(from, to string, topBoards int) (SiteStats, error) {
+	days, ok := dayRange(from, to)
+	if !ok {
+		return SiteStats{}, ErrInvalidInput
+	}
+
+	// created_at is RFC3339 UTC, so its first ten characters are the day.
+	daily := func(query string) ([]DailyCount, error) {
+		rows, err := s.db.Query(query, from, to)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		counts := map[string]int{}
+		for rows.Next() {
+			var day string
+			var count int
+			if err := rows.Scan(&day, &count); err != nil {
+				return nil, err
+			}
+			counts[day] = count
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return dailySeries(days, counts), nil
+	}
+	createdPerDay := func(table string) string {
+		return `SELECT substr(created_at, 1, 10) AS day, COUNT(*) FROM ` + table + `
+			WHERE substr(created_at, 1, 10) BETWEEN ? AND ? GROUP BY day;`
+	}
+
+	var stats SiteStats
+	var err error
+	if stats.ActiveUsers, err = daily(`SELECT day, COUNT(*) FROM user_activity WHERE day BETWEEN ? AND ? GROUP BY day;`); err != nil {
+		return SiteStats{}, err
+	}
+	if stats.Registrations, err = daily(createdPerDay("users")); err != nil {
+		return SiteStats{}, err
+	}
+	if stats.Posts, err = daily(createdPerDay("posts")); err != nil {
+		return SiteStats{}, err
+	}
+	if stats.Comments, err = daily(createdPerDay("comments")); err != nil {
+		return SiteStats{}, err
+	}
+	if stats.Messages, err = daily(createdPerDay("messages")); err != nil {
+		return SiteStats{}, err
+	}
+
+	rows, err := s.db.Query(
+		`SELECT b.id, b.name,
+			(SELECT COUNT(*) FROM posts p WHERE p.board_id = b.id
+			  AND substr(p.created_at, 1, 10) BETWEEN ?1 AND ?2) AS post_count,
+			(SELECT COUNT(*) FROM comments c JOIN posts p ON p.id = c.post_id WHERE p.board_id = b.id
+			  AND substr(c.created_at, 1, 10) BETWEEN ?1 AND ?2) AS comment_count
+		FROM boards b;`,
+		from,
+		to,
+	)
 	if err != nil {
-		return Comment{}, false
+		return SiteStats{}, err
 	}
-	comment.ParentID = strings.TrimSpace(parentID.String)
-	comment.DeletedAt = strings.TrimSpace(deletedAt.String)
-	return comment, true
+	defer rows.Close()
+	for rows.Next() {
+		var board BoardActivity
+		if err := rows.Scan(&board.BoardID, &board.Name, &board.Posts, &board.Comments); err != nil {
+			return SiteStats{}, err
+		}
+		if board.Posts > 0 || board.Comments > 0 {
+			stats.TopBoards = append(stats.TopBoards, board)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return SiteStats{}, err
+	}
+	stats.TopBoards = topBoardActivity(stats.TopBoards, topBoards)
+
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM reports WHERE status = 'open';`).Scan(&stats.OpenReports); err != nil {
+		return SiteStats{}, err
+	}
+	if err := s.db.QueryRow(`SELECT COUNT(*), COALESCE(SUM(size), 0) FROM files;`).Scan(&stats.FileCount, &stats.StorageBytes); err != nil {
+		return SiteStats{}, err
+	}
+	return stats, nil
+}
+
+const boardColumns = `id, name, description, icon, rules, qq_group, archived, read_only`
+
+func scanBoard(row interface{ Scan(dest ...any) error }) (Board, error) {
+	var b Board
+	var archived, readOnly int
+	if err := row.Scan(&b.ID, &b.Name, &b.Description, &b.Icon, &b.Rules, &b.QQGroup, &archived, &readOnly); err != nil {
+		return Board{}, err
+	}
+	b.Archived = archived != 0
+	b.ReadOnly = readOnly != 0
+	return b, nil
+}
+
+func (s *SQLiteStore) Boards() []Board {
+	rows, err := s.db.Query(`SELECT ` + boardColumns + ` FROM boards ORDER BY seq ASC;`)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var out []Board
+	for rows.Next() {
+		b, err := scanBoard(rows)
+		if err != nil {
+			return nil
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+func (s *SQLiteStore) GetBoard(boardID string) (Board, bool) {
+	board, err := scanBoard(s.db.QueryRow(`SELECT `+boardColumns+` FROM boards WHERE id = ?;`, boardID))
+	if err != nil {
+		return Board{}, false
+	}
+	return board, true
 }
 
-func (s *SQLiteStore) CreateComment(postID, authorID, content, parentID string) Comment {
+func (s *SQLiteStore) CreateBoard(board Board) (Board, error) {
+	board = normalizeBoard(board)
+	if !validBoardFields(board.Name, board.Description, board.Icon, board.Rules, board.QQGroup) {
+		return Board{}, ErrInvalidInput
+	}
+
 	tx, err := s.db.Begin()
 	if err != nil {
-		return Comment{}
+		return Board{}, err
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	seq, err := s.nextCounter(tx, "comment")
+	board, err = s.createBoardTx(tx, board)
 	if err != nil {
-		return Comment{}
+		return Board{}, err
 	}
+	if err := tx.Commit(); err != nil {
+		return Board{}, err
+	}
+	return board, nil
+}
 
-	comment := Comment{
-		ID:        fmt.Sprintf("c_%d", seq),
-		PostID:    postID,
-		ParentID:  parentID,
-		AuthorID:  authorID,
-		Content:   content,
-		CreatedAt: nowRFC3339(),
+func (s *SQLiteStore) createBoardTx(tx *sql.Tx, board Board) (Board, error) {
+	var taken int
+	if err := tx.QueryRow(`SELECT COUNT(1) FROM boards WHERE LOWER(name) = LOWER(?);`, board.Name).Scan(&taken); err != nil {
+		return Board{}, err
+	}
+	if taken > 0 {
+		return Board{}, ErrConflict
 	}
 
+	seq, err := s.nextCounter(tx, "board")
+	if err != nil {
+		return Board{}, err
+	}
+	board.ID = fmt.Sprintf("b_%d", seq)
 	if _, err := tx.Exec(
-		`INSERT INTO comments(seq, id, post_id, parent_id, author_id, content, created_at, deleted_at)
-		 VALUES(?, ?, ?, ?, ?, ?, ?, NULL);`,
+		`INSERT INTO boards(seq, id, name, description, icon, rules, qq_group, archived, read_only)
+		 VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?);`,
 		seq,
-		comment.ID,
-		comment.PostID,
-		nullStringOrValue(comment.ParentID),
-		comment.AuthorID,
-		comment.Content,
-		comment.CreatedAt,
+		board.ID,
+		board.Name,
+		board.Description,
+		board.Icon,
+		board.Rules,
+		board.QQGroup,
+		boolToInt(board.Archived),
+		boolToInt(board.ReadOnly),
 	); err != nil {
-		return Comment{}
+		return Board{}, err
+	}
+	return board, nil
+}
+
+func (s *SQLiteStore) UpdateBoard(board Board) (Board, error) {
+	board = normalizeBoard(board)
+	if board.ID == "" || !validBoardFields(board.Name, board.Description, board.Icon, board.Rules, board.QQGroup) {
+		return Board{}, ErrInvalidInput
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Board{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var taken int
+	if err := tx.QueryRow(
+		`SELECT COUNT(1) FROM boards WHERE LOWER(name) = LOWER(?) AND id <> ?;`,
+		board.Name,
+		board.ID,
+	).Scan(&taken); err != nil {
+		return Board{}, err
+	}
+	if taken > 0 {
+		return Board{}, ErrConflict
 	}
 
+	res, err := tx.Exec(
+		`UPDATE boards
+		 SET name = ?, description = ?, icon = ?, rules = ?, qq_group = ?, archived = ?, read_only = ?
+		 WHERE id = ?;`,
+		board.Name,
+		board.Description,
+		board.Icon,
+		board.Rules,
+		board.QQGroup,
+		boolToInt(board.Archived),
+		boolToInt(board.ReadOnly),
+		board.ID,
+	)
+	if err != nil {
+		return Board{}, err
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return Board{}, ErrNotFound
+	}
 	if err := tx.Commit(); err != nil {
-		return Comment{}
+		return Board{}, err
 	}
-	return comment
+	return board, nil
 }
 
-func (s *SQLiteStore) SoftDeleteComment(postID, commentID, actorUserID string) error {
+func (s *SQLiteStore) DeleteBoard(boardID string) error {
 	tx, err := s.db.Begin()
 	if err != nil {
 		return err
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	var authorID string
-	var deletedAt sql.NullString
-	err = tx.QueryRow(
-		`SELECT author_id, deleted_at
-		 FROM comments
-		 WHERE post_id = ? AND id = ?;`,
-		postID,
-		commentID,
-	).Scan(&authorID, &deletedAt)
-	if errors.Is(err, sql.ErrNoRows) {
-		return ErrNotFound
-	}
-	if err != nil {
+	var exists int
+	if err := tx.QueryRow(`SELECT COUNT(1) FROM boards WHERE id = ?;`, boardID).Scan(&exists); err != nil {
 		return err
 	}
-	if strings.TrimSpace(deletedAt.String) != "" {
+	if exists == 0 {
 		return ErrNotFound
 	}
-	if authorID != actorUserID {
-		return ErrForbidden
+	var posts int
+	if err := tx.QueryRow(`SELECT COUNT(1) FROM posts WHERE board_id = ?;`, boardID).Scan(&posts); err != nil {
+		return err
+	}
+	if posts > 0 {
+		return ErrConflict
 	}
 
-	if _, err := tx.Exec(
-		`UPDATE comments SET deleted_at = ? WHERE post_id = ? AND id = ?;`,
-		nowRFC3339(),
-		postID,
-		commentID,
-	); err != nil {
+	if _, err := tx.Exec(`DELETE FROM board_subscriptions WHERE board_id = ?;`, boardID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM board_moderators WHERE board_id = ?;`, boardID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM boards WHERE id = ?;`, boardID); err != nil {
 		return err
 	}
 	return tx.Commit()
 }
 
-func (s *SQLiteStore) PostScore(postID string) int {
-	var score int
-	err := s.db.QueryRow(
-		`SELECT COALESCE(SUM(value), 0)
-		 FROM post_votes
-		 WHERE post_id = ?;`,
-		postID,
-	).Scan(&score)
-	if err != nil {
-		return 0
-	}
-	return score
+const boardApplicationColumns = `id, applicant_id, name, description, icon, rules, qq_group, reason,
+	status, review_note, reviewed_by, board_id, created_at, updated_at`
+
+func scanBoardApplication(row interface{ Scan(dest ...any) error }) (BoardApplication, error) {
+	var a BoardApplication
+	err := row.Scan(
+		&a.ID,
+		&a.ApplicantID,
+		&a.Name,
+		&a.Description,
+		&a.Icon,
+		&a.Rules,
+		&a.QQGroup,
+		&a.Reason,
+		&a.Status,
+		&a.ReviewNote,
+		&a.ReviewedBy,
+		&a.BoardID,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	)
+	return a, err
 }
 
-func (s *SQLiteStore) PostVote(postID, userID string) int {
-	if strings.TrimSpace(userID) == "" {
-		return 0
-	}
-	var value int
-	err := s.db.QueryRow(
-		`SELECT value
-		 FROM post_votes
-		 WHERE post_id = ? AND user_id = ?;`,
-		postID,
-		userID,
-	).Scan(&value)
-	if errors.Is(err, sql.ErrNoRows) {
-		return 0
+func (s *SQLiteStore) CreateBoardApplication(app BoardApplication) (BoardApplication, error) {
+	app = normalizeBoardApplication(app)
+	if app.ApplicantID == "" || !validBoardFields(app.Name, app.Description, app.Icon, app.Rules, app.QQGroup) {
+		return BoardApplication{}, ErrInvalidInput
 	}
+
+	tx, err := s.db.Begin()
 	if err != nil {
-		return 0
+		return BoardApplication{}, err
 	}
-	return value
+	defer func() { _ = tx.Rollback() }()
+
+	var taken int
+	if err := tx.QueryRow(
+		`SELECT
+			(SELECT COUNT(1) FROM boards WHERE LOWER(name) = LOWER(?)) +
+			(SELECT COUNT(1) FROM board_applications WHERE status = 'pending' AND LOWER(name) = LOWER(?));`,
+		app.Name,
+		app.Name,
+	).Scan(&taken); err != nil {
+		return BoardApplication{}, err
+	}
+	if taken > 0 {
+		return BoardApplication{}, ErrConflict
+	}
+
+	seq, err := s.nextCounter(tx, "board_application")
+	if err != nil {
+		return BoardApplication{}, err
+	}
+	now := nowRFC3339()
+	app.ID = fmt.Sprintf("ba_%d", seq)
+	app.Status = "pending"
+	app.ReviewNote = ""
+	app.ReviewedBy = ""
+	app.BoardID = ""
+	app.CreatedAt = now
+	app.UpdatedAt = now
+
+	if _, err := tx.Exec(
+		`INSERT INTO board_applications(
+			seq, id, applicant_id, name, description, icon, rules, qq_group, reason,
+			status, review_note, reviewed_by, board_id, created_at, updated_at
+		) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+		seq,
+		app.ID,
+		app.ApplicantID,
+		app.Name,
+		app.Description,
+		app.Icon,
+		app.Rules,
+		app.QQGroup,
+		app.Reason,
+		app.Status,
+		app.ReviewNote,
+		app.ReviewedBy,
+		app.BoardID,
+		app.CreatedAt,
+		app.UpdatedAt,
+	); err != nil {
+		return BoardApplication{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return BoardApplication{}, err
+	}
+	return app, nil
+}
+
+func (s *SQLiteStore) BoardApplications(status, applicantID string, page, pageSize int) ([]BoardApplication, int, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	where := ` WHERE (? = '' OR status = ?) AND (? = '' OR applicant_id = ?)`
+	status = strings.TrimSpace(status)
+	applicantID = strings.TrimSpace(applicantID)
+	args := []any{status, status, applicantID, applicantID}
+
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM board_applications`+where+`;`, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.Query(
+		`SELECT `+boardApplicationColumns+` FROM board_applications`+where+` ORDER BY seq DESC LIMIT ? OFFSET ?;`,
+		append(args, pageSize, (page-1)*pageSize)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	out := make([]BoardApplication, 0, pageSize)
+	for rows.Next() {
+		app, err := scanBoardApplication(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		out = append(out, app)
+	}
+	return out, total, rows.Err()
+}
+
+func (s *SQLiteStore) ReviewBoardApplication(appID string, approve bool, note, reviewerID string) (BoardApplication, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return BoardApplication{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	app, err := scanBoardApplication(tx.QueryRow(
+		`SELECT `+boardApplicationColumns+` FROM board_applications WHERE id = ?;`,
+		appID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return BoardApplication{}, ErrNotFound
+	}
+	if err != nil {
+		return BoardApplication{}, err
+	}
+	if app.Status != "pending" {
+		return BoardApplication{}, ErrConflict
+	}
+
+	if approve {
+		board, err := s.createBoardTx(tx, Board{
+			Name:        app.Name,
+			Description: app.Description,
+			Icon:        app.Icon,
+			Rules:       app.Rules,
+			QQGroup:     app.QQGroup,
+		})
+		if err != nil {
+			return BoardApplication{}, err
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO board_moderators (board_id, user_id, granted_by, created_at)
+			 VALUES (?, ?, ?, ?);`,
+			board.ID,
+			app.ApplicantID,
+			reviewerID,
+			nowRFC3339(),
+		); err != nil {
+			return BoardApplication{}, err
+		}
+		app.Status = "approved"
+		app.BoardID = board.ID
+	} else {
+		app.Status = "rejected"
+	}
+	app.ReviewNote = strings.TrimSpace(note)
+	app.ReviewedBy = reviewerID
+	app.UpdatedAt = nowRFC3339()
+
+	if _, err := tx.Exec(
+		`UPDATE board_applications
+		 SET status = ?, review_note = ?, reviewed_by = ?, board_id = ?, updated_at = ?
+		 WHERE id = ?;`,
+		app.Status,
+		app.ReviewNote,
+		app.ReviewedBy,
+		app.BoardID,
+		app.UpdatedAt,
+		app.ID,
+	); err != nil {
+		return BoardApplication{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return BoardApplication{}, err
+	}
+	return app, nil
+}
+
+func boolToInt(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+func (s *SQLiteStore) SubscribeBoard(boardID, userID string) error {
+	if strings.TrimSpace(userID) == "" {
+		return ErrInvalidInput
+	}
+	if _, ok := s.GetBoard(boardID); !ok {
+		return ErrNotFound
+	}
+
+	_, err := s.db.Exec(
+		`INSERT INTO board_subscriptions (board_id, user_id, created_at)
+		 VALUES (?, ?, ?)
+		 ON CONFLICT(board_id, user_id) DO NOTHING;`,
+		boardID,
+		userID,
+		nowRFC3339(),
+	)
+	return err
+}
+
+func (s *SQLiteStore) UnsubscribeBoard(boardID, userID string) error {
+	if strings.TrimSpace(userID) == "" {
+		return ErrInvalidInput
+	}
+	if _, ok := s.GetBoard(boardID); !ok {
+		return ErrNotFound
+	}
+
+	_, err := s.db.Exec(`DELETE FROM board_subscriptions WHERE board_id = ? AND user_id = ?;`, boardID, userID)
+	return err
+}
+
+func (s *SQLiteStore) BoardSubscribed(boardID, userID string) bool {
+	if strings.TrimSpace(userID) == "" {
+		return false
+	}
+	var count int
+	err := s.db.QueryRow(
+		`SELECT COUNT(1) FROM board_subscriptions WHERE board_id = ? AND user_id = ?;`,
+		boardID,
+		userID,
+	).Scan(&count)
+	return err == nil && count > 0
+}
+
+func (s *SQLiteStore) BoardSubscriberCount(boardID string) int {
+	var count int
+	if err := s.db.QueryRow(`SELECT COUNT(1) FROM board_subscriptions WHERE board_id = ?;`, boardID).Scan(&count); err != nil {
+		return 0
+	}
+	return count
+}
+
+func (s *SQLiteStore) SubscribedBoardIDs(userID string) []string {
+	rows, err := s.db.Query(
+		`SELECT b.id
+		 FROM board_subscriptions bs
+		 JOIN boards b ON b.id = bs.board_id
+		 WHERE bs.user_id = ?
+		 ORDER BY b.seq ASC;`,
+		userID,
+	)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil
+		}
+		out = append(out, id)
+	}
+	return out
+}
+
+func (s *SQLiteStore) AddBoardModerator(boardID, userID, grantedBy string) error {
+	if _, ok := s.GetBoard(boardID); !ok {
+		return ErrNotFound
+	}
+	if _, ok := s.GetUser(userID); !ok {
+		return ErrInvalidInput
+	}
+
+	_, err := s.db.Exec(
+		`INSERT INTO board_moderators (board_id, user_id, granted_by, created_at)
+		 VALUES (?, ?, ?, ?)
+		 ON CONFLICT(board_id, user_id) DO NOTHING;`,
+		boardID,
+		userID,
+		grantedBy,
+		nowRFC3339(),
+	)
+	return err
+}
+
+func (s *SQLiteStore) RemoveBoardModerator(boardID, userID string) error {
+	res, err := s.db.Exec(`DELETE FROM board_moderators WHERE board_id = ? AND user_id = ?;`, boardID, userID)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStore) BoardModerators(boardID string) []BoardModerator {
+	rows, err := s.db.Query(
+		`SELECT board_id, user_id, granted_by, created_at
+		 FROM board_moderators
+		 WHERE board_id = ?
+		 ORDER BY created_at ASC, rowid ASC;`,
+		boardID,
+	)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var out []BoardModerator
+	for rows.Next() {
+		var m BoardModerator
+		if err := rows.Scan(&m.BoardID, &m.UserID, &m.GrantedBy, &m.CreatedAt); err != nil {
+			return nil
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func (s *SQLiteStore) IsBoardModerator(boardID, userID string) bool {
+	if strings.TrimSpace(userID) == "" {
+		return false
+	}
+	var count int
+	err := s.db.QueryRow(
+		`SELECT COUNT(1) FROM board_moderators WHERE board_id = ? AND user_id = ?;`,
+		boardID,
+		userID,
+	).Scan(&count)
+	return err == nil && count > 0
+}
+
+func (s *SQLiteStore) ModeratedBoardIDs(userID string) []string {
+	rows, err := s.db.Query(
+		`SELECT b.id
+		 FROM board_moderators bm
+		 JOIN boards b ON b.id = bm.board_id
+		 WHERE bm.user_id = ?
+		 ORDER BY b.seq ASC;`,
+		userID,
+	)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil
+		}
+		out = append(out, id)
+	}
+	return out
+}
+
+const postColumns = `id, board_id, author_id, title, content, created_at, deleted_at, pinned_at, locked_at, hidden_at`
+
+func scanPost(row interface{ Scan(dest ...any) error }) (Post, error) {
+	var p Post
+	var deletedAt, pinnedAt, lockedAt, hiddenAt sql.NullString
+	if err := row.Scan(&p.ID, &p.BoardID, &p.AuthorID, &p.Title, &p.Content, &p.CreatedAt, &deletedAt, &pinnedAt, &lockedAt, &hiddenAt); err != nil {
+		return Post{}, err
+	}
+	p.DeletedAt = strings.TrimSpace(deletedAt.String)
+	p.PinnedAt = strings.TrimSpace(pinnedAt.String)
+	p.LockedAt = strings.TrimSpace(lockedAt.String)
+	p.HiddenAt = strings.TrimSpace(hiddenAt.String)
+	return p, nil
+}
+
+func (s *SQLiteStore) Posts(boardID string) []Post {
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	if boardID == "" {
+		rows, err = s.db.Query(
+			`SELECT ` + postColumns + `
+			 FROM posts
+			 WHERE (deleted_at IS NULL OR TRIM(deleted_at) = '')
+			   AND (hidden_at IS NULL OR TRIM(hidden_at) = '')
+			 ORDER BY seq ASC;`,
+		)
+	} else {
+		rows, err = s.db.Query(
+			`SELECT `+postColumns+`
+			 FROM posts
+			 WHERE board_id = ?
+			   AND (deleted_at IS NULL OR TRIM(deleted_at) = '')
+			   AND (hidden_at IS NULL OR TRIM(hidden_at) = '')
+			 ORDER BY seq ASC;`,
+			boardID,
+		)
+	}
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var out []Post
+	for rows.Next() {
+		p, err := scanPost(rows)
+		if err != nil {
+			return nil
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+func (s *SQLiteStore) GetPost(postID string) (Post, bool) {
+	post, err := scanPost(s.db.QueryRow(
+		`SELECT `+postColumns+`
+		 FROM posts
+		 WHERE id = ?
+		   AND (deleted_at IS NULL OR TRIM(deleted_at) = '');`,
+		postID,
+	))
+	if err != nil {
+		return Post{}, false
+	}
+	return post, true
+}
+
+func (s *SQLiteStore) LookupPost(postID string) (Post, bool) {
+	post, err := scanPost(s.db.QueryRow(`SELECT `+postColumns+` FROM posts WHERE id = ?;`, postID))
+	if err != nil {
+		return Post{}, false
+	}
+	return post, true
+}
+
+func (s *SQLiteStore) CreatePost(boardID, authorID, title, content string, attachmentIDs []string) (Post, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Post{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	seq, err := s.nextCounter(tx, "post")
+	if err != nil {
+		return Post{}, err
+	}
+
+	post := Post{
+		ID:        fmt.Sprintf("p_%d", seq),
+		BoardID:   boardID,
+		AuthorID:  authorID,
+		Title:     title,
+		Content:   content,
+		CreatedAt: nowRFC3339(),
+	}
+
+	if _, err := tx.Exec(
+		`INSERT INTO posts(seq, id, board_id, author_id, title, content, created_at, deleted_at)
+		 VALUES(?, ?, ?, ?, ?, ?, ?, NULL);`,
+		seq,
+		post.ID,
+		post.BoardID,
+		post.AuthorID,
+		post.Title,
+		post.Content,
+		post.CreatedAt,
+	); err != nil {
+		return Post{}, err
+	}
+	if err := attachFiles(tx, authorID, attachmentIDs, post.ID, ""); err != nil {
+		return Post{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Post{}, err
+	}
+	return post, nil
+}
+
+// attachFiles links unattached files owned by uploaderID to a post/comment.
+// It fails with ErrForbidden when any file is missing, foreign or already attached.
+func attachFiles(tx *sql.Tx, uploaderID string, fileIDs []string, postID, commentID string) error {
+	for _, fileID := range fileIDs {
+		res, err := tx.Exec(
+			`UPDATE files
+			 SET post_id = ?, comment_id = ?
+			 WHERE id = ?
+			   AND uploader_id = ?
+			   AND (post_id IS NULL OR TRIM(post_id) = '');`,
+			postID,
+			nullStringOrValue(commentID),
+			fileID,
+			uploaderID,
+		)
+		if err != nil {
+			return err
+		}
+		if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+			return ErrForbidden
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) PurgePost(postID string) ([]FileMeta, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var exists int
+	err = tx.QueryRow(`SELECT COUNT(1) FROM posts WHERE id = ?;`, postID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if exists == 0 {
+		return nil, ErrNotFound
+	}
+
+	removed, err := queryFiles(tx,
+		`SELECT id, uploader_id, filename, content_type, size, storage_key, storage_path, post_id, comment_id, created_at
+		 FROM files
+		 WHERE post_id = ?
+		 ORDER BY seq ASC;`,
+		postID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stmt := range []string{
+		`DELETE FROM files WHERE post_id = ?;`,
+		`DELETE FROM comment_votes WHERE post_id = ?;`,
+		`DELETE FROM comments WHERE post_id = ?;`,
+		`DELETE FROM post_votes WHERE post_id = ?;`,
+		`DELETE FROM bookmarks WHERE post_id = ?;`,
+		`DELETE FROM posts WHERE id = ?;`,
+	} {
+		if _, err := tx.Exec(stmt, postID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+func (s *SQLiteStore) SoftDeletePost(postID, actorUserID string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var authorID string
+	var deletedAt sql.NullString
+	err = tx.QueryRow(`SELECT author_id, deleted_at FROM posts WHERE id = ?;`, postID).Scan(&authorID, &deletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(deletedAt.String) != "" {
+		return ErrNotFound
+	}
+	if authorID != actorUserID {
+		return ErrForbidden
+	}
+
+	if _, err := tx.Exec(`UPDATE posts SET deleted_at = ? WHERE id = ?;`, nowRFC3339(), postID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) SetPostDeleted(postID string, deleted bool) error {
+	return s.setPostStamp(postID, "deleted_at", deleted)
+}
+
+func (s *SQLiteStore) SetPostPinned(postID string, pinned bool) error {
+	return s.setPostStamp(postID, "pinned_at", pinned)
+}
+
+func (s *SQLiteStore) SetPostLocked(postID string, locked bool) error {
+	return s.setPostStamp(postID, "locked_at", locked)
+}
+
+func (s *SQLiteStore) SetPostHidden(postID string, hidden bool) error {
+	return s.setPostStamp(postID, "hidden_at", hidden)
+}
+
+// setPostStamp sets a nullable timestamp column, keeping an existing stamp when already set.
+// column must be a trusted constant.
+func (s *SQLiteStore) setPostStamp(postID, column string, on bool) error {
+	var res sql.Result
+	var err error
+	if on {
+		res, err = s.db.Exec(
+			`UPDATE posts SET `+column+` = COALESCE(NULLIF(TRIM(`+column+`), ''), ?) WHERE id = ?;`,
+			nowRFC3339(),
+			postID,
+		)
+	} else {
+		res, err = s.db.Exec(`UPDATE posts SET `+column+` = NULL WHERE id = ?;`, postID)
+	}
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Comments(postID string) []Comment {
+	rows, err := s.db.Query(
+		`SELECT id, post_id, parent_id, author_id, content, created_at
+		 FROM comments
+		 WHERE post_id = ?
+		   AND (deleted_at IS NULL OR TRIM(deleted_at) = '')
+		   AND (hidden_at IS NULL OR TRIM(hidden_at) = '')
+		 ORDER BY seq ASC;`,
+		postID,
+	)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var out []Comment
+	for rows.Next() {
+		var c Comment
+		var parentID sql.NullString
+		if err := rows.Scan(&c.ID, &c.PostID, &parentID, &c.AuthorID, &c.Content, &c.CreatedAt); err != nil {
+			return nil
+		}
+		c.ParentID = strings.TrimSpace(parentID.String)
+		out = append(out, c)
+	}
+	return out
+}
+
+func (s *SQLiteStore) CommentCount(postID string) int {
+	var count int
+	err := s.db.QueryRow(
+		`SELECT COUNT(1)
+		 FROM comments
+		 WHERE post_id = ?
+		   AND (deleted_at IS NULL OR TRIM(deleted_at) = '')
+		   AND (hidden_at IS NULL OR TRIM(hidden_at) = '');`,
+		postID,
+	).Scan(&count)
+	if err != nil {
+		return 0
+	}
+	return count
+}
+
+func (s *SQLiteStore) GetComment(postID, commentID string) (Comment, bool) {
+	var comment Comment
+	var deletedAt sql.NullString
+	var parentID sql.NullString
+	err := s.db.QueryRow(
+		`SELECT id, post_id, parent_id, author_id, content, created_at, deleted_at
+		 FROM comments
+		 WHERE post_id = ?
+		   AND id = ?
+		   AND (deleted_at IS NULL OR TRIM(deleted_at) = '');`,
+		postID,
+		commentID,
+	).Scan(&comment.ID, &comment.PostID, &parentID, &comment.AuthorID, &comment.Content, &comment.CreatedAt, &deletedAt)
+	if err != nil {
+		return Comment{}, false
+	}
+	comment.ParentID = strings.TrimSpace(parentID.String)
+	comment.DeletedAt = strings.TrimSpace(deletedAt.String)
+	return comment, true
+}
+
+func (s *SQLiteStore) LookupComment(commentID string) (Comment, bool) {
+	var comment Comment
+	var deletedAt, hiddenAt, parentID sql.NullString
+	err := s.db.QueryRow(
+		`SELECT id, post_id, parent_id, author_id, content, created_at, deleted_at, hidden_at
+		 FROM comments
+		 WHERE id = ?;`,
+		commentID,
+	).Scan(&comment.ID, &comment.PostID, &parentID, &comment.AuthorID, &comment.Content, &comment.CreatedAt, &deletedAt, &hiddenAt)
+	if err != nil {
+		return Comment{}, false
+	}
+	comment.ParentID = strings.TrimSpace(parentID.String)
+	comment.DeletedAt = strings.TrimSpace(deletedAt.String)
+	comment.HiddenAt = strings.TrimSpace(hiddenAt.String)
+	return comment, true
+}
+
+func (s *SQLiteStore) CreateComment(postID, authorID, content, parentID string, attachmentIDs []string) (Comment, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Comment{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	seq, err := s.nextCounter(tx, "comment")
+	if err != nil {
+		return Comment{}, err
+	}
+
+	comment := Comment{
+		ID:        fmt.Sprintf("c_%d", seq),
+		PostID:    postID,
+		ParentID:  parentID,
+		AuthorID:  authorID,
+		Content:   content,
+		CreatedAt: nowRFC3339(),
+	}
+
+	if _, err := tx.Exec(
+		`INSERT INTO comments(seq, id, post_id, parent_id, author_id, content, created_at, deleted_at)
+		 VALUES(?, ?, ?, ?, ?, ?, ?, NULL);`,
+		seq,
+		comment.ID,
+		comment.PostID,
+		nullStringOrValue(comment.ParentID),
+		comment.AuthorID,
+		comment.Content,
+		comment.CreatedAt,
+	); err != nil {
+		return Comment{}, err
+	}
+	if err := attachFiles(tx, authorID, attachmentIDs, postID, comment.ID); err != nil {
+		return Comment{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Comment{}, err
+	}
+	return comment, nil
+}
+
+func (s *SQLiteStore) SoftDeleteComment(postID, commentID, actorUserID string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var authorID string
+	var deletedAt sql.NullString
+	err = tx.QueryRow(
+		`SELECT author_id, deleted_at
+		 FROM comments
+		 WHERE post_id = ? AND id = ?;`,
+		postID,
+		commentID,
+	).Scan(&authorID, &deletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(deletedAt.String) != "" {
+		return ErrNotFound
+	}
+	if authorID != actorUserID {
+		return ErrForbidden
+	}
+
+	if _, err := tx.Exec(
+		`UPDATE comments SET deleted_at = ? WHERE post_id = ? AND id = ?;`,
+		nowRFC3339(),
+		postID,
+		commentID,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) SetCommentDeleted(postID, commentID string, deleted bool) error {
+	return s.setCommentStamp(postID, commentID, "deleted_at", deleted)
+}
+
+func (s *SQLiteStore) SetCommentHidden(postID, commentID string, hidden bool) error {
+	return s.setCommentStamp(postID, commentID, "hidden_at", hidden)
+}
+
+// setCommentStamp is the comment counterpart of setPostStamp; column must be a trusted constant.
+func (s *SQLiteStore) setCommentStamp(postID, commentID, column string, on bool) error {
+	var res sql.Result
+	var err error
+	if on {
+		res, err = s.db.Exec(
+			`UPDATE comments SET `+column+` = COALESCE(NULLIF(TRIM(`+column+`), ''), ?) WHERE post_id = ? AND id = ?;`,
+			nowRFC3339(),
+			postID,
+			commentID,
+		)
+	} else {
+		res, err = s.db.Exec(`UPDATE comments SET `+column+` = NULL WHERE post_id = ? AND id = ?;`, postID, commentID)
+	}
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStore) PostScore(postID string) int {
+	var score int
+	err := s.db.QueryRow(
+		`SELECT COALESCE(SUM(value), 0)
+		 FROM post_votes
+		 WHERE post_id = ?;`,
+		postID,
+	).Scan(&score)
+	if err != nil {
+		return 0
+	}
+	return score
+}
+
+func (s *SQLiteStore) PostVote(postID, userID string) int {
+	if strings.TrimSpace(userID) == "" {
+		return 0
+	}
+	var value int
+	err := s.db.QueryRow(
+		`SELECT value
+		 FROM post_votes
+		 WHERE post_id = ? AND user_id = ?;`,
+		postID,
+		userID,
+	).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0
+	}
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+func (s *SQLiteStore) VotePost(postID, userID string, value int) (int, int, error) {
+	if value != 1 && value != -1 {
+		return 0, 0, ErrInvalidInput
+	}
+	if strings.TrimSpace(userID) == "" {
+		return 0, 0, ErrInvalidInput
+	}
+	if _, ok := s.GetPost(postID); !ok {
+		return 0, 0, ErrNotFound
+	}
+
+	if _, err := s.db.Exec(
+		`INSERT INTO post_votes (post_id, user_id, value, created_at)
+		 VALUES (?, ?, ?, ?)
+		 ON CONFLICT(post_id, user_id)
+		 DO UPDATE SET value = excluded.value, created_at = excluded.created_at;`,
+		postID,
+		userID,
+		value,
+		nowRFC3339(),
+	); err != nil {
+		return 0, 0, err
+	}
+
+	score := s.PostScore(postID)
+	return score, value, nil
+}
+
+func (s *SQLiteStore) ClearPostVote(postID, userID string) (int, int, error) {
+	if strings.TrimSpace(userID) == "" {
+		return 0, 0, ErrInvalidInput
+	}
+	if _, ok := s.GetPost(postID); !ok {
+		return 0, 0, ErrNotFound
+	}
+
+	if _, err := s.db.Exec(
+		`DELETE FROM post_votes WHERE post_id = ? AND user_id = ?;`,
+		postID,
+		userID,
+	); err != nil {
+		return 0, 0, err
+	}
+
+	score := s.PostScore(postID)
+	return score, 0, nil
+}
+
+func (s *SQLiteStore) CommentScore(postID, commentID string) int {
+	var score int
+	err := s.db.QueryRow(
+		`SELECT COALESCE(SUM(value), 0)
+		 FROM comment_votes
+		 WHERE post_id = ? AND comment_id = ?;`,
+		postID,
+		commentID,
+	).Scan(&score)
+	if err != nil {
+		return 0
+	}
+	return score
+}
+
+func (s *SQLiteStore) CommentVote(postID, commentID, userID string) int {
+	if strings.TrimSpace(userID) == "" {
+		return 0
+	}
+	var value int
+	err := s.db.QueryRow(
+		`SELECT value
+		 FROM comment_votes
+		 WHERE post_id = ? AND comment_id = ? AND user_id = ?;`,
+		postID,
+		commentID,
+		userID,
+	).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0
+	}
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+func (s *SQLiteStore) VoteComment(postID, commentID, userID string, value int) (int, int, error) {
+	if value != 1 && value != -1 {
+		return 0, 0, ErrInvalidInput
+	}
+	if strings.TrimSpace(userID) == "" {
+		return 0, 0, ErrInvalidInput
+	}
+	if _, ok := s.GetComment(postID, commentID); !ok {
+		return 0, 0, ErrNotFound
+	}
+
+	if _, err := s.db.Exec(
+		`INSERT INTO comment_votes (comment_id, post_id, user_id, value, created_at)
+		 VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(comment_id, user_id)
+		 DO UPDATE SET value = excluded.value, post_id = excluded.post_id, created_at = excluded.created_at;`,
+		commentID,
+		postID,
+		userID,
+		value,
+		nowRFC3339(),
+	); err != nil {
+		return 0, 0, err
+	}
+
+	score := s.CommentScore(postID, commentID)
+	return score, value, nil
+}
+
+func (s *SQLiteStore) ClearCommentVote(postID, commentID, userID string) (int, int, error) {
+	if strings.TrimSpace(userID) == "" {
+		return 0, 0, ErrInvalidInput
+	}
+	if _, ok := s.GetComment(postID, commentID); !ok {
+		return 0, 0, ErrNotFound
+	}
+
+	if _, err := s.db.Exec(
+		`DELETE FROM comment_votes WHERE post_id = ? AND comment_id = ? AND user_id = ?;`,
+		postID,
+		commentID,
+		userID,
+	); err != nil {
+		return 0, 0, err
+	}
+
+	score := s.CommentScore(postID, commentID)
+	return score, 0, nil
+}
+
+func (s *SQLiteStore) BookmarkPost(postID, userID string) error {
+	if strings.TrimSpace(userID) == "" {
+		return ErrInvalidInput
+	}
+	if _, ok := s.GetPost(postID); !ok {
+		return ErrNotFound
+	}
+
+	_, err := s.db.Exec(
+		`INSERT INTO bookmarks (user_id, post_id, created_at)
+		 VALUES (?, ?, ?)
+		 ON CONFLICT(user_id, post_id) DO NOTHING;`,
+		userID,
+		postID,
+		nowRFC3339(),
+	)
+	return err
+}
+
+func (s *SQLiteStore) UnbookmarkPost(postID, userID string) error {
+	if strings.TrimSpace(userID) == "" {
+		return ErrInvalidInput
+	}
+	if _, ok := s.GetPost(postID); !ok {
+		return ErrNotFound
+	}
+
+	_, err := s.db.Exec(`DELETE FROM bookmarks WHERE user_id = ? AND post_id = ?;`, userID, postID)
+	return err
+}
+
+func (s *SQLiteStore) PostBookmarked(postID, userID string) bool {
+	if strings.TrimSpace(userID) == "" {
+		return false
+	}
+	var count int
+	err := s.db.QueryRow(
+		`SELECT COUNT(1) FROM bookmarks WHERE user_id = ? AND post_id = ?;`,
+		userID,
+		postID,
+	).Scan(&count)
+	return err == nil && count > 0
+}
+
+func (s *SQLiteStore) Bookmarks(userID string, page, pageSize int) ([]Post, int, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	var total int
+	if err := s.db.QueryRow(
+		`SELECT COUNT(1)
+		 FROM bookmarks b
+		 JOIN posts p ON p.id = b.post_id
+		 WHERE b.user_id = ?
+		   AND (p.deleted_at IS NULL OR TRIM(p.deleted_at) = '')
+		   AND (p.hidden_at IS NULL OR TRIM(p.hidden_at) = '');`,
+		userID,
+	).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.Query(
+		`SELECT p.id, p.board_id, p.author_id, p.title, p.content, p.created_at, p.deleted_at, p.pinned_at, p.locked_at, p.hidden_at
+		 FROM bookmarks b
+		 JOIN posts p ON p.id = b.post_id
+		 WHERE b.user_id = ?
+		   AND (p.deleted_at IS NULL OR TRIM(p.deleted_at) = '')
+		   AND (p.hidden_at IS NULL OR TRIM(p.hidden_at) = '')
+		 ORDER BY b.created_at DESC, b.rowid DESC
+		 LIMIT ? OFFSET ?;`,
+		userID,
+		pageSize,
+		(page-1)*pageSize,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	out := make([]Post, 0, pageSize)
+	for rows.Next() {
+		p, err := scanPost(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		out = append(out, p)
+	}
+	return out, total, rows.Err()
+}
+
+func (s *SQLiteStore) SaveFile(uploaderID, filename, storageKey, storagePath, contentType string, size int64) FileMeta {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return FileMeta{}
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	seq, err := s.nextCounter(tx, "file")
+	if err != nil {
+		return FileMeta{}
+	}
+
+	file := FileMeta{
+		ID:          fmt.Sprintf("f_%d", seq),
+		UploaderID:  uploaderID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+		StorageKey:  storageKey,
+		StoragePath: storagePath,
+		CreatedAt:   nowRFC3339(),
+	}
+
+	if _, err := tx.Exec(
+		`INSERT INTO files(seq, id, uploader_id, filename, content_type, size, storage_key, storage_path, created_at)
+		 VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+		seq,
+		file.ID,
+		file.UploaderID,
+		file.Filename,
+		file.ContentType,
+		file.Size,
+		file.StorageKey,
+		file.StoragePath,
+		file.CreatedAt,
+	); err != nil {
+		return FileMeta{}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return FileMeta{}
+	}
+	return file
+}
+
+func (s *SQLiteStore) GetFile(fileID string) (FileMeta, bool) {
+	files, err := queryFiles(s.db,
+		`SELECT id, uploader_id, filename, content_type, size, storage_key, storage_path, post_id, comment_id, created_at
+		 FROM files
+		 WHERE id = ?;`,
+		fileID,
+	)
+	if err != nil || len(files) == 0 {
+		return FileMeta{}, false
+	}
+	return files[0], true
+}
+
+func (s *SQLiteStore) Attachments(postID, commentID string) []FileMeta {
+	files, err := queryFiles(s.db,
+		`SELECT id, uploader_id, filename, content_type, size, storage_key, storage_path, post_id, comment_id, created_at
+		 FROM files
+		 WHERE post_id = ?
+		   AND COALESCE(comment_id, '') = ?
+		 ORDER BY seq ASC;`,
+		postID,
+		commentID,
+	)
+	if err != nil {
+		return nil
+	}
+	return files
+}
+
+func (s *SQLiteStore) PostAttachments(postIDs []string) map[string][]FileMeta {
+	out := map[string][]FileMeta{}
+	if len(postIDs) == 0 {
+		return out
+	}
+	args := make([]any, len(postIDs))
+	for i, id := range postIDs {
+		args[i] = id
+	}
+	files, err := queryFiles(s.db,
+		`SELECT id, uploader_id, filename, content_type, size, storage_key, storage_path, post_id, comment_id, created_at
+		 FROM files
+		 WHERE post_id IN (`+strings.TrimSuffix(strings.Repeat("?, ", len(postIDs)), ", ")+`)
+		   AND COALESCE(comment_id, '') = ''
+		 ORDER BY seq ASC;`,
+		args...,
+	)
+	if err != nil {
+		return out
+	}
+	for _, file := range files {
+		out[file.PostID] = append(out[file.PostID], file)
+	}
+	return out
+}
+
+func (s *SQLiteStore) CommentAttachments(postID string) map[string][]FileMeta {
+	out := map[string][]FileMeta{}
+	files, err := queryFiles(s.db,
+		`SELECT id, uploader_id, filename, content_type, size, storage_key, storage_path, post_id, comment_id, created_at
+		 FROM files
+		 WHERE post_id = ?
+		   AND COALESCE(comment_id, '') <> ''
+		 ORDER BY seq ASC;`,
+		postID,
+	)
+	if err != nil {
+		return out
+	}
+	for _, file := range files {
+		out[file.CommentID] = append(out[file.CommentID], file)
+	}
+	return out
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func queryFiles(q queryer, query string, args ...any) ([]FileMeta, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []FileMeta
+	for rows.Next() {
+		var f FileMeta
+		var postID, commentID sql.NullString
+		if err := rows.Scan(
+			&f.ID,
+			&f.UploaderID,
+			&f.Filename,
+			&f.ContentType,
+			&f.Size,
+			&f.StorageKey,
+			&f.StoragePath,
+			&postID,
+			&commentID,
+			&f.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		f.PostID = strings.TrimSpace(postID.String)
+		f.CommentID = strings.TrimSpace(commentID.String)
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+const chatRoomColumns = `id, name, description, visibility, owner_id, created_at, archived_at`
+
+func scanChatRoom(row interface{ Scan(dest ...any) error }) (ChatRoom, error) {
+	var room ChatRoom
+	err := row.Scan(&room.ID, &room.Name, &room.Description, &room.Visibility, &room.OwnerID, &room.CreatedAt, &room.ArchivedAt)
+	return room, err
+}
+
+func (s *SQLiteStore) ChatRooms() []ChatRoom {
+	rows, err := s.db.Query(`SELECT ` + chatRoomColumns + ` FROM chat_rooms ORDER BY seq ASC, created_at ASC;`)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var out []ChatRoom
+	for rows.Next() {
+		room, err := scanChatRoom(rows)
+		if err != nil {
+			return nil
+		}
+		out = append(out, room)
+	}
+	return out
+}
+
+func (s *SQLiteStore) GetChatRoom(roomID string) (ChatRoom, bool) {
+	if postID, ok := postRoomPostID(roomID); ok {
+		post, ok := s.LookupPost(postID)
+		if !ok {
+			return ChatRoom{}, false
+		}
+		return postChatRoom(post), true
+	}
+
+	room, err := scanChatRoom(s.db.QueryRow(`SELECT `+chatRoomColumns+` FROM chat_rooms WHERE id = ?;`, roomID))
+	if err != nil {
+		return ChatRoom{}, false
+	}
+	return room, true
+}
+
+func (s *SQLiteStore) CreateChatRoom(room ChatRoom) (ChatRoom, error) {
+	room, ok := normalizeChatRoom(room)
+	if !ok {
+		return ChatRoom{}, ErrInvalidInput
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return ChatRoom{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	seq, err := s.nextCounter(tx, "chat_room")
+	if err != nil {
+		return ChatRoom{}, err
+	}
+	room.ID = fmt.Sprintf("rm_%d", seq)
+	room.CreatedAt = nowRFC3339()
+	if _, err := tx.Exec(
+		`INSERT INTO chat_rooms(seq, id, name, description, visibility, owner_id, created_at)
+		 VALUES(?, ?, ?, ?, ?, ?, ?);`,
+		seq,
+		room.ID,
+		room.Name,
+		room.Description,
+		room.Visibility,
+		room.OwnerID,
+		room.CreatedAt,
+	); err != nil {
+		return ChatRoom{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return ChatRoom{}, err
+	}
+	return room, nil
+}
+
+func (s *SQLiteStore) ArchiveChatRoom(roomID string) (ChatRoom, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return ChatRoom{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	room, err := scanChatRoom(tx.QueryRow(`SELECT `+chatRoomColumns+` FROM chat_rooms WHERE id = ?;`, roomID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return ChatRoom{}, ErrNotFound
+	}
+	if err != nil {
+		return ChatRoom{}, err
+	}
+	if room.ArchivedAt != "" {
+		return ChatRoom{}, ErrConflict
+	}
+	room.ArchivedAt = nowRFC3339()
+	if _, err := tx.Exec(`UPDATE chat_rooms SET archived_at = ? WHERE id = ?;`, room.ArchivedAt, room.ID); err != nil {
+		return ChatRoom{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return ChatRoom{}, err
+	}
+	return room, nil
+}
+
+func (s *SQLiteStore) AddMessage(roomID, senderID, content string) ChatMessage {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return ChatMessage{}
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	seq, err := s.nextCounter(tx, "message")
+	if err != nil {
+		return ChatMessage{}
+	}
+
+	message := ChatMessage{
+		ID:        fmt.Sprintf("m_%d", seq),
+		RoomID:    roomID,
+		SenderID:  senderID,
+		Content:   content,
+		CreatedAt: nowRFC3339(),
+	}
+	message.Seq = seq
+
+	if _, err := tx.Exec(
+		`INSERT INTO messages(seq, id, room_id, sender_id, content, created_at)
+		 VALUES(?, ?, ?, ?, ?, ?);`,
+		seq,
+		message.ID,
+		message.RoomID,
+		message.SenderID,
+		message.Content,
+		message.CreatedAt,
+	); err != nil {
+		return ChatMessage{}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return ChatMessage{}
+	}
+	return message
+}
+
+func (s *SQLiteStore) Messages(roomID string, beforeSeq, limit int) []ChatMessage {
+	if strings.TrimSpace(roomID) == "" {
+		return nil
+	}
+	if beforeSeq <= 0 {
+		beforeSeq = math.MaxInt64
+	}
+
+	query := `SELECT id, seq, room_id, sender_id, content, created_at
+			  FROM messages
+			  WHERE room_id = ? AND seq < ? AND (deleted_at IS NULL OR TRIM(deleted_at) = '')
+			  ORDER BY seq ASC;`
+	args := []any{roomID, beforeSeq}
+
+	reverse := false
+	if limit > 0 {
+		query = `SELECT id, seq, room_id, sender_id, content, created_at
+				 FROM messages
+				 WHERE room_id = ? AND seq < ? AND (deleted_at IS NULL OR TRIM(deleted_at) = '')
+				 ORDER BY seq DESC
+				 LIMIT ?;`
+		args = []any{roomID, beforeSeq, limit}
+		reverse = true
+	}
+
+	return s.queryMessages(query, args, reverse)
+}
+
+func (s *SQLiteStore) MessagesSince(roomID string, afterSeq, limit int) []ChatMessage {
+	if strings.TrimSpace(roomID) == "" {
+		return nil
+	}
+	if limit <= 0 {
+		limit = -1 // SQLite: no limit
+	}
+	return s.queryMessages(
+		`SELECT id, seq, room_id, sender_id, content, created_at
+		 FROM messages
+		 WHERE room_id = ? AND seq > ? AND (deleted_at IS NULL OR TRIM(deleted_at) = '')
+		 ORDER BY seq DESC
+		 LIMIT ?;`,
+		[]any{roomID, afterSeq, limit},
+		true,
+	)
+}
+
+func (s *SQLiteStore) GetMessage(messageID string) (ChatMessage, bool) {
+	var m ChatMessage
+	var deletedAt sql.NullString
+	err := s.db.QueryRow(
+		`SELECT id, seq, room_id, sender_id, content, created_at, deleted_at FROM messages WHERE id = ?;`,
+		messageID,
+	).Scan(&m.ID, &m.Seq, &m.RoomID, &m.SenderID, &m.Content, &m.CreatedAt, &deletedAt)
+	if err != nil {
+		return ChatMessage{}, false
+	}
+	m.DeletedAt = strings.TrimSpace(deletedAt.String)
+	return m, true
+}
+
+// queryMessages runs a message query; reverse flips rows fetched newest first back into
+// chronological order.
+func (s *SQLiteStore) queryMessages(query string, args []any, reverse bool) []ChatMessage {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var out []ChatMessage
+	for rows.Next() {
+		var m ChatMessage
+		if err := rows.Scan(&m.ID, &m.Seq, &m.RoomID, &m.SenderID, &m.Content, &m.CreatedAt); err != nil {
+			return nil
+		}
+		out = append(out, m)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+
+	if reverse {
+		for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+			out[i], out[j] = out[j], out[i]
+		}
+	}
+	return out
+}
+
+const reportColumns = `id, target_type, target_id, board_id, target_user_id, snapshot, reporter_id, reason, detail,
+	status, action, note, handled_by, outcome, created_at, updated_at`
+
+func scanReport(row interface{ Scan(dest ...any) error }) (Report, error) {
+	var r Report
+	err := row.Scan(
+		&r.ID,
+		&r.TargetType,
+		&r.TargetID,
+		&r.BoardID,
+		&r.TargetUserID,
+		&r.Snapshot,
+		&r.ReporterID,
+		&r.Reason,
+		&r.Detail,
+		&r.Status,
+		&r.Action,
+		&r.Note,
+		&r.HandledBy,
+		&r.Outcome,
+		&r.CreatedAt,
+		&r.UpdatedAt,
+	)
+	return r, err
+}
+
+func (s *SQLiteStore) CreateReport(reporterID, targetType, targetID, reason, detail string) (Report, error) {
+	trimmedType := strings.TrimSpace(targetType)
+	trimmedID := strings.TrimSpace(targetID)
+	trimmedReason := strings.TrimSpace(reason)
+	trimmedDetail := strings.TrimSpace(detail)
+	if trimmedType == "" || trimmedID == "" || trimmedReason == "" || !validReportTargetType(trimmedType) {
+		return Report{}, ErrInvalidInput
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Report{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	target, ok, err := lookupReportTarget(tx, trimmedType, trimmedID)
+	if err != nil {
+		return Report{}, err
+	}
+	if !ok || target.deleted {
+		return Report{}, ErrNotFound
+	}
+	var duplicates int
+	if err := tx.QueryRow(
+		`SELECT COUNT(1) FROM reports
+		 WHERE reporter_id = ? AND target_type = ? AND target_id = ? AND status = 'open';`,
+		reporterID,
+		trimmedType,
+		trimmedID,
+	).Scan(&duplicates); err != nil {
+		return Report{}, err
+	}
+	if duplicates > 0 {
+		return Report{}, ErrConflict
+	}
+
+	seq, err := s.nextCounter(tx, "report")
+	if err != nil {
+		return Report{}, err
+	}
+
+	now := nowRFC3339()
+	report := Report{
+		ID:           fmt.Sprintf("r_%d", seq),
+		TargetType:   trimmedType,
+		TargetID:     trimmedID,
+		BoardID:      target.boardID,
+		TargetUserID: target.userID,
+		Snapshot:     target.snapshot,
+		ReporterID:   reporterID,
+		Reason:       trimmedReason,
+		Detail:       trimmedDetail,
+		Status:       "open",
+		Action:       "",
+		Note:         "",
+		HandledBy:    "",
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	if _, err := tx.Exec(
+		`INSERT INTO reports(
+			seq, id, target_type, target_id, board_id, target_user_id, snapshot, reporter_id, reason, detail,
+			status, action, note, handled_by, created_at, updated_at
+		) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+		seq,
+		report.ID,
+		report.TargetType,
+		report.TargetID,
+		report.BoardID,
+		report.TargetUserID,
+		report.Snapshot,
+		report.ReporterID,
+		report.Reason,
+		report.Detail,
+		report.Status,
+		report.Action,
+		report.Note,
+		report.HandledBy,
+		report.CreatedAt,
+		report.UpdatedAt,
+	); err != nil {
+		return Report{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Report{}, err
+	}
+	return report, nil
+}
+
+// lookupReportTarget resolves a report target, including soft-deleted content.
+func lookupReportTarget(q queryer, targetType, targetID string) (reportTarget, bool, error) {
+	var (
+		target reportTarget
+		err    error
+	)
+	switch targetType {
+	case "post":
+		var title, content string
+		var deletedAt sql.NullString
+		err = q.QueryRow(
+			`SELECT board_id, author_id, title, content, deleted_at FROM posts WHERE id = ?;`,
+			targetID,
+		).Scan(&target.boardID, &target.userID, &title, &content, &deletedAt)
+		target.snapshot = postSnapshot(title, content)
+		target.deleted = strings.TrimSpace(deletedAt.String) != ""
+	case "comment":
+		var content string
+		var commentDeleted, postDeleted sql.NullString
+		err = q.QueryRow(
+			`SELECT COALESCE(p.board_id, ''), c.author_id, c.content, c.deleted_at, p.deleted_at
+			 FROM comments c
+			 LEFT JOIN posts p ON p.id = c.post_id
+			 WHERE c.id = ?;`,
+			targetID,
+		).Scan(&target.boardID, &target.userID, &content, &commentDeleted, &postDeleted)
+		target.snapshot = truncateRunes(content, maxSnapshotRunes)
+		target.deleted = strings.TrimSpace(commentDeleted.String) != "" || strings.TrimSpace(postDeleted.String) != ""
+	case "user":
+		err = q.QueryRow(`SELECT id, nickname FROM users WHERE id = ?;`, targetID).Scan(&target.userID, &target.snapshot)
+	case "message":
+		var content string
+		var deletedAt sql.NullString
+		err = q.QueryRow(`SELECT sender_id, content, deleted_at FROM messages WHERE id = ?;`, targetID).Scan(&target.userID, &content, &deletedAt)
+		target.snapshot = truncateRunes(content, maxSnapshotRunes)
+		target.deleted = strings.TrimSpace(deletedAt.String) != ""
+	default:
+		return reportTarget{}, false, nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return reportTarget{}, false, nil
+	}
+	if err != nil {
+		return reportTarget{}, false, err
+	}
+	return target, true, nil
+}
+
+func (s *SQLiteStore) GetReport(reportID string) (Report, bool) {
+	report, err := scanReport(s.db.QueryRow(`SELECT `+reportColumns+` FROM reports WHERE id = ?;`, reportID))
+	if err != nil {
+		return Report{}, false
+	}
+	return report, true
+}
+
+func (s *SQLiteStore) ReportCounts(targetType, targetID string) (int, int) {
+	var total, open int
+	if err := s.db.QueryRow(
+		`SELECT COUNT(1), COALESCE(SUM(CASE WHEN status = 'open' THEN 1 ELSE 0 END), 0)
+		 FROM reports
+		 WHERE target_type = ? AND target_id = ?;`,
+		targetType,
+		targetID,
+	).Scan(&total, &open); err != nil {
+		return 0, 0
+	}
+	return total, open
+}
+
+func (s *SQLiteStore) TargetReports(targetType, targetID string) []Report {
+	rows, err := s.db.Query(
+		`SELECT `+reportColumns+`
+		 FROM reports
+		 WHERE target_type = ? AND target_id = ?
+		 ORDER BY seq ASC;`,
+		targetType,
+		targetID,
+	)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var out []Report
+	for rows.Next() {
+		r, err := scanReport(rows)
+		if err != nil {
+			return nil
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func (s *SQLiteStore) Reports(status, boardID string, page, pageSize int) ([]Report, int, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	where := []string{"1 = 1"}
+	var args []any
+	if trimmed := strings.TrimSpace(status); trimmed != "" {
+		where = append(where, "status = ?")
+		args = append(args, trimmed)
+	}
+	if trimmed := strings.TrimSpace(boardID); trimmed != "" {
+		where = append(where, "board_id = ?")
+		args = append(args, trimmed)
+	}
+	clause := strings.Join(where, " AND ")
+
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM reports WHERE `+clause+`;`, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.Query(
+		`SELECT `+reportColumns+`
+		 FROM reports
+		 WHERE `+clause+`
+		 ORDER BY seq DESC
+		 LIMIT ? OFFSET ?;`,
+		append(args, pageSize, (page-1)*pageSize)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	out := make([]Report, 0, pageSize)
+	for rows.Next() {
+		r, err := scanReport(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		out = append(out, r)
+	}
+	return out, total, nil
+}
+
+func (s *SQLiteStore) ReportsAgainst(userID string, page, pageSize int) ([]Report, int, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM reports WHERE target_user_id = ?;`, userID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := s.db.Query(
+		`SELECT `+reportColumns+` FROM reports WHERE target_user_id = ? ORDER BY seq DESC LIMIT ? OFFSET ?;`,
+		userID,
+		pageSize,
+		(page-1)*pageSize,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	out := make([]Report, 0, pageSize)
+	for rows.Next() {
+		r, err := scanReport(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		out = append(out, r)
+	}
+	return out, total, rows.Err()
+}
+
+func (s *SQLiteStore) UpdateReport(reportID string, resolution ReportResolution) (Report, error) {
+	resolution = normalizeReportResolution(resolution)
+	trimmedID := strings.TrimSpace(reportID)
+	if trimmedID == "" || !validReportResolution(resolution) {
+		return Report{}, ErrInvalidInput
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Report{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	report, err := scanReport(tx.QueryRow(`SELECT `+reportColumns+` FROM reports WHERE id = ?;`, trimmedID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Report{}, ErrNotFound
+	}
+	if err != nil {
+		return Report{}, err
+	}
+	if report.Status != "open" {
+		return Report{}, ErrConflict
+	}
+
+	var outcome string
+	switch resolution.Action {
+	case ReportActionDismiss:
+		outcome = "dismissed"
+		unhidden, err := unhideReportTarget(tx, report.TargetType, report.TargetID)
+		if err != nil {
+			return Report{}, err
+		}
+		if unhidden {
+			outcome = fmt.Sprintf("dismissed, %s %s unhidden", report.TargetType, report.TargetID)
+		}
+	case ReportActionRemoveContent:
+		removed, err := removeReportTarget(tx, report.TargetType, report.TargetID)
+		if err != nil {
+			return Report{}, err
+		}
+		if !removed {
+			return Report{}, ErrInvalidInput
+		}
+		outcome = fmt.Sprintf("%s %s removed", report.TargetType, report.TargetID)
+	default:
+		userID := report.TargetUserID
+		if userID == "" {
+			target, _, err := lookupReportTarget(tx, report.TargetType, report.TargetID)
+			if err != nil {
+				return Report{}, err
+			}
+			userID = target.userID
+		}
+		if userID == "" {
+			return Report{}, ErrInvalidInput
+		}
+		seq, err := s.nextCounter(tx, "sanction")
+		if err != nil {
+			return Report{}, err
+		}
+		sanction := newReportSanction(fmt.Sprintf("s_%d", seq), userID, report.ID, resolution)
+		if err := insertSanction(tx, seq, sanction); err != nil {
+			return Report{}, err
+		}
+		outcome = sanctionOutcome(sanction)
+	}
+
+	report.Status = reportStatusFor(resolution.Action)
+	report.Action = resolution.Action
+	report.Note = resolution.Note
+	report.HandledBy = resolution.HandledBy
+	report.Outcome = outcome
+	report.UpdatedAt = nowRFC3339()
+	if _, err := tx.Exec(
+		`UPDATE reports
+		 SET status = ?, action = ?, note = ?, handled_by = ?, outcome = ?, updated_at = ?
+		 WHERE id = ?;`,
+		report.Status,
+		report.Action,
+		report.Note,
+		report.HandledBy,
+		report.Outcome,
+		report.UpdatedAt,
+		report.ID,
+	); err != nil {
+		return Report{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Report{}, err
+	}
+	return report, nil
+}
+
+func removeReportTarget(tx *sql.Tx, targetType, targetID string) (bool, error) {
+	var table string
+	switch targetType {
+	case "post":
+		table = "posts"
+	case "comment":
+		table = "comments"
+	case "message":
+		table = "messages"
+	default:
+		return false, nil
+	}
+	res, err := tx.Exec(
+		`UPDATE `+table+` SET deleted_at = COALESCE(NULLIF(TRIM(deleted_at), ''), ?) WHERE id = ?;`,
+		nowRFC3339(),
+		targetID,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+// unhideReportTarget clears the auto-hide flag on a post/comment; it reports whether it was hidden.
+func unhideReportTarget(tx *sql.Tx, targetType, targetID string) (bool, error) {
+	var table string
+	switch targetType {
+	case "post":
+		table = "posts"
+	case "comment":
+		table = "comments"
+	default:
+		return false, nil
+	}
+	res, err := tx.Exec(
+		`UPDATE `+table+` SET hidden_at = NULL WHERE id = ? AND hidden_at IS NOT NULL AND TRIM(hidden_at) <> '';`,
+		targetID,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+func (s *SQLiteStore) FilterWords() []FilterWord {
+	rows, err := s.db.Query(`SELECT id, word, category, created_by, created_at FROM filter_words ORDER BY seq ASC;`)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var out []FilterWord
+	for rows.Next() {
+		var w FilterWord
+		if err := rows.Scan(&w.ID, &w.Word, &w.Category, &w.CreatedBy, &w.CreatedAt); err != nil {
+			return nil
+		}
+		out = append(out, w)
+	}
+	return out
+}
+
+func (s *SQLiteStore) AddFilterWord(word, category, createdBy string) (FilterWord, error) {
+	word, category, ok := normalizeFilterWord(word, category)
+	if !ok {
+		return FilterWord{}, ErrInvalidInput
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return FilterWord{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var exists int
+	err = tx.QueryRow(`SELECT 1 FROM filter_words WHERE word = ? COLLATE NOCASE;`, word).Scan(&exists)
+	if err == nil {
+		return FilterWord{}, ErrConflict
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return FilterWord{}, err
+	}
+
+	seq, err := s.nextCounter(tx, "filter_word")
+	if err != nil {
+		return FilterWord{}, err
+	}
+	entry := FilterWord{
+		ID:        fmt.Sprintf("fw_%d", seq),
+		Word:      word,
+		Category:  category,
+		CreatedBy: createdBy,
+		CreatedAt: nowRFC3339(),
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO filter_words(seq, id, word, category, created_by, created_at) VALUES(?, ?, ?, ?, ?, ?);`,
+		seq,
+		entry.ID,
+		entry.Word,
+		entry.Category,
+		entry.CreatedBy,
+		entry.CreatedAt,
+	); err != nil {
+		return FilterWord{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return FilterWord{}, err
+	}
+	return entry, nil
 }
 
-func (s *SQLiteStore) VotePost(postID, userID string, value int) (int, int, error) {
-	if value != 1 && value != -1 {
-		return 0, 0, ErrInvalidInput
+func (s *SQLiteStore) RemoveFilterWord(wordID string) error {
+	res, err := s.db.Exec(`DELETE FROM filter_words WHERE id = ?;`, wordID)
+	if err != nil {
+		return err
 	}
-	if strings.TrimSpace(userID) == "" {
-		return 0, 0, ErrInvalidInput
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
 	}
-	if _, ok := s.GetPost(postID); !ok {
-		return 0, 0, ErrNotFound
+	if affected == 0 {
+		return ErrNotFound
 	}
+	return nil
+}
 
-	if _, err := s.db.Exec(
-		`INSERT INTO post_votes (post_id, user_id, value, created_at)
-		 VALUES (?, ?, ?, ?)
-		 ON CONFLICT(post_id, user_id)
-		 DO UPDATE SET value = excluded.value, created_at = excluded.created_at;`,
-		postID,
-		userID,
-		value,
-		nowRFC3339(),
-	); err != nil {
-		return 0, 0, err
+func (s *SQLiteStore) FilterCategories() []FilterCategory {
+	rows, err := s.db.Query(`SELECT name, action FROM filter_categories ORDER BY name ASC;`)
+	if err != nil {
+		return nil
 	}
+	defer rows.Close()
 
-	score := s.PostScore(postID)
-	return score, value, nil
+	var out []FilterCategory
+	for rows.Next() {
+		var c FilterCategory
+		if err := rows.Scan(&c.Name, &c.Action); err != nil {
+			return nil
+		}
+		out = append(out, c)
+	}
+	return out
 }
 
-func (s *SQLiteStore) ClearPostVote(postID, userID string) (int, int, error) {
-	if strings.TrimSpace(userID) == "" {
-		return 0, 0, ErrInvalidInput
+func (s *SQLiteStore) SetFilterCategory(name, action string) error {
+	name = strings.TrimSpace(name)
+	action = strings.TrimSpace(action)
+	if name == "" || action == "" {
+		return ErrInvalidInput
+	}
+	_, err := s.db.Exec(
+		`INSERT INTO filter_categories(name, action) VALUES(?, ?)
+		 ON CONFLICT(name) DO UPDATE SET action = excluded.action;`,
+		name,
+		action,
+	)
+	return err
+}
+
+const sanctionColumns = `id, user_id, type, reason, report_id, created_by, created_at, expires_at, revoked_at`
+
+func scanSanction(row interface{ Scan(dest ...any) error }) (Sanction, error) {
+	var sanction Sanction
+	err := row.Scan(
+		&sanction.ID,
+		&sanction.UserID,
+		&sanction.Type,
+		&sanction.Reason,
+		&sanction.ReportID,
+		&sanction.CreatedBy,
+		&sanction.CreatedAt,
+		&sanction.ExpiresAt,
+		&sanction.RevokedAt,
+	)
+	return sanction, err
+}
+
+func (s *SQLiteStore) AddSanction(sanction Sanction) (Sanction, error) {
+	sanction, ok := normalizeSanction(sanction)
+	if !ok {
+		return Sanction{}, ErrInvalidInput
 	}
-	if _, ok := s.GetPost(postID); !ok {
-		return 0, 0, ErrNotFound
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Sanction{}, err
 	}
+	defer func() { _ = tx.Rollback() }()
 
-	if _, err := s.db.Exec(
-		`DELETE FROM post_votes WHERE post_id = ? AND user_id = ?;`,
-		postID,
-		userID,
-	); err != nil {
-		return 0, 0, err
+	var exists int
+	if err := tx.QueryRow(`SELECT 1 FROM users WHERE id = ?;`, sanction.UserID).Scan(&exists); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Sanction{}, ErrNotFound
+		}
+		return Sanction{}, err
 	}
 
-	score := s.PostScore(postID)
-	return score, 0, nil
+	seq, err := s.nextCounter(tx, "sanction")
+	if err != nil {
+		return Sanction{}, err
+	}
+	sanction.ID = fmt.Sprintf("s_%d", seq)
+	sanction.CreatedAt = nowRFC3339()
+	if err := insertSanction(tx, seq, sanction); err != nil {
+		return Sanction{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Sanction{}, err
+	}
+	return sanction, nil
 }
 
-func (s *SQLiteStore) CommentScore(postID, commentID string) int {
-	var score int
-	err := s.db.QueryRow(
-		`SELECT COALESCE(SUM(value), 0)
-		 FROM comment_votes
-		 WHERE post_id = ? AND comment_id = ?;`,
-		postID,
-		commentID,
-	).Scan(&score)
+func (s *SQLiteStore) Sanctions(userID string) []Sanction {
+	rows, err := s.db.Query(
+		`SELECT `+sanctionColumns+`
+		 FROM sanctions
+		 WHERE user_id = ?
+		 ORDER BY seq DESC;`,
+		userID,
+	)
 	if err != nil {
-		return 0
+		return nil
 	}
-	return score
-}
+	defer rows.Close()
 
-func (s *SQLiteStore) CommentVote(postID, commentID, userID string) int {
-	if strings.TrimSpace(userID) == "" {
-		return 0
+	var out []Sanction
+	for rows.Next() {
+		sanction, err := scanSanction(rows)
+		if err != nil {
+			return nil
+		}
+		out = append(out, sanction)
 	}
-	var value int
-	err := s.db.QueryRow(
-		`SELECT value
-		 FROM comment_votes
-		 WHERE post_id = ? AND comment_id = ? AND user_id = ?;`,
-		postID,
-		commentID,
+	return out
+}
+
+func (s *SQLiteStore) ActiveSanction(userID string) (Sanction, bool) {
+	sanction, err := scanSanction(s.db.QueryRow(
+		`SELECT `+sanctionColumns+`
+		 FROM sanctions
+		 WHERE user_id = ?
+		   AND revoked_at = ''
+		   AND (type = ? OR (type = ? AND expires_at > ?))
+		 ORDER BY type = ? DESC, expires_at DESC
+		 LIMIT 1;`,
 		userID,
-	).Scan(&value)
-	if errors.Is(err, sql.ErrNoRows) {
-		return 0
+		SanctionBan,
+		SanctionSuspension,
+		nowRFC3339(),
+		SanctionBan,
+	))
+	if err != nil {
+		return Sanction{}, false
 	}
+	return sanction, true
+}
+
+func (s *SQLiteStore) RevokeSanction(sanctionID string) (Sanction, error) {
+	tx, err := s.db.Begin()
 	if err != nil {
-		return 0
+		return Sanction{}, err
 	}
-	return value
+	defer func() { _ = tx.Rollback() }()
+
+	sanction, err := scanSanction(tx.QueryRow(`SELECT `+sanctionColumns+` FROM sanctions WHERE id = ?;`, sanctionID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Sanction{}, ErrNotFound
+		}
+		return Sanction{}, err
+	}
+	if sanction.RevokedAt != "" {
+		return Sanction{}, ErrConflict
+	}
+	sanction.RevokedAt = nowRFC3339()
+	if _, err := tx.Exec(`UPDATE sanctions SET revoked_at = ? WHERE id = ?;`, sanction.RevokedAt, sanction.ID); err != nil {
+		return Sanction{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Sanction{}, err
+	}
+	return sanction, nil
 }
 
-func (s *SQLiteStore) VoteComment(postID, commentID, userID string, value int) (int, int, error) {
-	if value != 1 && value != -1 {
-		return 0, 0, ErrInvalidInput
+const appealColumns = `id, user_id, target_type, target_id, reason, status, note, handled_by, outcome, created_at, updated_at`
+
+func scanAppeal(row interface{ Scan(dest ...any) error }) (Appeal, error) {
+	var appeal Appeal
+	err := row.Scan(
+		&appeal.ID,
+		&appeal.UserID,
+		&appeal.TargetType,
+		&appeal.TargetID,
+		&appeal.Reason,
+		&appeal.Status,
+		&appeal.Note,
+		&appeal.HandledBy,
+		&appeal.Outcome,
+		&appeal.CreatedAt,
+		&appeal.UpdatedAt,
+	)
+	return appeal, err
+}
+
+func (s *SQLiteStore) CreateAppeal(userID, targetType, targetID, reason string) (Appeal, error) {
+	appeal, ok := normalizeAppeal(userID, targetType, targetID, reason)
+	if !ok {
+		return Appeal{}, ErrInvalidInput
 	}
-	if strings.TrimSpace(userID) == "" {
-		return 0, 0, ErrInvalidInput
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Appeal{}, err
 	}
-	if _, ok := s.GetComment(postID, commentID); !ok {
-		return 0, 0, ErrNotFound
+	defer func() { _ = tx.Rollback() }()
+
+	switch appeal.TargetType {
+	case AppealTargetReport:
+		report, err := scanReport(tx.QueryRow(`SELECT `+reportColumns+` FROM reports WHERE id = ?;`, appeal.TargetID))
+		if errors.Is(err, sql.ErrNoRows) {
+			return Appeal{}, ErrNotFound
+		}
+		if err != nil {
+			return Appeal{}, err
+		}
+		if err := appealableReport(report, appeal.UserID); err != nil {
+			return Appeal{}, err
+		}
+	case AppealTargetSanction:
+		sanction, err := scanSanction(tx.QueryRow(`SELECT `+sanctionColumns+` FROM sanctions WHERE id = ?;`, appeal.TargetID))
+		if errors.Is(err, sql.ErrNoRows) {
+			return Appeal{}, ErrNotFound
+		}
+		if err != nil {
+			return Appeal{}, err
+		}
+		if err := appealableSanction(sanction, appeal.UserID); err != nil {
+			return Appeal{}, err
+		}
 	}
 
-	if _, err := s.db.Exec(
-		`INSERT INTO comment_votes (comment_id, post_id, user_id, value, created_at)
-		 VALUES (?, ?, ?, ?, ?)
-		 ON CONFLICT(comment_id, user_id)
-		 DO UPDATE SET value = excluded.value, post_id = excluded.post_id, created_at = excluded.created_at;`,
-		commentID,
-		postID,
-		userID,
-		value,
-		nowRFC3339(),
+	var exists int
+	err = tx.QueryRow(`SELECT 1 FROM appeals WHERE target_type = ? AND target_id = ?;`, appeal.TargetType, appeal.TargetID).Scan(&exists)
+	if err == nil {
+		return Appeal{}, ErrConflict
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return Appeal{}, err
+	}
+
+	seq, err := s.nextCounter(tx, "appeal")
+	if err != nil {
+		return Appeal{}, err
+	}
+	appeal.ID = fmt.Sprintf("ap_%d", seq)
+	appeal.CreatedAt = nowRFC3339()
+	appeal.UpdatedAt = appeal.CreatedAt
+	if _, err := tx.Exec(
+		`INSERT INTO appeals(seq, id, user_id, target_type, target_id, reason, status, created_at, updated_at)
+		 VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+		seq,
+		appeal.ID,
+		appeal.UserID,
+		appeal.TargetType,
+		appeal.TargetID,
+		appeal.Reason,
+		appeal.Status,
+		appeal.CreatedAt,
+		appeal.UpdatedAt,
 	); err != nil {
-		return 0, 0, err
+		return Appeal{}, err
 	}
+	if err := tx.Commit(); err != nil {
+		return Appeal{}, err
+	}
+	return appeal, nil
+}
 
-	score := s.CommentScore(postID, commentID)
-	return score, value, nil
+func (s *SQLiteStore) GetAppeal(appealID string) (Appeal, bool) {
+	appeal, err := scanAppeal(s.db.QueryRow(`SELECT `+appealColumns+` FROM appeals WHERE id = ?;`, appealID))
+	if err != nil {
+		return Appeal{}, false
+	}
+	return appeal, true
 }
 
-func (s *SQLiteStore) ClearCommentVote(postID, commentID, userID string) (int, int, error) {
-	if strings.TrimSpace(userID) == "" {
-		return 0, 0, ErrInvalidInput
+func (s *SQLiteStore) Appeals(status, userID string, page, pageSize int) ([]Appeal, int, error) {
+	if page <= 0 {
+		page = 1
 	}
-	if _, ok := s.GetComment(postID, commentID); !ok {
-		return 0, 0, ErrNotFound
+	if pageSize <= 0 {
+		pageSize = 20
 	}
 
-	if _, err := s.db.Exec(
-		`DELETE FROM comment_votes WHERE post_id = ? AND comment_id = ? AND user_id = ?;`,
-		postID,
-		commentID,
-		userID,
-	); err != nil {
-		return 0, 0, err
+	where := []string{"1 = 1"}
+	var args []any
+	if trimmed := strings.TrimSpace(status); trimmed != "" {
+		where = append(where, "status = ?")
+		args = append(args, trimmed)
+	}
+	if trimmed := strings.TrimSpace(userID); trimmed != "" {
+		where = append(where, "user_id = ?")
+		args = append(args, trimmed)
 	}
+	clause := strings.Join(where, " AND ")
 
-	score := s.CommentScore(postID, commentID)
-	return score, 0, nil
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM appeals WHERE `+clause+`;`, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.Query(
+		`SELECT `+appealColumns+`
+		 FROM appeals
+		 WHERE `+clause+`
+		 ORDER BY seq DESC
+		 LIMIT ? OFFSET ?;`,
+		append(args, pageSize, (page-1)*pageSize)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	out := make([]Appeal, 0, pageSize)
+	for rows.Next() {
+		appeal, err := scanAppeal(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		out = append(out, appeal)
+	}
+	return out, total, rows.Err()
 }
 
-func (s *SQLiteStore) SaveFile(uploaderID, filename, storageKey, storagePath string) FileMeta {
+func (s *SQLiteStore) ResolveAppeal(appealID string, accept bool, note, handledBy string) (Appeal, error) {
 	tx, err := s.db.Begin()
 	if err != nil {
-		return FileMeta{}
+		return Appeal{}, err
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	seq, err := s.nextCounter(tx, "file")
+	appeal, err := scanAppeal(tx.QueryRow(`SELECT `+appealColumns+` FROM appeals WHERE id = ?;`, appealID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Appeal{}, ErrNotFound
+	}
 	if err != nil {
-		return FileMeta{}
+		return Appeal{}, err
 	}
-
-	file := FileMeta{
-		ID:          fmt.Sprintf("f_%d", seq),
-		UploaderID:  uploaderID,
-		Filename:    filename,
-		StorageKey:  storageKey,
-		StoragePath: storagePath,
-		CreatedAt:   nowRFC3339(),
+	if appeal.Status != AppealPending {
+		return Appeal{}, ErrConflict
 	}
 
+	appeal.Status = AppealRejected
+	appeal.Outcome = "rejected"
+	if accept {
+		appeal.Status = AppealAccepted
+		if appeal.Outcome, err = reverseAppealTarget(tx, appeal); err != nil {
+			return Appeal{}, err
+		}
+	}
+	appeal.Note = strings.TrimSpace(note)
+	appeal.HandledBy = strings.TrimSpace(handledBy)
+	appeal.UpdatedAt = nowRFC3339()
 	if _, err := tx.Exec(
-		`INSERT INTO files(seq, id, uploader_id, filename, storage_key, storage_path, created_at)
-		 VALUES(?, ?, ?, ?, ?, ?, ?);`,
-		seq,
-		file.ID,
-		file.UploaderID,
-		file.Filename,
-		file.StorageKey,
-		file.StoragePath,
-		file.CreatedAt,
+		`UPDATE appeals SET status = ?, note = ?, handled_by = ?, outcome = ?, updated_at = ? WHERE id = ?;`,
+		appeal.Status,
+		appeal.Note,
+		appeal.HandledBy,
+		appeal.Outcome,
+		appeal.UpdatedAt,
+		appeal.ID,
 	); err != nil {
-		return FileMeta{}
+		return Appeal{}, err
 	}
-
 	if err := tx.Commit(); err != nil {
-		return FileMeta{}
+		return Appeal{}, err
 	}
-	return file
+	return appeal, nil
+}
+
+// reverseAppealTarget undoes what the appealed report or sanction did and describes it.
+func reverseAppealTarget(tx *sql.Tx, appeal Appeal) (string, error) {
+	var match, arg string
+	switch appeal.TargetType {
+	case AppealTargetReport:
+		report, err := scanReport(tx.QueryRow(`SELECT `+reportColumns+` FROM reports WHERE id = ?;`, appeal.TargetID))
+		if err != nil {
+			return "", err
+		}
+		if report.Action == ReportActionRemoveContent {
+			restored, err := restoreReportTarget(tx, report.TargetType, report.TargetID)
+			if err != nil || !restored {
+				return "nothing to restore", err
+			}
+			return fmt.Sprintf("%s %s restored", report.TargetType, report.TargetID), nil
+		}
+		match, arg = "report_id = ?", report.ID
+	case AppealTargetSanction:
+		match, arg = "id = ?", appeal.TargetID
+	default:
+		return "nothing to restore", nil
+	}
+
+	rows, err := tx.Query(`SELECT id FROM sanctions WHERE `+match+` AND revoked_at = '' ORDER BY seq ASC;`, arg)
+	if err != nil {
+		return "", err
+	}
+	var lifted []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return "", err
+		}
+		lifted = append(lifted, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(`UPDATE sanctions SET revoked_at = ? WHERE `+match+` AND revoked_at = '';`, nowRFC3339(), arg); err != nil {
+		return "", err
+	}
+	return appealLiftedOutcome(lifted), nil
 }
 
-func (s *SQLiteStore) GetFile(fileID string) (FileMeta, bool) {
-	var file FileMeta
-	err := s.db.QueryRow(
-		`SELECT id, uploader_id, filename, storage_key, storage_path, created_at
-		 FROM files
-		 WHERE id = ?;`,
-		fileID,
-	).Scan(&file.ID, &file.UploaderID, &file.Filename, &file.StorageKey, &file.StoragePath, &file.CreatedAt)
+// restoreReportTarget clears deleted_at on a removed post/comment/message, and hidden_at on a
+// post/comment that was auto-hidden or held for review, so it is visible again. It reports
+// whether anything changed.
+func restoreReportTarget(tx *sql.Tx, targetType, targetID string) (bool, error) {
+	unhidden, err := unhideReportTarget(tx, targetType, targetID)
 	if err != nil {
-		return FileMeta{}, false
+		return false, err
+	}
+	var table string
+	switch targetType {
+	case "post":
+		table = "posts"
+	case "comment":
+		table = "comments"
+	case "message":
+		table = "messages"
+	default:
+		return unhidden, nil
+	}
+	res, err := tx.Exec(
+		`UPDATE `+table+` SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL AND TRIM(deleted_at) <> '';`,
+		targetID,
+	)
+	if err != nil {
+		return false, err
 	}
-	return file, true
+	affected, err := res.RowsAffected()
+	return affected > 0 || unhidden, err
 }
 
-func (s *SQLiteStore) AddMessage(roomID, senderID, content string) ChatMessage {
+func insertSanction(tx *sql.Tx, seq int, sanction Sanction) error {
+	_, err := tx.Exec(
+		`INSERT INTO sanctions(seq, id, user_id, type, reason, report_id, created_by, created_at, expires_at, revoked_at)
+		 VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+		seq,
+		sanction.ID,
+		sanction.UserID,
+		sanction.Type,
+		sanction.Reason,
+		sanction.ReportID,
+		sanction.CreatedBy,
+		sanction.CreatedAt,
+		sanction.ExpiresAt,
+		sanction.RevokedAt,
+	)
+	return err
+}
+
+func (s *SQLiteStore) AddAuditEntry(entry AuditEntry) (AuditEntry, error) {
+	entry = normalizeAuditEntry(entry)
+	if entry.ActorID == "" || entry.Action == "" {
+		return AuditEntry{}, ErrInvalidInput
+	}
+
 	tx, err := s.db.Begin()
 	if err != nil {
-		return ChatMessage{}
+		return AuditEntry{}, err
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	seq, err := s.nextCounter(tx, "message")
+	seq, err := s.nextCounter(tx, "audit")
 	if err != nil {
-		return ChatMessage{}
-	}
-
-	message := ChatMessage{
-		ID:        fmt.Sprintf("m_%d", seq),
-		RoomID:    roomID,
-		SenderID:  senderID,
-		Content:   content,
-		CreatedAt: nowRFC3339(),
+		return AuditEntry{}, err
 	}
+	entry.ID = fmt.Sprintf("a_%d", seq)
+	entry.CreatedAt = nowRFC3339()
 
 	if _, err := tx.Exec(
-		`INSERT INTO messages(seq, id, room_id, sender_id, content, created_at)
-		 VALUES(?, ?, ?, ?, ?, ?);`,
+		`INSERT INTO audit_log(seq, id, actor_id, action, target_type, target_id, board_id, reason, before_state, after_state, ip, created_at)
+		 VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
 		seq,
-		message.ID,
-		message.RoomID,
-		message.SenderID,
-		message.Content,
-		message.CreatedAt,
+		entry.ID,
+		entry.ActorID,
+		entry.Action,
+		entry.TargetType,
+		entry.TargetID,
+		entry.BoardID,
+		entry.Reason,
+		nullableJSON(entry.Before),
+		nullableJSON(entry.After),
+		entry.IP,
+		entry.CreatedAt,
 	); err != nil {
-		return ChatMessage{}
+		return AuditEntry{}, err
 	}
-
 	if err := tx.Commit(); err != nil {
-		return ChatMessage{}
+		return AuditEntry{}, err
 	}
-	return message
+	return entry, nil
 }
 
-func (s *SQLiteStore) Messages(roomID string, limit int) []ChatMessage {
-	if strings.TrimSpace(roomID) == "" {
-		return nil
+func (s *SQLiteStore) AuditEntries(filter AuditFilter, page, pageSize int) ([]AuditEntry, int, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
 	}
 
-	query := `SELECT id, room_id, sender_id, content, created_at
-			  FROM messages
-			  WHERE room_id = ?
-			  ORDER BY seq ASC;`
-	args := []any{roomID}
+	where := []string{"1 = 1"}
+	var args []any
+	for _, cond := range []struct{ column, value string }{
+		{"actor_id", filter.ActorID},
+		{"action", filter.Action},
+		{"target_type", filter.TargetType},
+		{"target_id", filter.TargetID},
+		{"board_id", filter.BoardID},
+	} {
+		if cond.value != "" {
+			where = append(where, cond.column+" = ?")
+			args = append(args, cond.value)
+		}
+	}
+	if filter.Since != "" {
+		where = append(where, "created_at >= ?")
+		args = append(args, filter.Since)
+	}
+	if filter.Until != "" {
+		where = append(where, "created_at < ?")
+		args = append(args, filter.Until)
+	}
+	clause := strings.Join(where, " AND ")
 
-	reverse := false
-	if limit > 0 {
-		query = `SELECT id, room_id, sender_id, content, created_at
-				 FROM messages
-				 WHERE room_id = ?
-				 ORDER BY seq DESC
-				 LIMIT ?;`
-		args = []any{roomID, limit}
-		reverse = true
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM audit_log WHERE `+clause+`;`, args...).Scan(&total); err != nil {
+		return nil, 0, err
 	}
 
-	rows, err := s.db.Query(query, args...)
+	rows, err := s.db.Query(
+		`SELECT id, actor_id, action, target_type, target_id, board_id, reason, before_state, after_state, ip, created_at
+		 FROM audit_log
+		 WHERE `+clause+`
+		 ORDER BY seq DESC
+		 LIMIT ? OFFSET ?;`,
+		append(args, pageSize, (page-1)*pageSize)...,
+	)
 	if err != nil {
-		return nil
+		return nil, 0, err
 	}
 	defer rows.Close()
 
-	out := make([]ChatMessage, 0, max(limit, 0))
+	out := make([]AuditEntry, 0, pageSize)
 	for rows.Next() {
-		var m ChatMessage
-		if err := rows.Scan(&m.ID, &m.RoomID, &m.SenderID, &m.Content, &m.CreatedAt); err != nil {
-			return nil
+		var e AuditEntry
+		var before, after, ip sql.NullString
+		if err := rows.Scan(&e.ID, &e.ActorID, &e.Action, &e.TargetType, &e.TargetID, &e.BoardID, &e.Reason, &before, &after, &ip, &e.CreatedAt); err != nil {
+			return nil, 0, err
 		}
-		out = append(out, m)
+		if before.Valid {
+			e.Before = json.RawMessage(before.String)
+		}
+		if after.Valid {
+			e.After = json.RawMessage(after.String)
+		}
+		e.IP = ip.String
+		out = append(out, e)
 	}
-	if len(out) == 0 {
+	return out, total, rows.Err()
+}
+
+// nullableJSON stores empty JSON as NULL.
+func nullableJSON(data json.RawMessage) any {
+	if len(data) == 0 {
 		return nil
 	}
+	return string(data)
+}
 
-	if reverse {
-		for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
-			out[i], out[j] = out[j], out[i]
-		}
+func max(a, b int) int {
+	if a > b {
+		return a
 	}
-	return out
+	return b
 }
 
-func (s *SQLiteStore) CreateReport(reporterID, targetType, targetID, reason, detail string) (Report, error) {
-	trimmedType := strings.TrimSpace(targetType)
-	trimmedID := strings.TrimSpace(targetID)
-	trimmedReason := strings.TrimSpace(reason)
-	trimmedDetail := strings.TrimSpace(detail)
-	if trimmedType == "" || trimmedID == "" || trimmedReason == "" {
-		return Report{}, ErrInvalidInput
+func (s *SQLiteStore) CreateConversation(creatorID string, memberIDs []string, title string) (Conversation, error) {
+	convo, ok := normalizeConversation(creatorID, memberIDs, title)
+	if !ok {
+		return Conversation{}, ErrInvalidInput
 	}
 
 	tx, err := s.db.Begin()
 	if err != nil {
-		return Report{}, err
+		return Conversation{}, err
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	seq, err := s.nextCounter(tx, "report")
-	if err != nil {
-		return Report{}, err
+	for _, member := range convo.Members {
+		var exists int
+		err := tx.QueryRow(`SELECT 1 FROM users WHERE id = ?;`, member.UserID).Scan(&exists)
+		if errors.Is(err, sql.ErrNoRows) {
+			return Conversation{}, ErrNotFound
+		}
+		if err != nil {
+			return Conversation{}, err
+		}
+		err = tx.QueryRow(
+			`SELECT 1 FROM user_blocks WHERE (user_id = ? AND blocked_id = ?) OR (user_id = ? AND blocked_id = ?);`,
+			member.UserID, creatorID, creatorID, member.UserID,
+		).Scan(&exists)
+		if err == nil {
+			return Conversation{}, ErrForbidden
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return Conversation{}, err
+		}
 	}
 
-	now := nowRFC3339()
-	report := Report{
-		ID:         fmt.Sprintf("r_%d", seq),
-		TargetType: trimmedType,
-		TargetID:   trimmedID,
-		ReporterID: reporterID,
-		Reason:     trimmedReason,
-		Detail:     trimmedDetail,
-		Status:     "open",
-		Action:     "",
-		Note:       "",
-		HandledBy:  "",
-		CreatedAt:  now,
-		UpdatedAt:  now,
+	key := ""
+	if convo.Kind == ConversationDirect {
+		key = directKey(convo.MemberIDs())
+		var existingID string
+		err := tx.QueryRow(`SELECT id FROM conversations WHERE direct_key = ?;`, key).Scan(&existingID)
+		if err == nil {
+			_ = tx.Rollback()
+			existing, ok := s.GetConversation(existingID)
+			if !ok {
+				return Conversation{}, ErrNotFound
+			}
+			return existing, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return Conversation{}, err
+		}
 	}
 
+	seq, err := s.nextCounter(tx, "conversation")
+	if err != nil {
+		return Conversation{}, err
+	}
+	convo.ID = fmt.Sprintf("%s%d", conversationPrefix, seq)
+	convo.CreatedAt = nowRFC3339()
 	if _, err := tx.Exec(
-		`INSERT INTO reports(
-			seq, id, target_type, target_id, reporter_id, reason, detail,
-			status, action, note, handled_by, created_at, updated_at
-		) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+		`INSERT INTO conversations(seq, id, kind, title, creator_id, direct_key, created_at)
+		 VALUES(?, ?, ?, ?, ?, ?, ?);`,
 		seq,
-		report.ID,
-		report.TargetType,
-		report.TargetID,
-		report.ReporterID,
-		report.Reason,
-		report.Detail,
-		report.Status,
-		report.Action,
-		report.Note,
-		report.HandledBy,
-		report.CreatedAt,
-		report.UpdatedAt,
+		convo.ID,
+		convo.Kind,
+		convo.Title,
+		convo.CreatorID,
+		key,
+		convo.CreatedAt,
 	); err != nil {
-		return Report{}, err
+		return Conversation{}, err
+	}
+	for i := range convo.Members {
+		convo.Members[i].JoinedAt = convo.CreatedAt
+		if _, err := tx.Exec(
+			`INSERT INTO conversation_members(conversation_id, user_id, last_read_seq, joined_at) VALUES(?, ?, 0, ?);`,
+			convo.ID,
+			convo.Members[i].UserID,
+			convo.CreatedAt,
+		); err != nil {
+			return Conversation{}, err
+		}
 	}
-
 	if err := tx.Commit(); err != nil {
-		return Report{}, err
+		return Conversation{}, err
 	}
-	return report, nil
+	return convo, nil
 }
 
-func (s *SQLiteStore) Reports(status string, page, pageSize int) ([]Report, int, error) {
-	if page <= 0 {
-		page = 1
+func (s *SQLiteStore) GetConversation(conversationID string) (Conversation, bool) {
+	var convo Conversation
+	err := s.db.QueryRow(
+		`SELECT id, kind, title, creator_id, created_at FROM conversations WHERE id = ?;`,
+		conversationID,
+	).Scan(&convo.ID, &convo.Kind, &convo.Title, &convo.CreatorID, &convo.CreatedAt)
+	if err != nil {
+		return Conversation{}, false
 	}
-	if pageSize <= 0 {
-		pageSize = 20
+	members, err := s.conversationMembers(convo.ID)
+	if err != nil {
+		return Conversation{}, false
 	}
+	convo.Members = members
+	return convo, true
+}
 
-	trimmed := strings.TrimSpace(status)
-	var (
-		total int
-		err   error
+func (s *SQLiteStore) conversationMembers(conversationID string) ([]ConversationMember, error) {
+	rows, err := s.db.Query(
+		`SELECT user_id, last_read_seq, joined_at FROM conversation_members
+		 WHERE conversation_id = ? ORDER BY joined_at ASC, user_id ASC;`,
+		conversationID,
 	)
-
-	if trimmed == "" {
-		err = s.db.QueryRow(`SELECT COUNT(*) FROM reports;`).Scan(&total)
-	} else {
-		err = s.db.QueryRow(`SELECT COUNT(*) FROM reports WHERE status = ?;`, trimmed).Scan(&total)
-	}
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
+	defer rows.Close()
 
-	offset := (page - 1) * pageSize
-	var rows *sql.Rows
-	if trimmed == "" {
-		rows, err = s.db.Query(
-			`SELECT id, target_type, target_id, reporter_id, reason, detail, status, action, note, handled_by, created_at, updated_at
-			 FROM reports
-			 ORDER BY seq DESC
-			 LIMIT ? OFFSET ?;`,
-			pageSize,
-			offset,
-		)
-	} else {
-		rows, err = s.db.Query(
-			`SELECT id, target_type, target_id, reporter_id, reason, detail, status, action, note, handled_by, created_at, updated_at
-			 FROM reports
-			 WHERE status = ?
-			 ORDER BY seq DESC
-			 LIMIT ? OFFSET ?;`,
-			trimmed,
-			pageSize,
-			offset,
-		)
+	var members []ConversationMember
+	for rows.Next() {
+		var member ConversationMember
+		if err := rows.Scan(&member.UserID, &member.LastReadSeq, &member.JoinedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
 	}
+	return members, rows.Err()
+}
+
+func (s *SQLiteStore) Conversations(userID string) []Conversation {
+	rows, err := s.db.Query(
+		`SELECT c.id FROM conversations c
+		 JOIN conversation_members m ON m.conversation_id = c.id
+		 WHERE m.user_id = ?
+		 ORDER BY c.seq DESC;`,
+		userID,
+	)
 	if err != nil {
-		return nil, 0, err
+		return nil
 	}
-	defer rows.Close()
-
-	out := make([]Report, 0, pageSize)
+	var ids []string
 	for rows.Next() {
-		var r Report
-		if err := rows.Scan(
-			&r.ID,
-			&r.TargetType,
-			&r.TargetID,
-			&r.ReporterID,
-			&r.Reason,
-			&r.Detail,
-			&r.Status,
-			&r.Action,
-			&r.Note,
-			&r.HandledBy,
-			&r.CreatedAt,
-			&r.UpdatedAt,
-		); err != nil {
-			return nil, 0, err
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return nil
 		}
-		out = append(out, r)
+		ids = append(ids, id)
 	}
-	return out, total, nil
+	_ = rows.Close()
+
+	out := make([]Conversation, 0, len(ids))
+	for _, id := range ids {
+		if convo, ok := s.GetConversation(id); ok {
+			out = append(out, convo)
+		}
+	}
+	return out
 }
 
-func (s *SQLiteStore) UpdateReport(reportID, status, action, note, handledBy string) (Report, error) {
-	trimmedID := strings.TrimSpace(reportID)
-	trimmedStatus := strings.TrimSpace(status)
-	if trimmedID == "" || trimmedStatus == "" {
-		return Report{}, ErrInvalidInput
+func (s *SQLiteStore) MarkConversationRead(conversationID, userID string, seq int) error {
+	res, err := s.db.Exec(
+		`UPDATE conversation_members SET last_read_seq = MAX(last_read_seq, ?)
+		 WHERE conversation_id = ? AND user_id = ?;`,
+		seq, conversationID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
 	}
+	return nil
+}
 
-	tx, err := s.db.Begin()
+func (s *SQLiteStore) LeaveConversation(conversationID, userID string) error {
+	convo, ok := s.GetConversation(conversationID)
+	if !ok || !convo.HasMember(userID) {
+		return ErrNotFound
+	}
+	if convo.Kind != ConversationGroup {
+		return ErrInvalidInput
+	}
+	_, err := s.db.Exec(
+		`DELETE FROM conversation_members WHERE conversation_id = ? AND user_id = ?;`,
+		conversationID, userID,
+	)
+	return err
+}
+
+func (s *SQLiteStore) UnreadCount(conversationID, userID string) int {
+	var count int
+	err := s.db.QueryRow(
+		`SELECT COUNT(1) FROM messages msg
+		 JOIN conversation_members m ON m.conversation_id = msg.room_id AND m.user_id = ?
+		 WHERE msg.room_id = ? AND msg.seq > m.last_read_seq AND msg.sender_id != ?
+		   AND (msg.deleted_at IS NULL OR TRIM(msg.deleted_at) = '');`,
+		userID, conversationID, userID,
+	).Scan(&count)
 	if err != nil {
-		return Report{}, err
+		return 0
 	}
-	defer func() { _ = tx.Rollback() }()
+	return count
+}
 
-	now := nowRFC3339()
-	res, err := tx.Exec(
-		`UPDATE reports
-		 SET status = ?, action = ?, note = ?, handled_by = ?, updated_at = ?
-		 WHERE id = ?;`,
-		trimmedStatus,
-		strings.TrimSpace(action),
-		strings.TrimSpace(note),
-		strings.TrimSpace(handledBy),
-		now,
-		trimmedID,
+func (s *SQLiteStore) BlockUser(userID, blockedID string) error {
+	if userID == blockedID {
+		return ErrInvalidInput
+	}
+	var exists int
+	err := s.db.QueryRow(`SELECT 1 FROM users WHERE id = ?;`, blockedID).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(
+		`INSERT OR IGNORE INTO user_blocks(user_id, blocked_id, created_at) VALUES(?, ?, ?);`,
+		userID, blockedID, nowRFC3339(),
 	)
+	return err
+}
+
+func (s *SQLiteStore) UnblockUser(userID, blockedID string) error {
+	res, err := s.db.Exec(`DELETE FROM user_blocks WHERE user_id = ? AND blocked_id = ?;`, userID, blockedID)
 	if err != nil {
-		return Report{}, err
+		return err
 	}
-	affected, err := res.RowsAffected()
-	if err == nil && affected == 0 {
-		return Report{}, ErrNotFound
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
 	}
+	return nil
+}
 
-	var r Report
-	if err := tx.QueryRow(
-		`SELECT id, target_type, target_id, reporter_id, reason, detail, status, action, note, handled_by, created_at, updated_at
-		 FROM reports
-		 WHERE id = ?;`,
-		trimmedID,
-	).Scan(
-		&r.ID,
-		&r.TargetType,
-		&r.TargetID,
-		&r.ReporterID,
-		&r.Reason,
-		&r.Detail,
-		&r.Status,
-		&r.Action,
-		&r.Note,
-		&r.HandledBy,
-		&r.CreatedAt,
-		&r.UpdatedAt,
-	); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return Report{}, ErrNotFound
-		}
-		return Report{}, err
+func (s *SQLiteStore) BlockedUsers(userID string) []UserBlock {
+	rows, err := s.db.Query(
+		`SELECT blocked_id, created_at FROM user_blocks WHERE user_id = ? ORDER BY created_at DESC, blocked_id ASC;`,
+		userID,
+	)
+	if err != nil {
+		return nil
 	}
+	defer rows.Close()
 
-	if err := tx.Commit(); err != nil {
-		return Report{}, err
+	out := make([]UserBlock, 0)
+	for rows.Next() {
+		var block UserBlock
+		if err := rows.Scan(&block.BlockedID, &block.CreatedAt); err != nil {
+			return nil
+		}
+		out = append(out, block)
 	}
-	return r, nil
+	return out
 }
 
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
+func (s *SQLiteStore) Blocked(userID, otherID string) bool {
+	var exists int
+	err := s.db.QueryRow(`SELECT 1 FROM user_blocks WHERE user_id = ? AND blocked_id = ?;`, userID, otherID).Scan(&exists)
+	return err == nil
 }
 
 var _ API = (*SQLiteStore)(nil)
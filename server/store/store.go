@@ -1,18 +1,62 @@
 package store
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 type User struct {
 	ID        string
 	Nickname  string
+	Role      string
 	CreatedAt string
 }
 
+// User roles. Board moderators are tracked per board and are not a role.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// ValidRole reports whether role can be assigned with SetUserRole.
+func ValidRole(role string) bool {
+	return role == RoleUser || role == RoleAdmin
+}
+
+// UserRecord is the admin view of a user, including the login account.
+type UserRecord struct {
+	ID        string `json:"id"`
+	Nickname  string `json:"nickname"`
+	Account   string `json:"account"`
+	Role      string `json:"role"`
+	CreatedAt string `json:"created_at"`
+}
+
+// Session describes a user's login token. Only a short prefix of the token is exposed;
+// LastSeenAt/LastIP are updated by TouchSession and are empty until the token is first used.
+type Session struct {
+	TokenPrefix string `json:"token_prefix"`
+	CreatedAt   string `json:"created_at"`
+	LastSeenAt  string `json:"last_seen_at"`
+	LastIP      string `json:"last_ip"`
+}
+
+// sessionTokenPrefix is how many characters of a token Session exposes.
+const sessionTokenPrefix = 10
+
+// UserIP is an address a user's authenticated requests came from.
+type UserIP struct {
+	IP          string `json:"ip"`
+	FirstSeenAt string `json:"first_seen_at"`
+	LastSeenAt  string `json:"last_seen_at"`
+}
+
 // API defines the data operations the handlers need.
 //
 // The default implementation in this repo is an in-memory store (*Store).
@@ -23,19 +67,59 @@ type API interface {
 	Login(account, password string) (string, User, error)
 	UserByToken(token string) (User, bool)
 	GetUser(userID string) (User, bool)
+	UserKarma(userID string) int
+
+	SearchUsers(query string, page, pageSize int) ([]UserRecord, int, error)
+	GetUserRecord(userID string) (UserRecord, bool)
+	SetUserRole(userID, role string) error
+	SetPassword(userID, password string) error
+	UserSessions(userID string) []Session
+	RevokeSessions(userID string) (int, error)
+	TouchSession(token, ip string) error
+	UserIPs(userID string, limit int) []UserIP
+	UserPosts(authorID string, page, pageSize int) ([]Post, int, error)
+	UserComments(authorID string, page, pageSize int) ([]Comment, int, error)
+	ReportsAgainst(userID string, page, pageSize int) ([]Report, int, error)
 
 	Boards() []Board
 	GetBoard(boardID string) (Board, bool)
+	CreateBoard(board Board) (Board, error)
+	UpdateBoard(board Board) (Board, error)
+	DeleteBoard(boardID string) error
+	SubscribeBoard(boardID, userID string) error
+	UnsubscribeBoard(boardID, userID string) error
+	BoardSubscribed(boardID, userID string) bool
+	BoardSubscriberCount(boardID string) int
+	SubscribedBoardIDs(userID string) []string
+
+	AddBoardModerator(boardID, userID, grantedBy string) error
+	RemoveBoardModerator(boardID, userID string) error
+	BoardModerators(boardID string) []BoardModerator
+	IsBoardModerator(boardID, userID string) bool
+	ModeratedBoardIDs(userID string) []string
+
+	CreateBoardApplication(app BoardApplication) (BoardApplication, error)
+	BoardApplications(status, applicantID string, page, pageSize int) ([]BoardApplication, int, error)
+	ReviewBoardApplication(appID string, approve bool, note, reviewerID string) (BoardApplication, error)
 
 	Posts(boardID string) []Post
 	GetPost(postID string) (Post, bool)
-	CreatePost(boardID, authorID, title, content string) Post
+	LookupPost(postID string) (Post, bool)
+	CreatePost(boardID, authorID, title, content string, attachmentIDs []string) (Post, error)
 	SoftDeletePost(postID, actorUserID string) error
+	PurgePost(postID string) ([]FileMeta, error)
+	SetPostDeleted(postID string, deleted bool) error
+	SetPostPinned(postID string, pinned bool) error
+	SetPostLocked(postID string, locked bool) error
+	SetPostHidden(postID string, hidden bool) error
 
 	Comments(postID string) []Comment
 	GetComment(postID, commentID string) (Comment, bool)
-	CreateComment(postID, authorID, content, parentID string) Comment
+	LookupComment(commentID string) (Comment, bool)
+	CreateComment(postID, authorID, content, parentID string, attachmentIDs []string) (Comment, error)
 	SoftDeleteComment(postID, commentID, actorUserID string) error
+	SetCommentDeleted(postID, commentID string, deleted bool) error
+	SetCommentHidden(postID, commentID string, hidden bool) error
 	CommentCount(postID string) int
 
 	PostScore(postID string) int
@@ -47,15 +131,69 @@ type API interface {
 	VoteComment(postID, commentID, userID string, value int) (int, int, error)
 	ClearCommentVote(postID, commentID, userID string) (int, int, error)
 
-	SaveFile(uploaderID, filename, storageKey, storagePath string) FileMeta
+	BookmarkPost(postID, userID string) error
+	UnbookmarkPost(postID, userID string) error
+	PostBookmarked(postID, userID string) bool
+	Bookmarks(userID string, page, pageSize int) ([]Post, int, error)
+
+	SaveFile(uploaderID, filename, storageKey, storagePath, contentType string, size int64) FileMeta
 	GetFile(fileID string) (FileMeta, bool)
+	Attachments(postID, commentID string) []FileMeta
+	// PostAttachments loads the post-level attachments of several posts at once, keyed by post ID.
+	PostAttachments(postIDs []string) map[string][]FileMeta
+	// CommentAttachments loads the attachments of every comment on a post at once, keyed by comment ID.
+	CommentAttachments(postID string) map[string][]FileMeta
+
+	ChatRooms() []ChatRoom
+	GetChatRoom(roomID string) (ChatRoom, bool)
+	CreateChatRoom(room ChatRoom) (ChatRoom, error)
+	ArchiveChatRoom(roomID string) (ChatRoom, error)
+
+	CreateConversation(creatorID string, memberIDs []string, title string) (Conversation, error)
+	GetConversation(conversationID string) (Conversation, bool)
+	Conversations(userID string) []Conversation
+	MarkConversationRead(conversationID, userID string, seq int) error
+	LeaveConversation(conversationID, userID string) error
+	UnreadCount(conversationID, userID string) int
+	BlockUser(userID, blockedID string) error
+	UnblockUser(userID, blockedID string) error
+	BlockedUsers(userID string) []UserBlock
+	Blocked(userID, otherID string) bool
 
 	AddMessage(roomID, senderID, content string) ChatMessage
-	Messages(roomID string, limit int) []ChatMessage
+	Messages(roomID string, beforeSeq, limit int) []ChatMessage
+	MessagesSince(roomID string, afterSeq, limit int) []ChatMessage
+	// GetMessage returns a message by ID, including one removed by moderation.
+	GetMessage(messageID string) (ChatMessage, bool)
 
 	CreateReport(reporterID, targetType, targetID, reason, detail string) (Report, error)
-	Reports(status string, page, pageSize int) ([]Report, int, error)
-	UpdateReport(reportID, status, action, note, handledBy string) (Report, error)
+	GetReport(reportID string) (Report, bool)
+	ReportCounts(targetType, targetID string) (total, open int)
+	TargetReports(targetType, targetID string) []Report
+	Reports(status, boardID string, page, pageSize int) ([]Report, int, error)
+	UpdateReport(reportID string, resolution ReportResolution) (Report, error)
+
+	AddAuditEntry(entry AuditEntry) (AuditEntry, error)
+	AuditEntries(filter AuditFilter, page, pageSize int) ([]AuditEntry, int, error)
+
+	CreateAppeal(userID, targetType, targetID, reason string) (Appeal, error)
+	GetAppeal(appealID string) (Appeal, bool)
+	Appeals(status, userID string, page, pageSize int) ([]Appeal, int, error)
+	ResolveAppeal(appealID string, accept bool, note, handledBy string) (Appeal, error)
+
+	AddSanction(sanction Sanction) (Sanction, error)
+	Sanctions(userID string) []Sanction
+	ActiveSanction(userID string) (Sanction, bool)
+	RevokeSanction(sanctionID string) (Sanction, error)
+
+	FilterWords() []FilterWord
+	AddFilterWord(word, category, createdBy string) (FilterWord, error)
+	RemoveFilterWord(wordID string) error
+	FilterCategories() []FilterCategory
+	SetFilterCategory(name, action string) error
+
+	RecordActivity(userID, day string) error
+	SiteStats(from, to string, topBoards int) (SiteStats, error)
 }
 
 // Board is a simple forum category in the demo community module.
@@ -63,6 +201,44 @@ type Board struct {
 	ID          string `json:"id"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	Icon        string `json:"icon"`
+	Rules       string `json:"rules"`
+	QQGroup     string `json:"qq_group"`
+	Archived    bool   `json:"archived"`
+	ReadOnly    bool   `json:"read_only"`
+}
+
+// Writable reports whether new posts, comments and votes are accepted in the board.
+// Archived boards are implicitly read-only.
+func (b Board) Writable() bool {
+	return !b.Archived && !b.ReadOnly
+}
+
+// BoardApplication is a user's request to open a new board, reviewed by admins.
+// Status is one of "pending", "approved" or "rejected"; BoardID is set on approval.
+type BoardApplication struct {
+	ID          string `json:"id"`
+	ApplicantID string `json:"applicant_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Icon        string `json:"icon"`
+	Rules       string `json:"rules"`
+	QQGroup     string `json:"qq_group"`
+	Reason      string `json:"reason"`
+	Status      string `json:"status"`
+	ReviewNote  string `json:"review_note"`
+	ReviewedBy  string `json:"reviewed_by"`
+	BoardID     string `json:"board_id"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+// BoardModerator grants a user moderation powers within a single board.
+type BoardModerator struct {
+	BoardID   string `json:"board_id"`
+	UserID    string `json:"user_id"`
+	GrantedBy string `json:"granted_by"`
+	CreatedAt string `json:"created_at"`
 }
 
 // Post is a forum post stored in memory for the demo.
@@ -74,6 +250,14 @@ type Post struct {
 	Content   string
 	CreatedAt string
 	DeletedAt string
+	PinnedAt  string
+	LockedAt  string
+	HiddenAt  string
+}
+
+// Listed reports whether the post appears in public listings (not deleted or auto-hidden).
+func (p Post) Listed() bool {
+	return p.DeletedAt == "" && p.HiddenAt == ""
 }
 
 // Comment is a reply under a post.
@@ -85,40 +269,372 @@ type Comment struct {
 	Content   string
 	CreatedAt string
 	DeletedAt string
+	HiddenAt  string
+}
+
+// Listed reports whether the comment appears in public listings (not deleted or auto-hidden).
+func (c Comment) Listed() bool {
+	return c.DeletedAt == "" && c.HiddenAt == ""
+}
+
+// Bookmark records that a user saved a post for later.
+type Bookmark struct {
+	UserID    string
+	PostID    string
+	CreatedAt string
+}
+
+// Chat room visibilities. Private rooms are only listed to and joinable by their owner and admins.
+const (
+	RoomPublic  = "public"
+	RoomPrivate = "private"
+)
+
+// maxRoomNameRunes caps chat room names.
+const maxRoomNameRunes = 50
+
+// postRoomPrefix marks the discussion room that every post gets; see PostRoomID.
+const postRoomPrefix = "post_"
+
+// ChatRoom is a registered chat room; chat.join only accepts rooms that exist and are not archived.
+// Post rooms are not stored: they are derived from their post, so they lock and close with it.
+type ChatRoom struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Visibility  string `json:"visibility"`
+	OwnerID     string `json:"owner_id"`
+	PostID      string `json:"post_id,omitempty"`
+	CreatedAt   string `json:"created_at"`
+	LockedAt    string `json:"locked_at,omitempty"`
+	ArchivedAt  string `json:"archived_at"`
+}
+
+// Open reports whether the room accepts joins and messages.
+func (r ChatRoom) Open() bool {
+	return r.ArchivedAt == ""
+}
+
+// Writable reports whether new messages may be sent to the room.
+func (r ChatRoom) Writable() bool {
+	return r.Open() && r.LockedAt == ""
+}
+
+// PostRoomID returns the ID of the discussion room attached to a post.
+func PostRoomID(postID string) string {
+	return postRoomPrefix + postID
+}
+
+// postRoomPostID returns the post behind a post room ID.
+func postRoomPostID(roomID string) (string, bool) {
+	postID, ok := strings.CutPrefix(roomID, postRoomPrefix)
+	return postID, ok && postID != ""
+}
+
+// postChatRoom derives a post's discussion room: it is locked while the post is locked and
+// closed once the post is deleted or hidden.
+func postChatRoom(post Post) ChatRoom {
+	closed := post.DeletedAt
+	if closed == "" {
+		closed = post.HiddenAt
+	}
+	return ChatRoom{
+		ID:         PostRoomID(post.ID),
+		Name:       post.Title,
+		Visibility: RoomPublic,
+		OwnerID:    post.AuthorID,
+		PostID:     post.ID,
+		CreatedAt:  post.CreatedAt,
+		LockedAt:   post.LockedAt,
+		ArchivedAt: closed,
+	}
+}
+
+// Conversation kinds. A direct conversation has exactly two members and there is at most one
+// per pair of users; a group has up to maxConversationMembers.
+const (
+	ConversationDirect = "direct"
+	ConversationGroup  = "group"
+)
+
+const (
+	conversationPrefix     = "dm_"
+	maxConversationMembers = 10
+)
+
+// Conversation is a private chat. Its messages live in the room history under the conversation
+// ID, so chat.send and chat.history work on it like on a room.
+type Conversation struct {
+	ID        string               `json:"id"`
+	Kind      string               `json:"kind"`
+	Title     string               `json:"title"`
+	CreatorID string               `json:"creator_id"`
+	Members   []ConversationMember `json:"members"`
+	CreatedAt string               `json:"created_at"`
+}
+
+// ConversationMember tracks how far a member has read, for unread counts.
+type ConversationMember struct {
+	UserID      string `json:"user_id"`
+	LastReadSeq int    `json:"last_read_seq"`
+	JoinedAt    string `json:"joined_at"`
+}
+
+// HasMember reports whether the user is in the conversation.
+func (c Conversation) HasMember(userID string) bool {
+	for _, member := range c.Members {
+		if member.UserID == userID {
+			return true
+		}
+	}
+	return false
 }
 
-// ChatMessage is a message stored per room for history queries.
+// MemberIDs returns the members' user IDs.
+func (c Conversation) MemberIDs() []string {
+	ids := make([]string, 0, len(c.Members))
+	for _, member := range c.Members {
+		ids = append(ids, member.UserID)
+	}
+	return ids
+}
+
+// IsConversationID reports whether a room ID names a conversation.
+func IsConversationID(roomID string) bool {
+	return strings.HasPrefix(roomID, conversationPrefix)
+}
+
+// UserBlock records that a user blocked another from messaging them.
+type UserBlock struct {
+	BlockedID string `json:"user_id"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ChatMessage is a message stored per room for history queries. Seq is the numeric part of ID
+// and increases across all rooms, so it doubles as a history cursor.
 type ChatMessage struct {
 	ID        string
+	Seq       int
 	RoomID    string
 	SenderID  string
 	Content   string
 	CreatedAt string
+	DeletedAt string
 }
 
 // FileMeta tracks uploaded files and where they are stored on disk.
+//
+// PostID/CommentID are set once the file is attached to a post (or to a comment
+// under that post); a file can only be attached once.
 type FileMeta struct {
 	ID          string
 	UploaderID  string
 	Filename    string
+	ContentType string
+	Size        int64
 	StorageKey  string
 	StoragePath string
+	PostID      string
+	CommentID   string
 	CreatedAt   string
 }
 
+// Report is a user complaint about a post, comment, user or chat message.
+// BoardID is resolved from post/comment targets so moderators can see reports for their boards;
+// TargetUserID and Snapshot capture who is responsible and what the content said when reported.
 type Report struct {
-	ID         string
+	ID           string `json:"id"`
+	TargetType   string `json:"target_type"`
+	TargetID     string `json:"target_id"`
+	BoardID      string `json:"board_id"`
+	TargetUserID string `json:"target_user_id"`
+	Snapshot     string `json:"snapshot"`
+	ReporterID   string `json:"reporter_id"`
+	Reason       string `json:"reason"`
+	Detail       string `json:"detail"`
+	Status       string `json:"status"`
+	Action       string `json:"action"`
+	Note         string `json:"note"`
+	HandledBy    string `json:"handled_by"`
+	Outcome      string `json:"outcome"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
+}
+
+// Report actions accepted by UpdateReport.
+const (
+	ReportActionDismiss       = "dismiss"
+	ReportActionRemoveContent = "remove_content"
+	ReportActionWarnUser      = "warn_user"
+	ReportActionSuspendUser   = "suspend_user"
+	ReportActionBanUser       = "ban_user"
+)
+
+// ReportResolution describes how a report is handled; SuspendDays only applies to suspend_user.
+type ReportResolution struct {
+	Action      string
+	Note        string
+	HandledBy   string
+	SuspendDays int
+}
+
+// Appeal target types.
+const (
+	AppealTargetReport   = "report"
+	AppealTargetSanction = "sanction"
+)
+
+// Appeal statuses.
+const (
+	AppealPending  = "pending"
+	AppealAccepted = "accepted"
+	AppealRejected = "rejected"
+)
+
+// maxAppealReasonRunes caps the free-text reason on an appeal.
+const maxAppealReasonRunes = 1000
+
+// Appeal asks admins to reverse a resolved report against the user's content (or account) or a
+// sanction on their account. Each target can be appealed once. Outcome records what accepting
+// the appeal restored.
+type Appeal struct {
+	ID         string `json:"id"`
+	UserID     string `json:"user_id"`
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
+	Reason     string `json:"reason"`
+	Status     string `json:"status"`
+	Note       string `json:"note"`
+	HandledBy  string `json:"handled_by"`
+	Outcome    string `json:"outcome"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+// Sanction types recorded against users.
+const (
+	SanctionWarning    = "warning"
+	SanctionSuspension = "suspension"
+	SanctionBan        = "ban"
+)
+
+// Suspension lengths in days, shared by the sanction endpoint and report handling.
+const (
+	DefaultSuspendDays = 7
+	MaxSuspendDays     = 365
+)
+
+// Sanction is a penalty applied to a user. ExpiresAt is empty for warnings and permanent bans.
+type Sanction struct {
+	ID        string `json:"id"`
+	UserID    string `json:"user_id"`
+	Type      string `json:"type"`
+	Reason    string `json:"reason"`
+	ReportID  string `json:"report_id"`
+	CreatedBy string `json:"created_by"`
+	CreatedAt string `json:"created_at"`
+	ExpiresAt string `json:"expires_at"`
+	RevokedAt string `json:"revoked_at"`
+}
+
+// Restricts reports whether the sanction currently locks the user out: an unrevoked ban,
+// or an unrevoked suspension that has not expired yet. Warnings never restrict.
+func (s Sanction) Restricts(now time.Time) bool {
+	if s.RevokedAt != "" {
+		return false
+	}
+	switch s.Type {
+	case SanctionBan:
+		return true
+	case SanctionSuspension:
+		expires, err := time.Parse(time.RFC3339, s.ExpiresAt)
+		return err == nil && now.Before(expires)
+	default:
+		return false
+	}
+}
+
+// DailyCount is a per-day counter; Day is YYYY-MM-DD (UTC).
+type DailyCount struct {
+	Day   string `json:"day"`
+	Count int    `json:"count"`
+}
+
+// BoardActivity counts new posts and comments in a board over a period.
+type BoardActivity struct {
+	BoardID  string `json:"board_id"`
+	Name     string `json:"name"`
+	Posts    int    `json:"posts"`
+	Comments int    `json:"comments"`
+}
+
+// SiteStats backs the admin dashboard. Daily series cover every day of the requested range,
+// zero days included; TopBoards is ordered by posts, then comments.
+type SiteStats struct {
+	ActiveUsers   []DailyCount    `json:"active_users"`
+	Registrations []DailyCount    `json:"registrations"`
+	Posts         []DailyCount    `json:"posts"`
+	Comments      []DailyCount    `json:"comments"`
+	Messages      []DailyCount    `json:"messages"`
+	TopBoards     []BoardActivity `json:"top_boards"`
+	OpenReports   int             `json:"open_reports"`
+	FileCount     int             `json:"file_count"`
+	StorageBytes  int64           `json:"storage_bytes"`
+}
+
+// FilterWord is an entry of the sensitive-word list used by the content filter.
+type FilterWord struct {
+	ID        string `json:"id"`
+	Word      string `json:"word"`
+	Category  string `json:"category"`
+	CreatedBy string `json:"created_by"`
+	CreatedAt string `json:"created_at"`
+}
+
+// FilterCategory configures what the content filter does when a word of the category matches.
+type FilterCategory struct {
+	Name   string `json:"name"`
+	Action string `json:"action"`
+}
+
+// AuditEntry is an append-only record of an administrative or moderation action.
+// Before and After hold the relevant state of the target as JSON (null when not applicable).
+type AuditEntry struct {
+	ID         string          `json:"id"`
+	ActorID    string          `json:"actor_id"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	BoardID    string          `json:"board_id"`
+	Reason     string          `json:"reason"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	IP         string          `json:"ip"`
+	CreatedAt  string          `json:"created_at"`
+}
+
+// AuditFilter narrows AuditEntries; empty fields match everything.
+// Since (inclusive) and Until (exclusive) are RFC3339 timestamps in UTC.
+type AuditFilter struct {
+	ActorID    string
+	Action     string
 	TargetType string
 	TargetID   string
-	ReporterID string
-	Reason     string
-	Detail     string
-	Status     string
-	Action     string
-	Note       string
-	HandledBy  string
-	CreatedAt  string
-	UpdatedAt  string
+	BoardID    string
+	Since      string
+	Until      string
+}
+
+// AuditState encodes v for AuditEntry.Before/After; nil values and encoding failures yield null.
+func AuditState(v any) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
 }
 
 // Store is an in-memory, mutex-protected demo data store.
@@ -129,20 +645,43 @@ type Store struct {
 	passwords    map[string]string
 	tokens       map[string]string
 	userTokens   map[string]string
+	sessions     map[string]Session
+	userIPs      map[string][]UserIP
 	boards       []Board
+	boardApps    []BoardApplication
+	moderators   []BoardModerator
 	posts        []Post
 	comments     []Comment
 	postVotes    map[string]map[string]int
 	commentVotes map[string]map[string]int
+	boardSubs    map[string]map[string]string
+	bookmarks    []Bookmark
 	files        map[string]FileMeta
+	rooms        []ChatRoom
 	messages     map[string][]ChatMessage
 	reports      []Report
+	audit        []AuditEntry
+	sanctions    []Sanction
+	appeals      []Appeal
+	convos       []Conversation
+	blocks       map[string]map[string]string
+	filterWords  []FilterWord
+	filterCats   map[string]string
+	activity     map[string]map[string]bool
 	nextUserID   int
+	nextBoardID  int
+	nextBoardApp int
 	nextPostID   int
 	nextComment  int
 	nextFileID   int
 	nextMsgID    int
+	nextRoom     int
 	nextReport   int
+	nextAudit    int
+	nextSanction int
+	nextAppeal   int
+	nextConvo    int
+	nextFilter   int
 }
 
 // NewStore creates a demo store with a few built-in boards.
@@ -153,13 +692,21 @@ func NewStore() *Store {
 		passwords:    map[string]string{},
 		tokens:       map[string]string{},
 		userTokens:   map[string]string{},
+		sessions:     map[string]Session{},
+		userIPs:      map[string][]UserIP{},
 		boards:       defaultBoards(),
+		nextBoardID:  len(defaultBoards()),
 		posts:        []Post{},
 		comments:     []Comment{},
 		postVotes:    map[string]map[string]int{},
 		commentVotes: map[string]map[string]int{},
+		boardSubs:    map[string]map[string]string{},
 		files:        map[string]FileMeta{},
+		rooms:        defaultChatRooms(),
 		messages:     map[string][]ChatMessage{},
+		blocks:       map[string]map[string]string{},
+		filterCats:   map[string]string{},
+		activity:     map[string]map[string]bool{},
 	}
 }
 
@@ -196,475 +743,2501 @@ func (s *Store) GetUser(userID string) (User, bool) {
 	return user, ok
 }
 
-// Boards returns the list of boards.
-func (s *Store) Boards() []Board {
+// AddSanction records a sanction issued directly by an admin rather than through a report.
+func (s *Store) AddSanction(sanction Sanction) (Sanction, error) {
+	sanction, ok := normalizeSanction(sanction)
+	if !ok {
+		return Sanction{}, ErrInvalidInput
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	boards := make([]Board, len(s.boards))
-	copy(boards, s.boards)
-	return boards
+	if _, ok := s.users[sanction.UserID]; !ok {
+		return Sanction{}, ErrNotFound
+	}
+	s.nextSanction++
+	sanction.ID = fmt.Sprintf("s_%d", s.nextSanction)
+	sanction.CreatedAt = now()
+	s.sanctions = append(s.sanctions, sanction)
+	return sanction, nil
 }
 
-// GetBoard returns a board by ID.
-func (s *Store) GetBoard(boardID string) (Board, bool) {
+// Sanctions lists a user's sanctions newest first, including expired and revoked ones.
+func (s *Store) Sanctions(userID string) []Sanction {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for _, board := range s.boards {
-		if board.ID == boardID {
-			return board, true
+	var out []Sanction
+	for i := len(s.sanctions) - 1; i >= 0; i-- {
+		if s.sanctions[i].UserID == userID {
+			out = append(out, s.sanctions[i])
 		}
 	}
-	return Board{}, false
+	return out
 }
 
-// Posts returns posts for a board. If boardID is empty, it returns all posts.
-func (s *Store) Posts(boardID string) []Post {
+// ActiveSanction returns the sanction currently locking the user out, preferring bans over
+// suspensions and the latest-expiring suspension otherwise.
+func (s *Store) ActiveSanction(userID string) (Sanction, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if boardID == "" {
-		out := make([]Post, 0, len(s.posts))
-		for _, post := range s.posts {
-			if post.DeletedAt == "" {
-				out = append(out, post)
-			}
+	var active Sanction
+	found := false
+	current := time.Now()
+	for _, sanction := range s.sanctions {
+		if sanction.UserID != userID || !sanction.Restricts(current) {
+			continue
 		}
-		return out
-	}
-
-	filtered := make([]Post, 0, len(s.posts))
-	for _, post := range s.posts {
-		if post.BoardID == boardID && post.DeletedAt == "" {
-			filtered = append(filtered, post)
+		if !found || outranks(sanction, active) {
+			active = sanction
+			found = true
 		}
 	}
-	return filtered
+	return active, found
 }
 
-// GetPost returns a post by ID.
-func (s *Store) GetPost(postID string) (Post, bool) {
+// RevokeSanction lifts a sanction; revoking twice is a conflict.
+func (s *Store) RevokeSanction(sanctionID string) (Sanction, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for _, post := range s.posts {
-		if post.ID == postID && post.DeletedAt == "" {
-			return post, true
+	for i := range s.sanctions {
+		if s.sanctions[i].ID != sanctionID {
+			continue
 		}
+		if s.sanctions[i].RevokedAt != "" {
+			return Sanction{}, ErrConflict
+		}
+		s.sanctions[i].RevokedAt = now()
+		return s.sanctions[i], nil
 	}
-	return Post{}, false
+	return Sanction{}, ErrNotFound
 }
 
-// CreatePost appends a post to the store and returns it.
-func (s *Store) CreatePost(boardID, authorID, title, content string) Post {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	s.nextPostID++
-	post := Post{
-		ID:        fmt.Sprintf("p_%d", s.nextPostID),
-		BoardID:   boardID,
-		AuthorID:  authorID,
-		Title:     title,
-		Content:   content,
-		CreatedAt: now(),
+// CreateAppeal files an appeal by userID against a resolved report on their content or account,
+// or against one of their sanctions.
+func (s *Store) CreateAppeal(userID, targetType, targetID, reason string) (Appeal, error) {
+	appeal, ok := normalizeAppeal(userID, targetType, targetID, reason)
+	if !ok {
+		return Appeal{}, ErrInvalidInput
 	}
-	s.posts = append(s.posts, post)
-	return post
-}
 
-// SoftDeletePost marks a post as deleted. Only the post author can delete it in the demo.
-func (s *Store) SoftDeletePost(postID, actorUserID string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for idx, post := range s.posts {
-		if post.ID != postID {
-			continue
+	switch appeal.TargetType {
+	case AppealTargetReport:
+		report, ok := s.findReport(appeal.TargetID)
+		if !ok {
+			return Appeal{}, ErrNotFound
 		}
-		if post.DeletedAt != "" {
-			return ErrNotFound
+		if err := appealableReport(report, appeal.UserID); err != nil {
+			return Appeal{}, err
 		}
-		if post.AuthorID != actorUserID {
-			return ErrForbidden
+	case AppealTargetSanction:
+		sanction, ok := s.findSanction(appeal.TargetID)
+		if !ok {
+			return Appeal{}, ErrNotFound
+		}
+		if err := appealableSanction(sanction, appeal.UserID); err != nil {
+			return Appeal{}, err
 		}
-		post.DeletedAt = now()
-		s.posts[idx] = post
-		return nil
 	}
-	return ErrNotFound
+	for _, existing := range s.appeals {
+		if existing.TargetType == appeal.TargetType && existing.TargetID == appeal.TargetID {
+			return Appeal{}, ErrConflict
+		}
+	}
+
+	s.nextAppeal++
+	appeal.ID = fmt.Sprintf("ap_%d", s.nextAppeal)
+	appeal.CreatedAt = now()
+	appeal.UpdatedAt = appeal.CreatedAt
+	s.appeals = append(s.appeals, appeal)
+	return appeal, nil
 }
 
-// Comments returns all comments under the given post.
-func (s *Store) Comments(postID string) []Comment {
+// GetAppeal returns an appeal by ID.
+func (s *Store) GetAppeal(appealID string) (Appeal, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	filtered := make([]Comment, 0, len(s.comments))
-	for _, comment := range s.comments {
-		if comment.PostID == postID && comment.DeletedAt == "" {
-			filtered = append(filtered, comment)
+	for _, appeal := range s.appeals {
+		if appeal.ID == appealID {
+			return appeal, true
 		}
 	}
-	return filtered
+	return Appeal{}, false
 }
 
-// GetComment returns a comment by ID under the given post.
-func (s *Store) GetComment(postID, commentID string) (Comment, bool) {
+// Appeals lists appeals newest first, optionally filtered by status and appellant.
+func (s *Store) Appeals(status, userID string, page, pageSize int) ([]Appeal, int, error) {
+	status = strings.TrimSpace(status)
+	userID = strings.TrimSpace(userID)
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for _, comment := range s.comments {
-		if comment.PostID == postID && comment.ID == commentID && comment.DeletedAt == "" {
-			return comment, true
+	var matched []Appeal
+	for i := len(s.appeals) - 1; i >= 0; i-- {
+		appeal := s.appeals[i]
+		if (status == "" || appeal.Status == status) && (userID == "" || appeal.UserID == userID) {
+			matched = append(matched, appeal)
 		}
 	}
-	return Comment{}, false
+	start, end := pageBounds(len(matched), page, pageSize)
+	out := make([]Appeal, end-start)
+	copy(out, matched[start:end])
+	return out, len(matched), nil
 }
 
-// CreateComment appends a comment to the store and returns it.
-func (s *Store) CreateComment(postID, authorID, content, parentID string) Comment {
+// ResolveAppeal accepts or rejects a pending appeal. Accepting restores removed content or
+// lifts the sanction the appeal is about.
+func (s *Store) ResolveAppeal(appealID string, accept bool, note, handledBy string) (Appeal, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.nextComment++
-	comment := Comment{
-		ID:        fmt.Sprintf("c_%d", s.nextComment),
-		PostID:    postID,
-		ParentID:  parentID,
-		AuthorID:  authorID,
-		Content:   content,
-		CreatedAt: now(),
+	idx := -1
+	for i := range s.appeals {
+		if s.appeals[i].ID == appealID {
+			idx = i
+			break
+		}
 	}
-	s.comments = append(s.comments, comment)
-	return comment
+	if idx < 0 {
+		return Appeal{}, ErrNotFound
+	}
+	appeal := s.appeals[idx]
+	if appeal.Status != AppealPending {
+		return Appeal{}, ErrConflict
+	}
+
+	appeal.Status = AppealRejected
+	appeal.Outcome = "rejected"
+	if accept {
+		appeal.Status = AppealAccepted
+		appeal.Outcome = s.reverseAppealTarget(appeal)
+	}
+	appeal.Note = strings.TrimSpace(note)
+	appeal.HandledBy = strings.TrimSpace(handledBy)
+	appeal.UpdatedAt = now()
+	s.appeals[idx] = appeal
+	return appeal, nil
 }
 
-// SoftDeleteComment marks a comment as deleted. Only the comment author can delete it in the demo.
-func (s *Store) SoftDeleteComment(postID, commentID, actorUserID string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// reverseAppealTarget undoes what the appealed report or sanction did and describes it.
+// Callers must hold s.mu.
+func (s *Store) reverseAppealTarget(appeal Appeal) string {
+	var lifted []string
+	revoke := func(match func(Sanction) bool) {
+		for i := range s.sanctions {
+			if match(s.sanctions[i]) && s.sanctions[i].RevokedAt == "" {
+				s.sanctions[i].RevokedAt = now()
+				lifted = append(lifted, s.sanctions[i].ID)
+			}
+		}
+	}
 
-	for idx, comment := range s.comments {
-		if comment.PostID != postID || comment.ID != commentID {
-			continue
+	switch appeal.TargetType {
+	case AppealTargetReport:
+		report, _ := s.findReport(appeal.TargetID)
+		if report.Action == ReportActionRemoveContent {
+			if s.restoreReportTarget(report.TargetType, report.TargetID) {
+				return fmt.Sprintf("%s %s restored", report.TargetType, report.TargetID)
+			}
+			return "nothing to restore"
 		}
-		if comment.DeletedAt != "" {
-			return ErrNotFound
+		revoke(func(sanction Sanction) bool { return sanction.ReportID == report.ID })
+	case AppealTargetSanction:
+		revoke(func(sanction Sanction) bool { return sanction.ID == appeal.TargetID })
+	}
+	return appealLiftedOutcome(lifted)
+}
+
+// restoreReportTarget clears deleted_at on a removed post/comment/message, and the hidden flag on
+// a post/comment that was auto-hidden or held for review, so it is visible again. It reports
+// whether anything changed. Callers must hold s.mu.
+func (s *Store) restoreReportTarget(targetType, targetID string) bool {
+	restored := s.unhideReportTarget(targetType, targetID)
+	switch targetType {
+	case "post":
+		for idx := range s.posts {
+			if s.posts[idx].ID == targetID && s.posts[idx].DeletedAt != "" {
+				s.posts[idx].DeletedAt = ""
+				return true
+			}
 		}
-		if comment.AuthorID != actorUserID {
-			return ErrForbidden
+	case "comment":
+		for idx := range s.comments {
+			if s.comments[idx].ID == targetID && s.comments[idx].DeletedAt != "" {
+				s.comments[idx].DeletedAt = ""
+				return true
+			}
+		}
+	case "message":
+		if message := s.findMessage(targetID); message != nil && message.DeletedAt != "" {
+			message.DeletedAt = ""
+			return true
 		}
-		comment.DeletedAt = now()
-		s.comments[idx] = comment
-		return nil
 	}
-	return ErrNotFound
+	return restored
 }
 
-// CommentCount returns the number of non-deleted comments for a post.
-func (s *Store) CommentCount(postID string) int {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func (s *Store) findReport(reportID string) (Report, bool) {
+	for _, report := range s.reports {
+		if report.ID == reportID {
+			return report, true
+		}
+	}
+	return Report{}, false
+}
 
-	count := 0
-	for _, comment := range s.comments {
-		if comment.PostID == postID && comment.DeletedAt == "" {
-			count++
+func (s *Store) findSanction(sanctionID string) (Sanction, bool) {
+	for _, sanction := range s.sanctions {
+		if sanction.ID == sanctionID {
+			return sanction, true
 		}
 	}
-	return count
+	return Sanction{}, false
 }
 
-// PostScore returns the aggregated vote score for a post.
-func (s *Store) PostScore(postID string) int {
+// FilterWords returns the sensitive-word list in insertion order.
+func (s *Store) FilterWords() []FilterWord {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if !s.postExists(postID) {
-		return 0
-	}
-	return sumVotes(s.postVotes[postID])
+	out := make([]FilterWord, len(s.filterWords))
+	copy(out, s.filterWords)
+	return out
 }
 
-// PostVote returns the current user's vote value (-1/0/1) on a post.
-func (s *Store) PostVote(postID, userID string) int {
+// AddFilterWord adds a word to the list; words are unique case-insensitively.
+func (s *Store) AddFilterWord(word, category, createdBy string) (FilterWord, error) {
+	word, category, ok := normalizeFilterWord(word, category)
+	if !ok {
+		return FilterWord{}, ErrInvalidInput
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if userID == "" {
-		return 0
+	for _, existing := range s.filterWords {
+		if strings.EqualFold(existing.Word, word) {
+			return FilterWord{}, ErrConflict
+		}
 	}
-	votes := s.postVotes[postID]
-	if votes == nil {
-		return 0
+	s.nextFilter++
+	entry := FilterWord{
+		ID:        fmt.Sprintf("fw_%d", s.nextFilter),
+		Word:      word,
+		Category:  category,
+		CreatedBy: createdBy,
+		CreatedAt: now(),
 	}
-	return votes[userID]
+	s.filterWords = append(s.filterWords, entry)
+	return entry, nil
 }
 
-// VotePost upserts a user's vote on a post and returns the new score and my_vote.
-func (s *Store) VotePost(postID, userID string, value int) (int, int, error) {
-	if value != 1 && value != -1 {
-		return 0, 0, ErrInvalidInput
-	}
-	if strings.TrimSpace(userID) == "" {
-		return 0, 0, ErrInvalidInput
+// RemoveFilterWord deletes a word from the list.
+func (s *Store) RemoveFilterWord(wordID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, existing := range s.filterWords {
+		if existing.ID == wordID {
+			s.filterWords = append(s.filterWords[:i], s.filterWords[i+1:]...)
+			return nil
+		}
 	}
+	return ErrNotFound
+}
 
+// FilterCategories returns the configured category actions sorted by name.
+func (s *Store) FilterCategories() []FilterCategory {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if !s.postExists(postID) {
-		return 0, 0, ErrNotFound
+	out := make([]FilterCategory, 0, len(s.filterCats))
+	for name, action := range s.filterCats {
+		out = append(out, FilterCategory{Name: name, Action: action})
 	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
 
-	if s.postVotes[postID] == nil {
-		s.postVotes[postID] = map[string]int{}
+// SetFilterCategory sets the action of a category; the action itself is validated by the caller.
+func (s *Store) SetFilterCategory(name, action string) error {
+	name = strings.TrimSpace(name)
+	action = strings.TrimSpace(action)
+	if name == "" || action == "" {
+		return ErrInvalidInput
 	}
-	s.postVotes[postID][userID] = value
-	score := sumVotes(s.postVotes[postID])
-	return score, value, nil
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.filterCats[name] = action
+	return nil
 }
 
-// ClearPostVote removes a user's vote and returns the new score and my_vote.
-func (s *Store) ClearPostVote(postID, userID string) (int, int, error) {
-	if strings.TrimSpace(userID) == "" {
-		return 0, 0, ErrInvalidInput
+// RecordActivity marks the user as active on day (YYYY-MM-DD).
+func (s *Store) RecordActivity(userID, day string) error {
+	if userID == "" || day == "" {
+		return ErrInvalidInput
 	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if !s.postExists(postID) {
-		return 0, 0, ErrNotFound
+	if s.activity[day] == nil {
+		s.activity[day] = map[string]bool{}
 	}
+	s.activity[day][userID] = true
+	return nil
+}
 
-	if votes := s.postVotes[postID]; votes != nil {
-		delete(votes, userID)
+// SiteStats aggregates dashboard numbers for the days from..to (inclusive, YYYY-MM-DD).
+func (s *Store) SiteStats(from, to string, topBoards int) (SiteStats, error) {
+	days, ok := dayRange(from, to)
+	if !ok {
+		return SiteStats{}, ErrInvalidInput
+	}
+	inRange := func(createdAt string) (string, bool) {
+		day := dayOf(createdAt)
+		return day, day >= from && day <= to
 	}
-	score := sumVotes(s.postVotes[postID])
-	return score, 0, nil
-}
 
-// CommentScore returns the aggregated vote score for a comment.
-func (s *Store) CommentScore(postID, commentID string) int {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if !s.commentExists(postID, commentID) {
-		return 0
+	active := map[string]int{}
+	for _, day := range days {
+		active[day] = len(s.activity[day])
 	}
-	return sumVotes(s.commentVotes[commentID])
+	registrations := map[string]int{}
+	for _, user := range s.users {
+		if day, ok := inRange(user.CreatedAt); ok {
+			registrations[day]++
+		}
+	}
+
+	boards := map[string]*BoardActivity{}
+	boardOf := func(boardID string) *BoardActivity {
+		if boards[boardID] == nil {
+			boards[boardID] = &BoardActivity{BoardID: boardID}
+		}
+		return boards[boardID]
+	}
+	posts := map[string]int{}
+	postBoards := map[string]string{}
+	for _, post := range s.posts {
+		postBoards[post.ID] = post.BoardID
+		if day, ok := inRange(post.CreatedAt); ok {
+			posts[day]++
+			boardOf(post.BoardID).Posts++
+		}
+	}
+	comments := map[string]int{}
+	for _, comment := range s.comments {
+		if day, ok := inRange(comment.CreatedAt); ok {
+			comments[day]++
+			boardOf(postBoards[comment.PostID]).Comments++
+		}
+	}
+	messages := map[string]int{}
+	for _, roomMessages := range s.messages {
+		for _, msg := range roomMessages {
+			if day, ok := inRange(msg.CreatedAt); ok {
+				messages[day]++
+			}
+		}
+	}
+
+	stats := SiteStats{
+		ActiveUsers:   dailySeries(days, active),
+		Registrations: dailySeries(days, registrations),
+		Posts:         dailySeries(days, posts),
+		Comments:      dailySeries(days, comments),
+		Messages:      dailySeries(days, messages),
+	}
+	for _, board := range s.boards {
+		if activity, ok := boards[board.ID]; ok {
+			activity.Name = board.Name
+			stats.TopBoards = append(stats.TopBoards, *activity)
+		}
+	}
+	stats.TopBoards = topBoardActivity(stats.TopBoards, topBoards)
+	for _, report := range s.reports {
+		if report.Status == "open" {
+			stats.OpenReports++
+		}
+	}
+	for _, file := range s.files {
+		stats.FileCount++
+		stats.StorageBytes += file.Size
+	}
+	return stats, nil
 }
 
-// CommentVote returns the current user's vote value (-1/0/1) on a comment.
-func (s *Store) CommentVote(postID, commentID, userID string) int {
+// UserKarma sums the votes received on a user's live posts and comments.
+func (s *Store) UserKarma(userID string) int {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if strings.TrimSpace(userID) == "" || !s.commentExists(postID, commentID) {
-		return 0
+	karma := 0
+	for _, post := range s.posts {
+		if post.AuthorID == userID && post.DeletedAt == "" {
+			karma += sumVotes(s.postVotes[post.ID])
+		}
 	}
-	votes := s.commentVotes[commentID]
-	if votes == nil {
-		return 0
+	for _, comment := range s.comments {
+		if comment.AuthorID == userID && comment.DeletedAt == "" {
+			karma += sumVotes(s.commentVotes[comment.ID])
+		}
 	}
-	return votes[userID]
+	return karma
 }
 
-// VoteComment upserts a user's vote on a comment and returns the new score and my_vote.
-func (s *Store) VoteComment(postID, commentID, userID string, value int) (int, int, error) {
+// Boards returns the list of boards.
+func (s *Store) Boards() []Board {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if value != 1 && value != -1 {
-		return 0, 0, ErrInvalidInput
-	}
-	if strings.TrimSpace(userID) == "" {
-		return 0, 0, ErrInvalidInput
+	boards := make([]Board, len(s.boards))
+	copy(boards, s.boards)
+	return boards
+}
+
+// GetBoard returns a board by ID.
+func (s *Store) GetBoard(boardID string) (Board, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, board := range s.boards {
+		if board.ID == boardID {
+			return board, true
+		}
 	}
-	if !s.commentExists(postID, commentID) {
-		return 0, 0, ErrNotFound
+	return Board{}, false
+}
+
+// CreateBoard adds a board with a generated ID. Board names must be unique.
+func (s *Store) CreateBoard(board Board) (Board, error) {
+	board = normalizeBoard(board)
+	if !validBoardFields(board.Name, board.Description, board.Icon, board.Rules, board.QQGroup) {
+		return Board{}, ErrInvalidInput
 	}
 
-	if s.commentVotes[commentID] == nil {
-		s.commentVotes[commentID] = map[string]int{}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.createBoardLocked(board)
+}
+
+func (s *Store) createBoardLocked(board Board) (Board, error) {
+	for _, existing := range s.boards {
+		if strings.EqualFold(existing.Name, board.Name) {
+			return Board{}, ErrConflict
+		}
 	}
-	s.commentVotes[commentID][userID] = value
-	score := sumVotes(s.commentVotes[commentID])
-	return score, value, nil
+	s.nextBoardID++
+	board.ID = fmt.Sprintf("b_%d", s.nextBoardID)
+	s.boards = append(s.boards, board)
+	return board, nil
 }
 
-// ClearCommentVote removes a user's vote and returns the new score and my_vote.
-func (s *Store) ClearCommentVote(postID, commentID, userID string) (int, int, error) {
-	if strings.TrimSpace(userID) == "" {
-		return 0, 0, ErrInvalidInput
+// UpdateBoard replaces the editable fields of an existing board.
+func (s *Store) UpdateBoard(board Board) (Board, error) {
+	board = normalizeBoard(board)
+	if board.ID == "" || !validBoardFields(board.Name, board.Description, board.Icon, board.Rules, board.QQGroup) {
+		return Board{}, ErrInvalidInput
 	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if !s.commentExists(postID, commentID) {
-		return 0, 0, ErrNotFound
+	idx := -1
+	for i, existing := range s.boards {
+		if existing.ID == board.ID {
+			idx = i
+		} else if strings.EqualFold(existing.Name, board.Name) {
+			return Board{}, ErrConflict
+		}
 	}
-
-	if votes := s.commentVotes[commentID]; votes != nil {
-		delete(votes, userID)
+	if idx < 0 {
+		return Board{}, ErrNotFound
 	}
-	score := sumVotes(s.commentVotes[commentID])
-	return score, 0, nil
+	s.boards[idx] = board
+	return board, nil
 }
 
-// SaveFile stores file metadata and returns it.
-func (s *Store) SaveFile(uploaderID, filename, storageKey, storagePath string) FileMeta {
+// DeleteBoard removes a board that has never had posts; otherwise it returns
+// ErrConflict and the board should be archived instead.
+func (s *Store) DeleteBoard(boardID string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.nextFileID++
-	file := FileMeta{
-		ID:          fmt.Sprintf("f_%d", s.nextFileID),
-		UploaderID:  uploaderID,
-		Filename:    filename,
-		StorageKey:  storageKey,
-		StoragePath: storagePath,
-		CreatedAt:   now(),
+	idx := -1
+	for i, board := range s.boards {
+		if board.ID == boardID {
+			idx = i
+			break
+		}
 	}
-	s.files[file.ID] = file
-	return file
+	if idx < 0 {
+		return ErrNotFound
+	}
+	for _, post := range s.posts {
+		if post.BoardID == boardID {
+			return ErrConflict
+		}
+	}
+	s.boards = append(s.boards[:idx], s.boards[idx+1:]...)
+	delete(s.boardSubs, boardID)
+	moderators := s.moderators[:0]
+	for _, moderator := range s.moderators {
+		if moderator.BoardID != boardID {
+			moderators = append(moderators, moderator)
+		}
+	}
+	s.moderators = moderators
+	return nil
 }
 
-// GetFile looks up file metadata by ID.
-func (s *Store) GetFile(fileID string) (FileMeta, bool) {
+// AddBoardModerator assigns a moderator to a board. Assigning twice is a no-op.
+func (s *Store) AddBoardModerator(boardID, userID, grantedBy string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	file, ok := s.files[fileID]
-	return file, ok
+	if !s.boardExists(boardID) {
+		return ErrNotFound
+	}
+	if _, ok := s.users[userID]; !ok {
+		return ErrInvalidInput
+	}
+	for _, moderator := range s.moderators {
+		if moderator.BoardID == boardID && moderator.UserID == userID {
+			return nil
+		}
+	}
+	s.moderators = append(s.moderators, BoardModerator{
+		BoardID:   boardID,
+		UserID:    userID,
+		GrantedBy: grantedBy,
+		CreatedAt: now(),
+	})
+	return nil
 }
 
-// AddMessage appends a message to a room history and returns it.
-func (s *Store) AddMessage(roomID, senderID, content string) ChatMessage {
+// RemoveBoardModerator revokes a moderator assignment.
+func (s *Store) RemoveBoardModerator(boardID, userID string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.nextMsgID++
-	message := ChatMessage{
-		ID:        fmt.Sprintf("m_%d", s.nextMsgID),
-		RoomID:    roomID,
-		SenderID:  senderID,
-		Content:   content,
-		CreatedAt: now(),
+	for idx, moderator := range s.moderators {
+		if moderator.BoardID == boardID && moderator.UserID == userID {
+			s.moderators = append(s.moderators[:idx], s.moderators[idx+1:]...)
+			return nil
+		}
 	}
-	s.messages[roomID] = append(s.messages[roomID], message)
-	return message
+	return ErrNotFound
 }
 
-// Messages returns the last N messages for the room (or all if limit <= 0).
-func (s *Store) Messages(roomID string, limit int) []ChatMessage {
+// BoardModerators lists a board's moderators in assignment order.
+func (s *Store) BoardModerators(boardID string) []BoardModerator {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	messages := s.messages[roomID]
-	if len(messages) == 0 {
-		return nil
-	}
-	if limit <= 0 || limit >= len(messages) {
-		out := make([]ChatMessage, len(messages))
-		copy(out, messages)
-		return out
+	var out []BoardModerator
+	for _, moderator := range s.moderators {
+		if moderator.BoardID == boardID {
+			out = append(out, moderator)
+		}
 	}
-	out := make([]ChatMessage, limit)
-	copy(out, messages[len(messages)-limit:])
 	return out
 }
 
-func (s *Store) CreateReport(reporterID, targetType, targetID, reason, detail string) (Report, error) {
+// IsBoardModerator reports whether the user moderates the board.
+func (s *Store) IsBoardModerator(boardID, userID string) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.nextReport++
-	report := Report{
-		ID:         fmt.Sprintf("r_%d", s.nextReport),
-		TargetType: strings.TrimSpace(targetType),
-		TargetID:   strings.TrimSpace(targetID),
-		ReporterID: reporterID,
-		Reason:     strings.TrimSpace(reason),
-		Detail:     strings.TrimSpace(detail),
-		Status:     "open",
-		CreatedAt:  now(),
-		UpdatedAt:  now(),
-	}
-	if report.TargetType == "" || report.TargetID == "" || report.Reason == "" {
-		return Report{}, ErrInvalidInput
+	for _, moderator := range s.moderators {
+		if moderator.BoardID == boardID && moderator.UserID == userID {
+			return true
+		}
 	}
-	s.reports = append(s.reports, report)
-	return report, nil
+	return false
 }
 
-func (s *Store) Reports(status string, page, pageSize int) ([]Report, int, error) {
+// ModeratedBoardIDs returns the boards the user moderates.
+func (s *Store) ModeratedBoardIDs(userID string) []string {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	trimmed := strings.TrimSpace(status)
-	filtered := make([]Report, 0, len(s.reports))
-	for _, r := range s.reports {
-		if trimmed == "" || r.Status == trimmed {
-			filtered = append(filtered, r)
+	var out []string
+	for _, moderator := range s.moderators {
+		if moderator.UserID == userID {
+			out = append(out, moderator.BoardID)
 		}
 	}
-	total := len(filtered)
-	if page <= 0 {
-		page = 1
-	}
-	if pageSize <= 0 {
-		pageSize = 20
+	return out
+}
+
+// CreateBoardApplication files a pending request for a new board.
+func (s *Store) CreateBoardApplication(app BoardApplication) (BoardApplication, error) {
+	app = normalizeBoardApplication(app)
+	if app.ApplicantID == "" || !validBoardFields(app.Name, app.Description, app.Icon, app.Rules, app.QQGroup) {
+		return BoardApplication{}, ErrInvalidInput
 	}
-	start := (page - 1) * pageSize
-	if start > total {
-		start = total
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, board := range s.boards {
+		if strings.EqualFold(board.Name, app.Name) {
+			return BoardApplication{}, ErrConflict
+		}
 	}
-	end := start + pageSize
-	if end > total {
-		end = total
+	for _, existing := range s.boardApps {
+		if existing.Status == "pending" && strings.EqualFold(existing.Name, app.Name) {
+			return BoardApplication{}, ErrConflict
+		}
 	}
-	out := make([]Report, end-start)
-	copy(out, filtered[start:end])
-	return out, total, nil
+
+	s.nextBoardApp++
+	app.ID = fmt.Sprintf("ba_%d", s.nextBoardApp)
+	app.Status = "pending"
+	app.ReviewNote = ""
+	app.ReviewedBy = ""
+	app.BoardID = ""
+	app.CreatedAt = now()
+	app.UpdatedAt = app.CreatedAt
+	s.boardApps = append(s.boardApps, app)
+	return app, nil
 }
 
-func (s *Store) UpdateReport(reportID, status, action, note, handledBy string) (Report, error) {
+// BoardApplications lists applications newest first, optionally filtered by status and applicant.
+func (s *Store) BoardApplications(status, applicantID string, page, pageSize int) ([]BoardApplication, int, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	trimmedID := strings.TrimSpace(reportID)
-	trimmedStatus := strings.TrimSpace(status)
-	if trimmedID == "" || trimmedStatus == "" {
-		return Report{}, ErrInvalidInput
+	status = strings.TrimSpace(status)
+	applicantID = strings.TrimSpace(applicantID)
+	var filtered []BoardApplication
+	for i := len(s.boardApps) - 1; i >= 0; i-- {
+		app := s.boardApps[i]
+		if status != "" && app.Status != status {
+			continue
+		}
+		if applicantID != "" && app.ApplicantID != applicantID {
+			continue
+		}
+		filtered = append(filtered, app)
 	}
+	start, end := pageBounds(len(filtered), page, pageSize)
+	out := make([]BoardApplication, end-start)
+	copy(out, filtered[start:end])
+	return out, len(filtered), nil
+}
 
-	for idx, report := range s.reports {
-		if report.ID != trimmedID {
+// ReviewBoardApplication approves (creating the board) or rejects a pending application.
+func (s *Store) ReviewBoardApplication(appID string, approve bool, note, reviewerID string) (BoardApplication, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for idx, app := range s.boardApps {
+		if app.ID != appID {
 			continue
 		}
-		report.Status = trimmedStatus
-		report.Action = strings.TrimSpace(action)
-		report.Note = strings.TrimSpace(note)
-		report.HandledBy = strings.TrimSpace(handledBy)
-		report.UpdatedAt = now()
-		s.reports[idx] = report
-		return report, nil
+		if app.Status != "pending" {
+			return BoardApplication{}, ErrConflict
+		}
+		if approve {
+			board, err := s.createBoardLocked(Board{
+				Name:        app.Name,
+				Description: app.Description,
+				Icon:        app.Icon,
+				Rules:       app.Rules,
+				QQGroup:     app.QQGroup,
+			})
+			if err != nil {
+				return BoardApplication{}, err
+			}
+			app.Status = "approved"
+			app.BoardID = board.ID
+			s.moderators = append(s.moderators, BoardModerator{
+				BoardID:   board.ID,
+				UserID:    app.ApplicantID,
+				GrantedBy: reviewerID,
+				CreatedAt: now(),
+			})
+		} else {
+			app.Status = "rejected"
+		}
+		app.ReviewNote = strings.TrimSpace(note)
+		app.ReviewedBy = reviewerID
+		app.UpdatedAt = now()
+		s.boardApps[idx] = app
+		return app, nil
 	}
-	return Report{}, ErrNotFound
+	return BoardApplication{}, ErrNotFound
 }
 
-// now returns the current time in UTC RFC3339 format.
-func now() string {
+// SubscribeBoard subscribes the user to a board. Subscribing twice is a no-op.
+func (s *Store) SubscribeBoard(boardID, userID string) error {
+	if strings.TrimSpace(userID) == "" {
+		return ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.boardExists(boardID) {
+		return ErrNotFound
+	}
+	if s.boardSubs[boardID] == nil {
+		s.boardSubs[boardID] = map[string]string{}
+	}
+	if _, ok := s.boardSubs[boardID][userID]; !ok {
+		s.boardSubs[boardID][userID] = now()
+	}
+	return nil
+}
+
+// UnsubscribeBoard removes the user's subscription. Removing a missing subscription is a no-op.
+func (s *Store) UnsubscribeBoard(boardID, userID string) error {
+	if strings.TrimSpace(userID) == "" {
+		return ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.boardExists(boardID) {
+		return ErrNotFound
+	}
+	delete(s.boardSubs[boardID], userID)
+	return nil
+}
+
+// BoardSubscribed reports whether the user is subscribed to the board.
+func (s *Store) BoardSubscribed(boardID, userID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.boardSubs[boardID][userID]
+	return ok
+}
+
+// BoardSubscriberCount returns the number of users subscribed to the board.
+func (s *Store) BoardSubscriberCount(boardID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.boardSubs[boardID])
+}
+
+// SubscribedBoardIDs returns the IDs of boards the user subscribes to, in board order.
+func (s *Store) SubscribedBoardIDs(userID string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []string
+	for _, board := range s.boards {
+		if _, ok := s.boardSubs[board.ID][userID]; ok {
+			out = append(out, board.ID)
+		}
+	}
+	return out
+}
+
+// Posts returns posts for a board. If boardID is empty, it returns all posts.
+func (s *Store) Posts(boardID string) []Post {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if boardID == "" {
+		out := make([]Post, 0, len(s.posts))
+		for _, post := range s.posts {
+			if post.Listed() {
+				out = append(out, post)
+			}
+		}
+		return out
+	}
+
+	filtered := make([]Post, 0, len(s.posts))
+	for _, post := range s.posts {
+		if post.BoardID == boardID && post.Listed() {
+			filtered = append(filtered, post)
+		}
+	}
+	return filtered
+}
+
+// GetPost returns a post by ID.
+func (s *Store) GetPost(postID string) (Post, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, post := range s.posts {
+		if post.ID == postID && post.DeletedAt == "" {
+			return post, true
+		}
+	}
+	return Post{}, false
+}
+
+// LookupPost returns a post by ID, including soft-deleted ones.
+func (s *Store) LookupPost(postID string) (Post, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, post := range s.posts {
+		if post.ID == postID {
+			return post, true
+		}
+	}
+	return Post{}, false
+}
+
+// SetPostDeleted removes or restores a post on behalf of a moderator (no author check).
+func (s *Store) SetPostDeleted(postID string, deleted bool) error {
+	return s.updatePost(postID, func(post *Post) {
+		post.DeletedAt = stampIf(deleted, post.DeletedAt)
+	})
+}
+
+// SetPostPinned pins or unpins a post.
+func (s *Store) SetPostPinned(postID string, pinned bool) error {
+	return s.updatePost(postID, func(post *Post) {
+		post.PinnedAt = stampIf(pinned, post.PinnedAt)
+	})
+}
+
+// SetPostLocked locks or unlocks a post for new comments.
+func (s *Store) SetPostLocked(postID string, locked bool) error {
+	return s.updatePost(postID, func(post *Post) {
+		post.LockedAt = stampIf(locked, post.LockedAt)
+	})
+}
+
+// SetPostHidden hides a post from listings (auto-hide after reports) or shows it again.
+func (s *Store) SetPostHidden(postID string, hidden bool) error {
+	return s.updatePost(postID, func(post *Post) {
+		post.HiddenAt = stampIf(hidden, post.HiddenAt)
+	})
+}
+
+func (s *Store) updatePost(postID string, update func(post *Post)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for idx := range s.posts {
+		if s.posts[idx].ID == postID {
+			update(&s.posts[idx])
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// CreatePost appends a post to the store and returns it.
+// Attachments must be unattached files uploaded by the author; otherwise nothing is
+// created and ErrForbidden is returned.
+func (s *Store) CreatePost(boardID, authorID, title, content string, attachmentIDs []string) (Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.canAttach(authorID, attachmentIDs) {
+		return Post{}, ErrForbidden
+	}
+
+	s.nextPostID++
+	post := Post{
+		ID:        fmt.Sprintf("p_%d", s.nextPostID),
+		BoardID:   boardID,
+		AuthorID:  authorID,
+		Title:     title,
+		Content:   content,
+		CreatedAt: now(),
+	}
+	s.posts = append(s.posts, post)
+	s.attach(attachmentIDs, post.ID, "")
+	return post, nil
+}
+
+// SoftDeletePost marks a post as deleted. Only the post author can delete it in the demo.
+func (s *Store) SoftDeletePost(postID, actorUserID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for idx, post := range s.posts {
+		if post.ID != postID {
+			continue
+		}
+		if post.DeletedAt != "" {
+			return ErrNotFound
+		}
+		if post.AuthorID != actorUserID {
+			return ErrForbidden
+		}
+		post.DeletedAt = now()
+		s.posts[idx] = post
+		return nil
+	}
+	return ErrNotFound
+}
+
+// PurgePost permanently removes a post together with its comments, votes and
+// attachment metadata. The removed attachments are returned so the caller can
+// delete the stored files.
+func (s *Store) PurgePost(postID string) ([]FileMeta, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := -1
+	for i, post := range s.posts {
+		if post.ID == postID {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil, ErrNotFound
+	}
+	s.posts = append(s.posts[:idx], s.posts[idx+1:]...)
+	delete(s.postVotes, postID)
+
+	bookmarks := s.bookmarks[:0]
+	for _, bookmark := range s.bookmarks {
+		if bookmark.PostID != postID {
+			bookmarks = append(bookmarks, bookmark)
+		}
+	}
+	s.bookmarks = bookmarks
+
+	comments := s.comments[:0]
+	for _, comment := range s.comments {
+		if comment.PostID == postID {
+			delete(s.commentVotes, comment.ID)
+			continue
+		}
+		comments = append(comments, comment)
+	}
+	s.comments = comments
+
+	var removed []FileMeta
+	for id, file := range s.files {
+		if file.PostID == postID {
+			removed = append(removed, file)
+			delete(s.files, id)
+		}
+	}
+	sortFiles(removed)
+	return removed, nil
+}
+
+// Comments returns all comments under the given post.
+func (s *Store) Comments(postID string) []Comment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filtered := make([]Comment, 0, len(s.comments))
+	for _, comment := range s.comments {
+		if comment.PostID == postID && comment.Listed() {
+			filtered = append(filtered, comment)
+		}
+	}
+	return filtered
+}
+
+// GetComment returns a comment by ID under the given post.
+func (s *Store) GetComment(postID, commentID string) (Comment, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, comment := range s.comments {
+		if comment.PostID == postID && comment.ID == commentID && comment.DeletedAt == "" {
+			return comment, true
+		}
+	}
+	return Comment{}, false
+}
+
+// LookupComment returns a comment by ID regardless of post, including soft-deleted ones.
+func (s *Store) LookupComment(commentID string) (Comment, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, comment := range s.comments {
+		if comment.ID == commentID {
+			return comment, true
+		}
+	}
+	return Comment{}, false
+}
+
+// SetCommentDeleted removes or restores a comment on behalf of a moderator (no author check).
+func (s *Store) SetCommentDeleted(postID, commentID string, deleted bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for idx, comment := range s.comments {
+		if comment.PostID == postID && comment.ID == commentID {
+			comment.DeletedAt = stampIf(deleted, comment.DeletedAt)
+			s.comments[idx] = comment
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// SetCommentHidden hides a comment from listings (auto-hide after reports) or shows it again.
+func (s *Store) SetCommentHidden(postID, commentID string, hidden bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for idx, comment := range s.comments {
+		if comment.PostID == postID && comment.ID == commentID {
+			comment.HiddenAt = stampIf(hidden, comment.HiddenAt)
+			s.comments[idx] = comment
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// CreateComment appends a comment to the store and returns it.
+// Attachments follow the same rules as CreatePost.
+func (s *Store) CreateComment(postID, authorID, content, parentID string, attachmentIDs []string) (Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.canAttach(authorID, attachmentIDs) {
+		return Comment{}, ErrForbidden
+	}
+
+	s.nextComment++
+	comment := Comment{
+		ID:        fmt.Sprintf("c_%d", s.nextComment),
+		PostID:    postID,
+		ParentID:  parentID,
+		AuthorID:  authorID,
+		Content:   content,
+		CreatedAt: now(),
+	}
+	s.comments = append(s.comments, comment)
+	s.attach(attachmentIDs, postID, comment.ID)
+	return comment, nil
+}
+
+// SoftDeleteComment marks a comment as deleted. Only the comment author can delete it in the demo.
+func (s *Store) SoftDeleteComment(postID, commentID, actorUserID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for idx, comment := range s.comments {
+		if comment.PostID != postID || comment.ID != commentID {
+			continue
+		}
+		if comment.DeletedAt != "" {
+			return ErrNotFound
+		}
+		if comment.AuthorID != actorUserID {
+			return ErrForbidden
+		}
+		comment.DeletedAt = now()
+		s.comments[idx] = comment
+		return nil
+	}
+	return ErrNotFound
+}
+
+// CommentCount returns the number of non-deleted comments for a post.
+func (s *Store) CommentCount(postID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, comment := range s.comments {
+		if comment.PostID == postID && comment.Listed() {
+			count++
+		}
+	}
+	return count
+}
+
+// PostScore returns the aggregated vote score for a post.
+func (s *Store) PostScore(postID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.postExists(postID) {
+		return 0
+	}
+	return sumVotes(s.postVotes[postID])
+}
+
+// PostVote returns the current user's vote value (-1/0/1) on a post.
+func (s *Store) PostVote(postID, userID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if userID == "" {
+		return 0
+	}
+	votes := s.postVotes[postID]
+	if votes == nil {
+		return 0
+	}
+	return votes[userID]
+}
+
+// VotePost upserts a user's vote on a post and returns the new score and my_vote.
+func (s *Store) VotePost(postID, userID string, value int) (int, int, error) {
+	if value != 1 && value != -1 {
+		return 0, 0, ErrInvalidInput
+	}
+	if strings.TrimSpace(userID) == "" {
+		return 0, 0, ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.postExists(postID) {
+		return 0, 0, ErrNotFound
+	}
+
+	if s.postVotes[postID] == nil {
+		s.postVotes[postID] = map[string]int{}
+	}
+	s.postVotes[postID][userID] = value
+	score := sumVotes(s.postVotes[postID])
+	return score, value, nil
+}
+
+// ClearPostVote removes a user's vote and returns the new score and my_vote.
+func (s *Store) ClearPostVote(postID, userID string) (int, int, error) {
+	if strings.TrimSpace(userID) == "" {
+		return 0, 0, ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.postExists(postID) {
+		return 0, 0, ErrNotFound
+	}
+
+	if votes := s.postVotes[postID]; votes != nil {
+		delete(votes, userID)
+	}
+	score := sumVotes(s.postVotes[postID])
+	return score, 0, nil
+}
+
+// CommentScore returns the aggregated vote score for a comment.
+func (s *Store) CommentScore(postID, commentID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.commentExists(postID, commentID) {
+		return 0
+	}
+	return sumVotes(s.commentVotes[commentID])
+}
+
+// CommentVote returns the current user's vote value (-1/0/1) on a comment.
+func (s *Store) CommentVote(postID, commentID, userID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if strings.TrimSpace(userID) == "" || !s.commentExists(postID, commentID) {
+		return 0
+	}
+	votes := s.commentVotes[commentID]
+	if votes == nil {
+		return 0
+	}
+	return votes[userID]
+}
+
+// VoteComment upserts a user's vote on a comment and returns the new score and my_vote.
+func (s *Store) VoteComment(postID, commentID, userID string, value int) (int, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if value != 1 && value != -1 {
+		return 0, 0, ErrInvalidInput
+	}
+	if strings.TrimSpace(userID) == "" {
+		return 0, 0, ErrInvalidInput
+	}
+	if !s.commentExists(postID, commentID) {
+		return 0, 0, ErrNotFound
+	}
+
+	if s.commentVotes[commentID] == nil {
+		s.commentVotes[commentID] = map[string]int{}
+	}
+	s.commentVotes[commentID][userID] = value
+	score := sumVotes(s.commentVotes[commentID])
+	return score, value, nil
+}
+
+// ClearCommentVote removes a user's vote and returns the new score and my_vote.
+func (s *Store) ClearCommentVote(postID, commentID, userID string) (int, int, error) {
+	if strings.TrimSpace(userID) == "" {
+		return 0, 0, ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.commentExists(postID, commentID) {
+		return 0, 0, ErrNotFound
+	}
+
+	if votes := s.commentVotes[commentID]; votes != nil {
+		delete(votes, userID)
+	}
+	score := sumVotes(s.commentVotes[commentID])
+	return score, 0, nil
+}
+
+// BookmarkPost saves a post for the user. Bookmarking twice is a no-op.
+func (s *Store) BookmarkPost(postID, userID string) error {
+	if strings.TrimSpace(userID) == "" {
+		return ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.postExists(postID) {
+		return ErrNotFound
+	}
+	for _, bookmark := range s.bookmarks {
+		if bookmark.PostID == postID && bookmark.UserID == userID {
+			return nil
+		}
+	}
+	s.bookmarks = append(s.bookmarks, Bookmark{UserID: userID, PostID: postID, CreatedAt: now()})
+	return nil
+}
+
+// UnbookmarkPost removes a saved post. Removing a missing bookmark is a no-op.
+func (s *Store) UnbookmarkPost(postID, userID string) error {
+	if strings.TrimSpace(userID) == "" {
+		return ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.postExists(postID) {
+		return ErrNotFound
+	}
+	for idx, bookmark := range s.bookmarks {
+		if bookmark.PostID == postID && bookmark.UserID == userID {
+			s.bookmarks = append(s.bookmarks[:idx], s.bookmarks[idx+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// PostBookmarked reports whether the user has saved the post.
+func (s *Store) PostBookmarked(postID, userID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, bookmark := range s.bookmarks {
+		if bookmark.PostID == postID && bookmark.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// Bookmarks returns the user's saved (non-deleted) posts, newest bookmark first.
+func (s *Store) Bookmarks(userID string, page, pageSize int) ([]Post, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var saved []Post
+	for i := len(s.bookmarks) - 1; i >= 0; i-- {
+		bookmark := s.bookmarks[i]
+		if bookmark.UserID != userID {
+			continue
+		}
+		for _, post := range s.posts {
+			if post.ID == bookmark.PostID && post.Listed() {
+				saved = append(saved, post)
+				break
+			}
+		}
+	}
+	start, end := pageBounds(len(saved), page, pageSize)
+	out := make([]Post, end-start)
+	copy(out, saved[start:end])
+	return out, len(saved), nil
+}
+
+// SaveFile stores file metadata and returns it.
+func (s *Store) SaveFile(uploaderID, filename, storageKey, storagePath, contentType string, size int64) FileMeta {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextFileID++
+	file := FileMeta{
+		ID:          fmt.Sprintf("f_%d", s.nextFileID),
+		UploaderID:  uploaderID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+		StorageKey:  storageKey,
+		StoragePath: storagePath,
+		CreatedAt:   now(),
+	}
+	s.files[file.ID] = file
+	return file
+}
+
+// GetFile looks up file metadata by ID.
+func (s *Store) GetFile(fileID string) (FileMeta, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, ok := s.files[fileID]
+	return file, ok
+}
+
+// Attachments returns the files attached to a post (commentID empty) or to one of its comments.
+func (s *Store) Attachments(postID, commentID string) []FileMeta {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []FileMeta
+	for _, file := range s.files {
+		if file.PostID == postID && file.CommentID == commentID {
+			out = append(out, file)
+		}
+	}
+	sortFiles(out)
+	return out
+}
+
+// PostAttachments returns the post-level attachments of the given posts, keyed by post ID.
+func (s *Store) PostAttachments(postIDs []string) map[string][]FileMeta {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wanted := make(map[string]bool, len(postIDs))
+	for _, id := range postIDs {
+		wanted[id] = true
+	}
+	out := map[string][]FileMeta{}
+	for _, file := range s.files {
+		if wanted[file.PostID] && file.CommentID == "" {
+			out[file.PostID] = append(out[file.PostID], file)
+		}
+	}
+	for postID := range out {
+		sortFiles(out[postID])
+	}
+	return out
+}
+
+// CommentAttachments returns the attachments of all comments on a post, keyed by comment ID.
+func (s *Store) CommentAttachments(postID string) map[string][]FileMeta {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := map[string][]FileMeta{}
+	for _, file := range s.files {
+		if file.PostID == postID && file.CommentID != "" {
+			out[file.CommentID] = append(out[file.CommentID], file)
+		}
+	}
+	for commentID := range out {
+		sortFiles(out[commentID])
+	}
+	return out
+}
+
+// ChatRooms lists every room, archived ones included, in creation order.
+func (s *Store) ChatRooms() []ChatRoom {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]ChatRoom, len(s.rooms))
+	copy(out, s.rooms)
+	return out
+}
+
+// GetChatRoom returns a room by ID, archived or not.
+func (s *Store) GetChatRoom(roomID string) (ChatRoom, bool) {
+	if postID, ok := postRoomPostID(roomID); ok {
+		post, ok := s.LookupPost(postID)
+		if !ok {
+			return ChatRoom{}, false
+		}
+		return postChatRoom(post), true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, room := range s.rooms {
+		if room.ID == roomID {
+			return room, true
+		}
+	}
+	return ChatRoom{}, false
+}
+
+// CreateChatRoom registers a new room; ID and CreatedAt are assigned here.
+func (s *Store) CreateChatRoom(room ChatRoom) (ChatRoom, error) {
+	room, ok := normalizeChatRoom(room)
+	if !ok {
+		return ChatRoom{}, ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextRoom++
+	room.ID = fmt.Sprintf("rm_%d", s.nextRoom)
+	room.CreatedAt = now()
+	s.rooms = append(s.rooms, room)
+	return room, nil
+}
+
+// ArchiveChatRoom closes a room to new joins and messages; its history is kept.
+func (s *Store) ArchiveChatRoom(roomID string) (ChatRoom, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.rooms {
+		if s.rooms[i].ID != roomID {
+			continue
+		}
+		if s.rooms[i].ArchivedAt != "" {
+			return ChatRoom{}, ErrConflict
+		}
+		s.rooms[i].ArchivedAt = now()
+		return s.rooms[i], nil
+	}
+	return ChatRoom{}, ErrNotFound
+}
+
+// CreateConversation starts a conversation between the creator and memberIDs. With a single
+// other member it is a direct conversation, and the existing one is returned if there is one.
+// Unknown users are ErrNotFound; a block between the creator and a member is ErrForbidden.
+func (s *Store) CreateConversation(creatorID string, memberIDs []string, title string) (Conversation, error) {
+	convo, ok := normalizeConversation(creatorID, memberIDs, title)
+	if !ok {
+		return Conversation{}, ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, member := range convo.Members {
+		if _, ok := s.users[member.UserID]; !ok {
+			return Conversation{}, ErrNotFound
+		}
+		if s.blockedLocked(member.UserID, creatorID) || s.blockedLocked(creatorID, member.UserID) {
+			return Conversation{}, ErrForbidden
+		}
+	}
+	if convo.Kind == ConversationDirect {
+		key := directKey(convo.MemberIDs())
+		for _, existing := range s.convos {
+			if existing.Kind == ConversationDirect && directKey(existing.MemberIDs()) == key {
+				return cloneConversation(existing), nil
+			}
+		}
+	}
+
+	s.nextConvo++
+	convo.ID = fmt.Sprintf("%s%d", conversationPrefix, s.nextConvo)
+	convo.CreatedAt = now()
+	for i := range convo.Members {
+		convo.Members[i].JoinedAt = convo.CreatedAt
+	}
+	s.convos = append(s.convos, convo)
+	return cloneConversation(convo), nil
+}
+
+func (s *Store) GetConversation(conversationID string) (Conversation, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, convo := range s.convos {
+		if convo.ID == conversationID {
+			return cloneConversation(convo), true
+		}
+	}
+	return Conversation{}, false
+}
+
+// Conversations lists the user's conversations, newest first.
+func (s *Store) Conversations(userID string) []Conversation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]Conversation, 0)
+	for i := len(s.convos) - 1; i >= 0; i-- {
+		if s.convos[i].HasMember(userID) {
+			out = append(out, cloneConversation(s.convos[i]))
+		}
+	}
+	return out
+}
+
+// MarkConversationRead moves the member's read position forward to seq (never backwards).
+func (s *Store) MarkConversationRead(conversationID, userID string, seq int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	convo := s.findConversation(conversationID)
+	if convo == nil || !convo.HasMember(userID) {
+		return ErrNotFound
+	}
+	for i := range convo.Members {
+		if convo.Members[i].UserID == userID && seq > convo.Members[i].LastReadSeq {
+			convo.Members[i].LastReadSeq = seq
+		}
+	}
+	return nil
+}
+
+// LeaveConversation removes the user from a group conversation. Direct conversations cannot
+// be left (ErrInvalidInput); block the other user instead.
+func (s *Store) LeaveConversation(conversationID, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	convo := s.findConversation(conversationID)
+	if convo == nil || !convo.HasMember(userID) {
+		return ErrNotFound
+	}
+	if convo.Kind != ConversationGroup {
+		return ErrInvalidInput
+	}
+	members := convo.Members[:0]
+	for _, member := range convo.Members {
+		if member.UserID != userID {
+			members = append(members, member)
+		}
+	}
+	convo.Members = members
+	return nil
+}
+
+// UnreadCount counts messages from other members after the user's read position.
+func (s *Store) UnreadCount(conversationID, userID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	convo := s.findConversation(conversationID)
+	if convo == nil {
+		return 0
+	}
+	lastRead := -1
+	for _, member := range convo.Members {
+		if member.UserID == userID {
+			lastRead = member.LastReadSeq
+		}
+	}
+	if lastRead < 0 {
+		return 0
+	}
+	count := 0
+	for _, message := range s.messages[conversationID] {
+		if message.Seq > lastRead && message.SenderID != userID && message.DeletedAt == "" {
+			count++
+		}
+	}
+	return count
+}
+
+// findConversation returns a pointer into s.convos. Callers must hold s.mu.
+func (s *Store) findConversation(conversationID string) *Conversation {
+	for i := range s.convos {
+		if s.convos[i].ID == conversationID {
+			return &s.convos[i]
+		}
+	}
+	return nil
+}
+
+// BlockUser stops blockedID from messaging userID. Blocking twice is a no-op.
+func (s *Store) BlockUser(userID, blockedID string) error {
+	if userID == blockedID {
+		return ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[blockedID]; !ok {
+		return ErrNotFound
+	}
+	if s.blocks[userID] == nil {
+		s.blocks[userID] = map[string]string{}
+	}
+	if _, ok := s.blocks[userID][blockedID]; !ok {
+		s.blocks[userID][blockedID] = now()
+	}
+	return nil
+}
+
+func (s *Store) UnblockUser(userID, blockedID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.blocks[userID][blockedID]; !ok {
+		return ErrNotFound
+	}
+	delete(s.blocks[userID], blockedID)
+	return nil
+}
+
+// BlockedUsers lists who the user has blocked, newest first.
+func (s *Store) BlockedUsers(userID string) []UserBlock {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]UserBlock, 0, len(s.blocks[userID]))
+	for blockedID, createdAt := range s.blocks[userID] {
+		out = append(out, UserBlock{BlockedID: blockedID, CreatedAt: createdAt})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CreatedAt != out[j].CreatedAt {
+			return out[i].CreatedAt > out[j].CreatedAt
+		}
+		return out[i].BlockedID < out[j].BlockedID
+	})
+	return out
+}
+
+// Blocked reports whether userID has blocked otherID.
+func (s *Store) Blocked(userID, otherID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.blockedLocked(userID, otherID)
+}
+
+// blockedLocked is Blocked for callers that hold s.mu.
+func (s *Store) blockedLocked(userID, otherID string) bool {
+	_, ok := s.blocks[userID][otherID]
+	return ok
+}
+
+// AddMessage appends a message to a room history and returns it.
+func (s *Store) AddMessage(roomID, senderID, content string) ChatMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextMsgID++
+	message := ChatMessage{
+		ID:        fmt.Sprintf("m_%d", s.nextMsgID),
+		Seq:       s.nextMsgID,
+		RoomID:    roomID,
+		SenderID:  senderID,
+		Content:   content,
+		CreatedAt: now(),
+	}
+	s.messages[roomID] = append(s.messages[roomID], message)
+	return message
+}
+
+// Messages returns the last N messages for the room (or all if limit <= 0), oldest first.
+// A positive beforeSeq only considers messages older than that sequence number.
+func (s *Store) Messages(roomID string, beforeSeq, limit int) []ChatMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messages := liveMessages(s.messages[roomID])
+	if beforeSeq > 0 {
+		end := sort.Search(len(messages), func(i int) bool { return messages[i].Seq >= beforeSeq })
+		messages = messages[:end]
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	if limit <= 0 || limit >= len(messages) {
+		out := make([]ChatMessage, len(messages))
+		copy(out, messages)
+		return out
+	}
+	out := make([]ChatMessage, limit)
+	copy(out, messages[len(messages)-limit:])
+	return out
+}
+
+// MessagesSince returns the newest limit messages in the room with a sequence number above
+// afterSeq, oldest first.
+func (s *Store) MessagesSince(roomID string, afterSeq, limit int) []ChatMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messages := liveMessages(s.messages[roomID])
+	start := sort.Search(len(messages), func(i int) bool { return messages[i].Seq > afterSeq })
+	if limit > 0 && len(messages)-start > limit {
+		start = len(messages) - limit
+	}
+	if start >= len(messages) {
+		return nil
+	}
+	out := make([]ChatMessage, len(messages)-start)
+	copy(out, messages[start:])
+	return out
+}
+
+func (s *Store) GetMessage(messageID string) (ChatMessage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if message := s.findMessage(messageID); message != nil {
+		return *message, true
+	}
+	return ChatMessage{}, false
+}
+
+// liveMessages drops messages removed by moderation, keeping the order.
+func liveMessages(messages []ChatMessage) []ChatMessage {
+	out := make([]ChatMessage, 0, len(messages))
+	for _, message := range messages {
+		if message.DeletedAt == "" {
+			out = append(out, message)
+		}
+	}
+	return out
+}
+
+// CreateReport files a report against an existing target. A reporter can only have one
+// open report per target (ErrConflict); missing or deleted targets return ErrNotFound.
+func (s *Store) CreateReport(reporterID, targetType, targetID, reason, detail string) (Report, error) {
+	targetType = strings.TrimSpace(targetType)
+	targetID = strings.TrimSpace(targetID)
+	reason = strings.TrimSpace(reason)
+	if targetType == "" || targetID == "" || reason == "" || !validReportTargetType(targetType) {
+		return Report{}, ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target, ok := s.lookupReportTarget(targetType, targetID)
+	if !ok || target.deleted {
+		return Report{}, ErrNotFound
+	}
+	for _, existing := range s.reports {
+		if existing.ReporterID == reporterID && existing.TargetType == targetType &&
+			existing.TargetID == targetID && existing.Status == "open" {
+			return Report{}, ErrConflict
+		}
+	}
+
+	s.nextReport++
+	report := Report{
+		ID:           fmt.Sprintf("r_%d", s.nextReport),
+		TargetType:   targetType,
+		TargetID:     targetID,
+		BoardID:      target.boardID,
+		TargetUserID: target.userID,
+		Snapshot:     target.snapshot,
+		ReporterID:   reporterID,
+		Reason:       reason,
+		Detail:       strings.TrimSpace(detail),
+		Status:       "open",
+		CreatedAt:    now(),
+		UpdatedAt:    now(),
+	}
+	s.reports = append(s.reports, report)
+	return report, nil
+}
+
+// ReportCounts returns how many reports (and how many open ones) a target has received.
+func (s *Store) ReportCounts(targetType, targetID string) (int, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total, open := 0, 0
+	for _, report := range s.reports {
+		if report.TargetType == targetType && report.TargetID == targetID {
+			total++
+			if report.Status == "open" {
+				open++
+			}
+		}
+	}
+	return total, open
+}
+
+// TargetReports returns every report filed against a target, oldest first.
+func (s *Store) TargetReports(targetType, targetID string) []Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Report
+	for _, report := range s.reports {
+		if report.TargetType == targetType && report.TargetID == targetID {
+			out = append(out, report)
+		}
+	}
+	return out
+}
+
+// GetReport returns a report by ID.
+func (s *Store) GetReport(reportID string) (Report, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, report := range s.reports {
+		if report.ID == reportID {
+			return report, true
+		}
+	}
+	return Report{}, false
+}
+
+func (s *Store) Reports(status, boardID string, page, pageSize int) ([]Report, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	trimmed := strings.TrimSpace(status)
+	boardID = strings.TrimSpace(boardID)
+	filtered := make([]Report, 0, len(s.reports))
+	for _, r := range s.reports {
+		if (trimmed == "" || r.Status == trimmed) && (boardID == "" || r.BoardID == boardID) {
+			filtered = append(filtered, r)
+		}
+	}
+	total := len(filtered)
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	start := (page - 1) * pageSize
+	if start > total {
+		start = total
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+	out := make([]Report, end-start)
+	copy(out, filtered[start:end])
+	return out, total, nil
+}
+
+// UpdateReport resolves an open report and executes its action against the target in one step.
+func (s *Store) UpdateReport(reportID string, resolution ReportResolution) (Report, error) {
+	resolution = normalizeReportResolution(resolution)
+	trimmedID := strings.TrimSpace(reportID)
+	if trimmedID == "" || !validReportResolution(resolution) {
+		return Report{}, ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := -1
+	for i, report := range s.reports {
+		if report.ID == trimmedID {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return Report{}, ErrNotFound
+	}
+	report := s.reports[idx]
+	if report.Status != "open" {
+		return Report{}, ErrConflict
+	}
+
+	var outcome string
+	switch resolution.Action {
+	case ReportActionDismiss:
+		outcome = "dismissed"
+		if s.unhideReportTarget(report.TargetType, report.TargetID) {
+			outcome = fmt.Sprintf("dismissed, %s %s unhidden", report.TargetType, report.TargetID)
+		}
+	case ReportActionRemoveContent:
+		if !s.removeReportTarget(report.TargetType, report.TargetID) {
+			return Report{}, ErrInvalidInput
+		}
+		outcome = fmt.Sprintf("%s %s removed", report.TargetType, report.TargetID)
+	default:
+		userID := report.TargetUserID
+		if userID == "" {
+			target, _ := s.lookupReportTarget(report.TargetType, report.TargetID)
+			userID = target.userID
+		}
+		if userID == "" {
+			return Report{}, ErrInvalidInput
+		}
+		s.nextSanction++
+		sanction := newReportSanction(fmt.Sprintf("s_%d", s.nextSanction), userID, report.ID, resolution)
+		s.sanctions = append(s.sanctions, sanction)
+		outcome = sanctionOutcome(sanction)
+	}
+
+	report.Status = reportStatusFor(resolution.Action)
+	report.Action = resolution.Action
+	report.Note = resolution.Note
+	report.HandledBy = resolution.HandledBy
+	report.Outcome = outcome
+	report.UpdatedAt = now()
+	s.reports[idx] = report
+	return report, nil
+}
+
+func (s *Store) removeReportTarget(targetType, targetID string) bool {
+	switch targetType {
+	case "post":
+		for idx := range s.posts {
+			if s.posts[idx].ID == targetID {
+				s.posts[idx].DeletedAt = stampIf(true, s.posts[idx].DeletedAt)
+				return true
+			}
+		}
+	case "comment":
+		for idx := range s.comments {
+			if s.comments[idx].ID == targetID {
+				s.comments[idx].DeletedAt = stampIf(true, s.comments[idx].DeletedAt)
+				return true
+			}
+		}
+	case "message":
+		if message := s.findMessage(targetID); message != nil {
+			message.DeletedAt = stampIf(true, message.DeletedAt)
+			return true
+		}
+	}
+	return false
+}
+
+// findMessage locates a chat message by ID across rooms. Callers must hold s.mu.
+func (s *Store) findMessage(messageID string) *ChatMessage {
+	for roomID := range s.messages {
+		for idx := range s.messages[roomID] {
+			if s.messages[roomID][idx].ID == messageID {
+				return &s.messages[roomID][idx]
+			}
+		}
+	}
+	return nil
+}
+
+// unhideReportTarget clears the auto-hide flag on a post/comment; it reports whether it was hidden.
+func (s *Store) unhideReportTarget(targetType, targetID string) bool {
+	switch targetType {
+	case "post":
+		for idx := range s.posts {
+			if s.posts[idx].ID == targetID && s.posts[idx].HiddenAt != "" {
+				s.posts[idx].HiddenAt = ""
+				return true
+			}
+		}
+	case "comment":
+		for idx := range s.comments {
+			if s.comments[idx].ID == targetID && s.comments[idx].HiddenAt != "" {
+				s.comments[idx].HiddenAt = ""
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lookupReportTarget resolves a report target, including soft-deleted content.
+func (s *Store) lookupReportTarget(targetType, targetID string) (reportTarget, bool) {
+	switch targetType {
+	case "post":
+		for _, post := range s.posts {
+			if post.ID == targetID {
+				return reportTarget{
+					boardID:  post.BoardID,
+					userID:   post.AuthorID,
+					snapshot: postSnapshot(post.Title, post.Content),
+					deleted:  post.DeletedAt != "",
+				}, true
+			}
+		}
+	case "comment":
+		for _, comment := range s.comments {
+			if comment.ID != targetID {
+				continue
+			}
+			target := reportTarget{
+				userID:   comment.AuthorID,
+				snapshot: truncateRunes(comment.Content, maxSnapshotRunes),
+				deleted:  comment.DeletedAt != "",
+			}
+			for _, post := range s.posts {
+				if post.ID == comment.PostID {
+					target.boardID = post.BoardID
+					target.deleted = target.deleted || post.DeletedAt != ""
+				}
+			}
+			return target, true
+		}
+	case "user":
+		if user, ok := s.users[targetID]; ok {
+			return reportTarget{userID: user.ID, snapshot: user.Nickname}, true
+		}
+	case "message":
+		for _, messages := range s.messages {
+			for _, message := range messages {
+				if message.ID == targetID {
+					return reportTarget{
+						userID:   message.SenderID,
+						snapshot: truncateRunes(message.Content, maxSnapshotRunes),
+						deleted:  message.DeletedAt != "",
+					}, true
+				}
+			}
+		}
+	}
+	return reportTarget{}, false
+}
+
+// AddAuditEntry appends an entry to the audit log.
+func (s *Store) AddAuditEntry(entry AuditEntry) (AuditEntry, error) {
+	entry = normalizeAuditEntry(entry)
+	if entry.ActorID == "" || entry.Action == "" {
+		return AuditEntry{}, ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextAudit++
+	entry.ID = fmt.Sprintf("a_%d", s.nextAudit)
+	entry.CreatedAt = now()
+	s.audit = append(s.audit, entry)
+	return entry, nil
+}
+
+// AuditEntries lists audit entries newest first.
+func (s *Store) AuditEntries(filter AuditFilter, page, pageSize int) ([]AuditEntry, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var filtered []AuditEntry
+	for i := len(s.audit) - 1; i >= 0; i-- {
+		if filter.matches(s.audit[i]) {
+			filtered = append(filtered, s.audit[i])
+		}
+	}
+	start, end := pageBounds(len(filtered), page, pageSize)
+	out := make([]AuditEntry, end-start)
+	copy(out, filtered[start:end])
+	return out, len(filtered), nil
+}
+
+func (f AuditFilter) matches(entry AuditEntry) bool {
+	return (f.ActorID == "" || entry.ActorID == f.ActorID) &&
+		(f.Action == "" || entry.Action == f.Action) &&
+		(f.TargetType == "" || entry.TargetType == f.TargetType) &&
+		(f.TargetID == "" || entry.TargetID == f.TargetID) &&
+		(f.BoardID == "" || entry.BoardID == f.BoardID) &&
+		(f.Since == "" || entry.CreatedAt >= f.Since) &&
+		(f.Until == "" || entry.CreatedAt < f.Until)
+}
+
+func normalizeAuditEntry(entry AuditEntry) AuditEntry {
+	entry.ActorID = strings.TrimSpace(entry.ActorID)
+	entry.Action = strings.TrimSpace(entry.Action)
+	entry.TargetType = strings.TrimSpace(entry.TargetType)
+	entry.TargetID = strings.TrimSpace(entry.TargetID)
+	entry.BoardID = strings.TrimSpace(entry.BoardID)
+	entry.Reason = strings.TrimSpace(entry.Reason)
+	entry.IP = strings.TrimSpace(entry.IP)
+	return entry
+}
+
+// reportTarget describes what a report points at.
+type reportTarget struct {
+	boardID  string
+	userID   string
+	snapshot string
+	deleted  bool
+}
+
+const maxSnapshotRunes = 2000
+
+func validReportTargetType(targetType string) bool {
+	switch targetType {
+	case "post", "comment", "user", "message":
+		return true
+	}
+	return false
+}
+
+func postSnapshot(title, content string) string {
+	return truncateRunes(title+"\n\n"+content, maxSnapshotRunes)
+}
+
+func truncateRunes(value string, limit int) string {
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	return string(runes[:limit])
+}
+
+func normalizeReportResolution(resolution ReportResolution) ReportResolution {
+	resolution.Action = strings.TrimSpace(resolution.Action)
+	resolution.Note = strings.TrimSpace(resolution.Note)
+	resolution.HandledBy = strings.TrimSpace(resolution.HandledBy)
+	return resolution
+}
+
+func validReportResolution(resolution ReportResolution) bool {
+	switch resolution.Action {
+	case ReportActionDismiss, ReportActionRemoveContent, ReportActionWarnUser, ReportActionBanUser:
+		return true
+	case ReportActionSuspendUser:
+		return resolution.SuspendDays > 0
+	}
+	return false
+}
+
+func reportStatusFor(action string) string {
+	if action == ReportActionDismiss {
+		return "dismissed"
+	}
+	return "resolved"
+}
+
+// newReportSanction builds the sanction a warn/suspend/ban resolution applies to userID.
+func newReportSanction(id, userID, reportID string, resolution ReportResolution) Sanction {
+	createdAt := time.Now().UTC()
+	sanction := Sanction{
+		ID:        id,
+		UserID:    userID,
+		Reason:    resolution.Note,
+		ReportID:  reportID,
+		CreatedBy: resolution.HandledBy,
+		CreatedAt: createdAt.Format(time.RFC3339),
+	}
+	switch resolution.Action {
+	case ReportActionWarnUser:
+		sanction.Type = SanctionWarning
+	case ReportActionSuspendUser:
+		sanction.Type = SanctionSuspension
+		sanction.ExpiresAt = createdAt.AddDate(0, 0, resolution.SuspendDays).Format(time.RFC3339)
+	case ReportActionBanUser:
+		sanction.Type = SanctionBan
+	}
+	return sanction
+}
+
+// normalizeSanction validates an admin-issued sanction: suspensions need a future ExpiresAt,
+// warnings and bans must not carry one.
+func normalizeSanction(sanction Sanction) (Sanction, bool) {
+	sanction.UserID = strings.TrimSpace(sanction.UserID)
+	sanction.Type = strings.TrimSpace(sanction.Type)
+	sanction.Reason = strings.TrimSpace(sanction.Reason)
+	sanction.ExpiresAt = strings.TrimSpace(sanction.ExpiresAt)
+	sanction.RevokedAt = ""
+	if sanction.UserID == "" || sanction.CreatedBy == "" {
+		return Sanction{}, false
+	}
+	switch sanction.Type {
+	case SanctionWarning, SanctionBan:
+		return sanction, sanction.ExpiresAt == ""
+	case SanctionSuspension:
+		expires, err := time.Parse(time.RFC3339, sanction.ExpiresAt)
+		if err != nil || !expires.After(time.Now()) {
+			return Sanction{}, false
+		}
+		sanction.ExpiresAt = expires.UTC().Format(time.RFC3339)
+		return sanction, true
+	default:
+		return Sanction{}, false
+	}
+}
+
+// maxStatsDays caps SiteStats ranges so a dashboard query stays cheap.
+const maxStatsDays = 366
+
+// dayRange lists the days from..to inclusive; both must be YYYY-MM-DD with from <= to.
+func dayRange(from, to string) ([]string, bool) {
+	start, err := time.Parse(time.DateOnly, from)
+	if err != nil {
+		return nil, false
+	}
+	end, err := time.Parse(time.DateOnly, to)
+	if err != nil || end.Before(start) {
+		return nil, false
+	}
+	var days []string
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		if len(days) == maxStatsDays {
+			return nil, false
+		}
+		days = append(days, day.Format(time.DateOnly))
+	}
+	return days, true
+}
+
+// dayOf returns the YYYY-MM-DD part of an RFC3339 UTC timestamp.
+func dayOf(timestamp string) string {
+	if len(timestamp) < len(time.DateOnly) {
+		return ""
+	}
+	return timestamp[:len(time.DateOnly)]
+}
+
+func dailySeries(days []string, counts map[string]int) []DailyCount {
+	out := make([]DailyCount, 0, len(days))
+	for _, day := range days {
+		out = append(out, DailyCount{Day: day, Count: counts[day]})
+	}
+	return out
+}
+
+// topBoardActivity sorts by posts then comments (board ID breaks ties) and keeps the first n.
+func topBoardActivity(boards []BoardActivity, n int) []BoardActivity {
+	sort.Slice(boards, func(i, j int) bool {
+		if boards[i].Posts != boards[j].Posts {
+			return boards[i].Posts > boards[j].Posts
+		}
+		if boards[i].Comments != boards[j].Comments {
+			return boards[i].Comments > boards[j].Comments
+		}
+		return boards[i].BoardID < boards[j].BoardID
+	})
+	if n >= 0 && len(boards) > n {
+		boards = boards[:n]
+	}
+	if boards == nil {
+		boards = []BoardActivity{}
+	}
+	return boards
+}
+
+const maxFilterWordRunes = 64
+
+func normalizeFilterWord(word, category string) (string, string, bool) {
+	word = strings.TrimSpace(word)
+	category = strings.TrimSpace(category)
+	if word == "" || category == "" || utf8.RuneCountInString(word) > maxFilterWordRunes {
+		return "", "", false
+	}
+	return word, category, true
+}
+
+// outranks reports whether a is the more severe of two restricting sanctions.
+func outranks(a, b Sanction) bool {
+	if (a.Type == SanctionBan) != (b.Type == SanctionBan) {
+		return a.Type == SanctionBan
+	}
+	return a.ExpiresAt > b.ExpiresAt
+}
+
+func sanctionOutcome(sanction Sanction) string {
+	switch sanction.Type {
+	case SanctionWarning:
+		return fmt.Sprintf("user %s warned (%s)", sanction.UserID, sanction.ID)
+	case SanctionSuspension:
+		return fmt.Sprintf("user %s suspended until %s (%s)", sanction.UserID, sanction.ExpiresAt, sanction.ID)
+	default:
+		return fmt.Sprintf("user %s banned (%s)", sanction.UserID, sanction.ID)
+	}
+}
+
+func normalizeAppeal(userID, targetType, targetID, reason string) (Appeal, bool) {
+	appeal := Appeal{
+		UserID:     strings.TrimSpace(userID),
+		TargetType: strings.TrimSpace(targetType),
+		TargetID:   strings.TrimSpace(targetID),
+		Reason:     strings.TrimSpace(reason),
+		Status:     AppealPending,
+	}
+	if appeal.UserID == "" || appeal.TargetID == "" || appeal.Reason == "" ||
+		utf8.RuneCountInString(appeal.Reason) > maxAppealReasonRunes {
+		return Appeal{}, false
+	}
+	if appeal.TargetType != AppealTargetReport && appeal.TargetType != AppealTargetSanction {
+		return Appeal{}, false
+	}
+	return appeal, true
+}
+
+// appealableReport checks that the report was resolved against userID: removed content they
+// wrote or a sanction on their account. Dismissed and open reports cannot be appealed.
+func appealableReport(report Report, userID string) error {
+	if report.TargetUserID != userID {
+		return ErrForbidden
+	}
+	if report.Status != "resolved" {
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+func appealableSanction(sanction Sanction, userID string) error {
+	if sanction.UserID != userID {
+		return ErrForbidden
+	}
+	if sanction.RevokedAt != "" {
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+func appealLiftedOutcome(lifted []string) string {
+	if len(lifted) == 0 {
+		return "nothing to restore"
+	}
+	return "sanction " + strings.Join(lifted, ", ") + " lifted"
+}
+
+// defaultChatRooms are the rooms every store starts with; "public" predates the room registry.
+func defaultChatRooms() []ChatRoom {
+	return []ChatRoom{
+		{ID: "public", Name: "公共聊天室", Description: "全校公共聊天", Visibility: RoomPublic, OwnerID: "system"},
+	}
+}
+
+func normalizeChatRoom(room ChatRoom) (ChatRoom, bool) {
+	room.Name = strings.TrimSpace(room.Name)
+	room.Description = strings.TrimSpace(room.Description)
+	room.Visibility = strings.TrimSpace(room.Visibility)
+	room.OwnerID = strings.TrimSpace(room.OwnerID)
+	room.ArchivedAt = ""
+	if room.Visibility == "" {
+		room.Visibility = RoomPublic
+	}
+	if room.Name == "" || utf8.RuneCountInString(room.Name) > maxRoomNameRunes || room.OwnerID == "" {
+		return ChatRoom{}, false
+	}
+	if room.Visibility != RoomPublic && room.Visibility != RoomPrivate {
+		return ChatRoom{}, false
+	}
+	return room, true
+}
+
+// normalizeConversation builds a conversation (without ID and timestamps) from the creator and
+// the other members: duplicates and the creator are dropped from memberIDs, one other member
+// makes it direct (with no title), more make it a group.
+func normalizeConversation(creatorID string, memberIDs []string, title string) (Conversation, bool) {
+	creatorID = strings.TrimSpace(creatorID)
+	title = strings.TrimSpace(title)
+	if creatorID == "" || utf8.RuneCountInString(title) > maxRoomNameRunes {
+		return Conversation{}, false
+	}
+
+	seen := map[string]bool{creatorID: true}
+	members := []ConversationMember{{UserID: creatorID}}
+	for _, id := range memberIDs {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		members = append(members, ConversationMember{UserID: id})
+	}
+	if len(members) < 2 || len(members) > maxConversationMembers {
+		return Conversation{}, false
+	}
+
+	convo := Conversation{Kind: ConversationGroup, Title: title, CreatorID: creatorID, Members: members}
+	if len(members) == 2 {
+		convo.Kind = ConversationDirect
+		convo.Title = ""
+	}
+	return convo, true
+}
+
+// directKey identifies the pair of users in a direct conversation, in either order.
+func directKey(memberIDs []string) string {
+	ids := append([]string(nil), memberIDs...)
+	sort.Strings(ids)
+	return strings.Join(ids, "|")
+}
+
+func cloneConversation(convo Conversation) Conversation {
+	convo.Members = append([]ConversationMember(nil), convo.Members...)
+	return convo
+}
+
+// stampIf returns the existing timestamp (or now) when on is true, and "" otherwise.
+func stampIf(on bool, current string) string {
+	if !on {
+		return ""
+	}
+	if current != "" {
+		return current
+	}
+	return now()
+}
+
+// now returns the current time in UTC RFC3339 format.
+func now() string {
 	return time.Now().UTC().Format(time.RFC3339)
 }
 
 var _ API = (*Store)(nil)
 
+// validBoardFields checks that a board name is present and every field fits its length limit.
+func validBoardFields(name, description, icon, rules, qqGroup string) bool {
+	return name != "" &&
+		utf8.RuneCountInString(name) <= 32 &&
+		utf8.RuneCountInString(description) <= 200 &&
+		utf8.RuneCountInString(icon) <= 512 &&
+		utf8.RuneCountInString(rules) <= 5000 &&
+		utf8.RuneCountInString(qqGroup) <= 256
+}
+
+func normalizeBoard(board Board) Board {
+	board.ID = strings.TrimSpace(board.ID)
+	board.Name = strings.TrimSpace(board.Name)
+	board.Description = strings.TrimSpace(board.Description)
+	board.Icon = strings.TrimSpace(board.Icon)
+	board.Rules = strings.TrimSpace(board.Rules)
+	board.QQGroup = strings.TrimSpace(board.QQGroup)
+	return board
+}
+
+func normalizeBoardApplication(app BoardApplication) BoardApplication {
+	app.ApplicantID = strings.TrimSpace(app.ApplicantID)
+	app.Name = strings.TrimSpace(app.Name)
+	app.Description = strings.TrimSpace(app.Description)
+	app.Icon = strings.TrimSpace(app.Icon)
+	app.Rules = strings.TrimSpace(app.Rules)
+	app.QQGroup = strings.TrimSpace(app.QQGroup)
+	app.Reason = strings.TrimSpace(app.Reason)
+	return app
+}
+
+func (s *Store) boardExists(boardID string) bool {
+	for _, board := range s.boards {
+		if board.ID == boardID {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *Store) postExists(postID string) bool {
 	for _, post := range s.posts {
 		if post.ID == postID && post.DeletedAt == "" {
@@ -683,6 +3256,65 @@ func (s *Store) commentExists(postID, commentID string) bool {
 	return false
 }
 
+// canAttach reports whether every file exists, belongs to the uploader and is not attached yet.
+func (s *Store) canAttach(uploaderID string, fileIDs []string) bool {
+	seen := map[string]bool{}
+	for _, id := range fileIDs {
+		file, ok := s.files[id]
+		if !ok || seen[id] || file.UploaderID != uploaderID || file.PostID != "" {
+			return false
+		}
+		seen[id] = true
+	}
+	return true
+}
+
+func (s *Store) attach(fileIDs []string, postID, commentID string) {
+	for _, id := range fileIDs {
+		file := s.files[id]
+		file.PostID = postID
+		file.CommentID = commentID
+		s.files[id] = file
+	}
+}
+
+// sortFiles orders files by their numeric ID suffix (upload order).
+func sortFiles(files []FileMeta) {
+	sort.Slice(files, func(i, j int) bool {
+		return IDSeq(files[i].ID) < IDSeq(files[j].ID)
+	})
+}
+
+// IDSeq extracts the numeric suffix of a prefixed ID such as "p_12"; IDs of one kind are
+// issued in increasing order, so it sorts them by creation.
+func IDSeq(id string) int {
+	if idx := strings.LastIndex(id, "_"); idx >= 0 {
+		if n, err := strconv.Atoi(id[idx+1:]); err == nil {
+			return n
+		}
+	}
+	return 0
+}
+
+// pageBounds converts 1-based page/pageSize into slice bounds for a list of total items.
+func pageBounds(total, page, pageSize int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	start := (page - 1) * pageSize
+	if start > total {
+		start = total
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+	return start, end
+}
+
 func sumVotes(votes map[string]int) int {
 	score := 0
 	for _, value := range votes {